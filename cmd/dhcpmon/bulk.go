@@ -0,0 +1,198 @@
+// ===== cmd/dhcpmon/bulk.go =====
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"dhcpmon/internal/config"
+	"dhcpmon/internal/static"
+)
+
+// runExport writes the static reservations to stdout or a file:
+//
+//	dhcpmon export [-config dhcpmon.ini] [-format csv|json|yaml] [-o file]
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	loadCfg := configFlag(fs, "configuration file")
+	format := fs.String("format", "", "csv, json or yaml (default: from the -o extension, or json)")
+	output := fs.String("o", "", "file to write instead of stdout")
+	fs.Parse(args)
+
+	f, err := bulkFormat(*format, *output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	manager, err := loadStatic(loadCfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" && *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := static.Export(w, f, manager.GetAll()); err != nil {
+		fmt.Fprintf(os.Stderr, "export failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runImport imports static reservations from a file, or stdin for "-",
+// and saves them to the static file:
+//
+//	dhcpmon import [-config dhcpmon.ini] [-format csv|json|yaml] [-mode merge|replace] [-dry-run] file
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	loadCfg := configFlag(fs, "configuration file")
+	format := fs.String("format", "", "csv, json or yaml (default: from the file extension)")
+	mode := fs.String("mode", static.ImportMerge, "merge adds and updates; replace also removes entries not in the file")
+	dryRun := fs.Bool("dry-run", false, "show the changes without saving them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: dhcpmon import [options] file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+
+	f, err := bulkFormat(*format, path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer file.Close()
+		r = file
+	}
+	rows, err := static.Import(r, f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	manager, err := loadStatic(loadCfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := manager.Import(rows, *mode, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	printImport(result)
+
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d rows cannot be imported; nothing was changed\n", result.FailedRows(), result.Rows)
+		return 1
+	}
+	if !result.Applied {
+		if *dryRun {
+			fmt.Printf("Dry run: %s\n", result.Summary())
+		} else {
+			fmt.Printf("Nothing to import: %s\n", result.Summary())
+		}
+		return 0
+	}
+
+	if err := manager.Save(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Imported: %s\n", result.Summary())
+	fmt.Println("Reload dnsmasq (or use the web interface) to apply the changes")
+	return 0
+}
+
+// bulkFormat returns the format named by a flag, or by the extension of
+// the file
+func bulkFormat(format, path string) (string, error) {
+	if format != "" {
+		return static.ParseFormat(format)
+	}
+	if f := static.FormatOf(path); f != "" {
+		return f, nil
+	}
+	if path == "" || path == "-" {
+		return static.FormatJSON, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s; use -format", path)
+}
+
+// loadStatic loads the static reservations named by the configuration
+func loadStatic(loadCfg func() (*config.Config, error)) (*static.Manager, error) {
+	cfg, err := loadCfg()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	manager := static.NewManager(cfg.StaticFile)
+	if err := manager.Load(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// printImport lists the changes and row errors of an import
+func printImport(result *static.ImportResult) {
+	for _, c := range result.Changes {
+		switch c.Action {
+		case static.ImportAdd:
+			fmt.Printf("+ row %-4d %s %s\n", c.Row, c.MAC, describeRecord(c.After))
+		case static.ImportUpdate:
+			fmt.Printf("~ row %-4d %s %s -> %s\n", c.Row, c.MAC, describeRecord(c.Before), describeRecord(c.After))
+		case static.ImportRemove:
+			fmt.Printf("- %-8s %s %s\n", "", c.MAC, describeRecord(c.Before))
+		}
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(os.Stderr, "row %d: %s\n", e.Row, e.Error)
+	}
+}
+
+// describeRecord returns the fields of a reservation as key=value pairs
+func describeRecord(r *static.Record) string {
+	var parts []string
+	for _, f := range []struct{ key, value string }{
+		{"ip", r.IP}, {"hostname", r.Hostname}, {"tag", r.Tag},
+		{"leaseTime", r.LeaseTime}, {"comment", r.Comment},
+	} {
+		if f.value != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", f.key, f.value))
+		}
+	}
+	if r.Enabled != nil && !*r.Enabled {
+		parts = append(parts, "disabled")
+	}
+	if r.Ignore {
+		parts = append(parts, "ignore")
+	}
+	if len(parts) == 0 {
+		return "{}"
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
@@ -0,0 +1,114 @@
+// ===== cmd/dhcpmon/check.go =====
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"dhcpmon/internal/config"
+	"dhcpmon/pkg/utils"
+)
+
+// runCheckConfig prints the settings that are not at their defaults and
+// where each came from, checks the configuration, and returns 1 when it
+// has errors
+func runCheckConfig(cfg *config.Config) int {
+	if cfg.File != "" {
+		fmt.Printf("Configuration file: %s\n", cfg.File)
+	} else {
+		fmt.Printf("Configuration file: none, %s not read\n", cli.configFile)
+	}
+
+	keys := cfg.SourceKeys()
+	if len(keys) == 0 {
+		fmt.Println("All settings at their defaults")
+	}
+	for _, key := range keys {
+		source := cfg.Source(key)
+		value := source.Value
+		if key == "apitoken" && value != "" {
+			value = "********"
+		}
+		if strings.HasPrefix(key, "notify.") {
+			fmt.Printf("  [%s] (%s)\n", key, source.Origin)
+			continue
+		}
+		fmt.Printf("  %s = %s (%s)\n", key, value, source.Origin)
+	}
+
+	var errors, warnings []string
+	failf := func(format string, args ...interface{}) {
+		errors = append(errors, fmt.Sprintf(format, args...))
+	}
+	warnf := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.HTTPListen); err != nil {
+		failf("httplisten: %v", err)
+	}
+	if _, err := utils.ParseLogLevel(cfg.LogLevel); err != nil {
+		failf("loglevel: %v", err)
+	}
+	if cfg.SyslogListen != "" {
+		if _, _, err := net.SplitHostPort(cfg.SyslogListen); err != nil {
+			failf("sysloglisten: %v", err)
+		}
+		switch strings.ToLower(cfg.SyslogProtocol) {
+		case "udp", "tcp", "both":
+		default:
+			failf("syslogprotocol: unknown protocol %q (use udp, tcp or both)", cfg.SyslogProtocol)
+		}
+	}
+	switch cfg.RestartPolicy {
+	case "always", "on-failure", "never":
+	default:
+		failf("restartpolicy: unknown policy %q (use always, on-failure or never)", cfg.RestartPolicy)
+	}
+	if cfg.PoolWarn < 0 || cfg.PoolWarn > 100 || cfg.PoolCrit < 0 || cfg.PoolCrit > 100 {
+		failf("poolwarn and poolcrit must be percentages between 0 and 100")
+	} else if cfg.PoolWarn > 0 && cfg.PoolCrit > 0 && cfg.PoolWarn > cfg.PoolCrit {
+		warnf("poolwarn (%g) is above poolcrit (%g)", cfg.PoolWarn, cfg.PoolCrit)
+	}
+	if cfg.Edit && cfg.APIToken != "" {
+		warnf("edit is on, so changes are allowed without apitoken")
+	}
+
+	// The files the server reads; missing ones are only warnings, as
+	// several are created or optional
+	for _, f := range []struct{ key, path string }{
+		{"leasesfile", cfg.LeasesFile},
+		{"staticfile", cfg.StaticFile},
+		{"hostsfile", cfg.HostsFile},
+		{"htmldir", cfg.HTMLDir},
+	} {
+		if _, err := os.Stat(f.path); err != nil {
+			warnf("%s: %v", f.key, err)
+		}
+	}
+	for _, path := range cfg.MACDBFiles {
+		if _, err := os.Stat(path); err != nil {
+			warnf("macdbfile: %v", err)
+		}
+	}
+	if !cfg.SystemD {
+		if _, err := os.Stat(cfg.DNSMasq); err != nil {
+			warnf("dnsmasq: %v", err)
+		}
+	}
+
+	for _, w := range warnings {
+		fmt.Printf("warning: %s\n", w)
+	}
+	for _, e := range errors {
+		fmt.Printf("error: %s\n", e)
+	}
+	if len(errors) > 0 {
+		fmt.Printf("Configuration has %d errors\n", len(errors))
+		return 1
+	}
+	fmt.Println("Configuration OK")
+	return 0
+}
@@ -2,6 +2,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -13,6 +15,7 @@ import (
 	"dhcpmon/internal/dhcp"
 	"dhcpmon/internal/web"
 	"dhcpmon/internal/monitor"
+	"dhcpmon/pkg/utils"
 )
 
 const (
@@ -25,18 +28,98 @@ var (
 	repoName  string
 )
 
+const usage = `Usage: dhcpmon [options] [command [options] [arguments]]
+
+Without a command, dhcpmon runs the monitor and web server.
+
+Commands:
+  export     write the static reservations to a file
+  import     import static reservations from a file
+  validate   check static files without starting the server
+
+Options:`
+
+// cli holds the global command-line options, which subcommands share
+var cli struct {
+	configFile string            // -config, or $DHCPMON_CONFIG
+	explicit   bool              // The configuration file was named, so must exist
+	settings   map[string]string // Settings given as flags, by INI key
+}
+
 func main() {
-	log.Printf("%s: Build %s, Time %s", repoName, sha1ver, buildTime)
+	fs := flag.NewFlagSet("dhcpmon", flag.ExitOnError)
+	cfgFile := fs.String("config", configFile, "configuration file, or $DHCPMON_CONFIG when not given")
+	listen := fs.String("listen", "", "address to serve HTTP on, overriding httplisten")
+	logLevel := fs.String("log-level", "", "debug, info, warning or error, overriding loglevel")
+	version := fs.Bool("version", false, "print the version and exit")
+	checkConfig := fs.Bool("check-config", false, "check the configuration, show where each setting came from, and exit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	
+	cli.configFile = *cfgFile
+	cli.settings = make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "config":
+			cli.explicit = true
+		case "listen":
+			cli.settings["httplisten"] = *listen
+		case "log-level":
+			cli.settings["loglevel"] = *logLevel
+		}
+	})
+	if v, ok := os.LookupEnv("DHCPMON_CONFIG"); ok && !cli.explicit {
+		cli.configFile, cli.explicit = v, true
+	}
+	
+	if *version {
+		fmt.Println(versionString())
+		return
+	}
+	
+	// Subcommands work on the configuration files and exit
+	if fs.NArg() > 0 {
+		args := fs.Args()
+		switch args[0] {
+		case "export":
+			os.Exit(runExport(args[1:]))
+		case "import":
+			os.Exit(runImport(args[1:]))
+		case "validate":
+			os.Exit(runValidate(args[1:]))
+		}
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		fs.Usage()
+		os.Exit(2)
+	}
 	
 	// Load configuration
-	cfg, err := config.New(configFile)
+	cfg, err := loadConfig(cli.configFile, cli.explicit)
 
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 	
+	if *checkConfig {
+		os.Exit(runCheckConfig(cfg))
+	}
+	
+	level, err := utils.ParseLogLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("Failed to set log level: %v", err)
+	}
+	utils.SetLogLevel(level)
+	
+	utils.Infof("%s", versionString())
+	if cfg.File != "" {
+		utils.Infof("Using configuration file %s", cfg.File)
+	}
+	
 	// Initialize MAC database
-	macDB, err := mac.NewDatabase(cfg.MACDBFile, cfg.MACDBPreload)
+	macDB, err := mac.NewDatabase(cfg.MACDBFiles, cfg.MACDBPreload)
 	if err != nil {
 		log.Fatalf("Failed to initialize MAC database: %v", err)
 	}
@@ -46,7 +129,7 @@ func main() {
 	dhcpParser := dhcp.NewParser(macDB, cfg.StaticFile)
 	
 	// Initialize monitor
-	monitor := monitor.New(cfg, dhcpParser)
+	monitor := monitor.New(cfg, dhcpParser, macDB)
 	
 	// Add timeout to detect hanging
 	done := make(chan error, 1)
@@ -60,7 +143,7 @@ func main() {
 			log.Fatalf("Failed to start monitor: %v", err)
 		}
 	case <-time.After(30 * time.Second):
-		log.Fatal("TIMEOUT: Monitor.Start() took longer than 30 seconds - likely hanging")
+		log.Fatal("Failed to start monitor: timeout, Monitor.Start() took longer than 30 seconds - likely hanging")
 	}
 	
 	defer monitor.Stop()
@@ -69,7 +152,7 @@ func main() {
 	webServer := web.NewServer(cfg, monitor)
 	
 	go func() {
-		log.Printf("Starting HTTP server on %s", cfg.HTTPListen)
+		utils.Infof("Starting HTTP server on %s", cfg.HTTPListen)
 		if err := webServer.Start(); err != nil {
 			log.Fatalf("HTTP server failed: %v", err)
 		}
@@ -81,6 +164,40 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 	
-	log.Println("Shutting down...")
+	utils.Infof("Shutting down...")
 }
 
+// versionString describes the build
+func versionString() string {
+	name, version, built := repoName, sha1ver, buildTime
+	if name == "" {
+		name = "dhcpmon"
+	}
+	if version == "" {
+		version = "dev"
+	}
+	if built == "" {
+		built = "unknown"
+	}
+	return fmt.Sprintf("%s: Build %s, Time %s", name, version, built)
+}
+
+// loadConfig loads the configuration the way the server does, with the
+// settings given as global flags. A configuration file that was named
+// explicitly must exist.
+func loadConfig(file string, explicit bool) (*config.Config, error) {
+	return config.Load(file, explicit, cli.settings)
+}
+
+// configFlag adds -config to a subcommand, defaulting to the global
+// -config, and returns the loader of the configuration it names
+func configFlag(fs *flag.FlagSet, usage string) func() (*config.Config, error) {
+	file := fs.String("config", cli.configFile, usage)
+	return func() (*config.Config, error) {
+		explicit := cli.explicit
+		fs.Visit(func(f *flag.Flag) {
+			explicit = explicit || f.Name == "config"
+		})
+		return loadConfig(*file, explicit)
+	}
+}
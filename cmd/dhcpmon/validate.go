@@ -0,0 +1,263 @@
+// ===== cmd/dhcpmon/validate.go =====
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dhcpmon/internal/consistency"
+	"dhcpmon/internal/dnsmasq"
+	"dhcpmon/internal/hosts"
+	"dhcpmon/internal/static"
+	"dhcpmon/pkg/models"
+)
+
+// Finding is a problem reported by validate, located by file and line
+type Finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+// validateReport is the JSON output of validate
+type validateReport struct {
+	Files    []string       `json:"files"`
+	Hosts    string         `json:"hosts,omitempty"`
+	Ranges   []string       `json:"ranges"`
+	Findings []Finding      `json:"findings"`
+	Counts   map[string]int `json:"counts"`
+}
+
+// runValidate checks static files without starting the server, and
+// exits with 1 when it finds errors:
+//
+//	dhcpmon validate [-config dhcpmon.ini] [-hosts file] [-dnsmasq-conf paths] [-format text|json] [-strict] file|dir...
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	loadCfg := configFlag(fs, "configuration file, for the default hosts file and dnsmasq configuration")
+	hostsFile := fs.String("hosts", "", "hosts file to check against (default: hostsfile; none to skip)")
+	dnsmasqConf := fs.String("dnsmasq-conf", "", "comma-separated dnsmasq files and directories with the dhcp-range lines (default: dnsmasqconf; none to skip)")
+	format := fs.String("format", "text", "text, or json")
+	strict := fs.Bool("strict", false, "exit with 1 on warnings too")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: dhcpmon validate [options] file|dir...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 || (*format != "text" && *format != "json") {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := loadCfg()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		return 2
+	}
+	explicitHosts := *hostsFile != ""
+	if !explicitHosts {
+		*hostsFile = cfg.HostsFile
+	}
+	confPaths := cfg.DNSMasqConf
+	if *dnsmasqConf != "" {
+		confPaths = strings.Split(*dnsmasqConf, ",")
+	}
+
+	files, err := staticFiles(fs.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	report := validateReport{Files: files, Ranges: []string{}, Findings: []Finding{}}
+	add := func(f Finding) { report.Findings = append(report.Findings, f) }
+
+	// Entries are identified by file and line, so that findings from the
+	// consistency checks can be located
+	var entries []models.StaticDHCPEntry
+	parser := static.NewParser()
+	for _, file := range files {
+		parsed, err := parser.ParseFile(file)
+		if err != nil {
+			add(Finding{File: file, Severity: consistency.SeverityError, Check: "read", Message: err.Error()})
+			continue
+		}
+		for _, entry := range parsed {
+			entry.ID = file + ":" + strconv.Itoa(entry.LineNumber)
+			entries = append(entries, entry)
+		}
+	}
+
+	manager := static.NewManager("")
+	manager.Restore(entries)
+	for _, p := range manager.Problems() {
+		entry := entries[p.Index]
+		file, line := entryLocation(entry)
+		f := Finding{File: file, Line: line, Severity: consistency.SeverityError,
+			Check: "invalid-entry", Message: p.Err.Error()}
+		if p.Kind != static.ProblemInvalid {
+			// Duplicate MAC or IP
+			f.Check = p.Kind
+			other, otherLine := entryLocation(entries[p.Other])
+			if other == file {
+				f.Message = fmt.Sprintf("%v (also on line %d)", p.Err, otherLine)
+			} else {
+				f.Message = fmt.Sprintf("%v (also at %s:%d)", p.Err, other, otherLine)
+			}
+		} else if !entry.Enabled {
+			// Commented-out lines are not read by dnsmasq
+			f.Severity = consistency.SeverityWarning
+			f.Message += " (disabled entry)"
+		}
+		add(f)
+	}
+
+	var hostEntries []models.HostEntry
+	if *hostsFile != "" && *hostsFile != "none" {
+		hm := hosts.NewManager(*hostsFile)
+		if _, err := os.Stat(*hostsFile); os.IsNotExist(err) && !explicitHosts {
+			// The configured hosts file need not exist where this runs
+		} else if err := hm.Load(); err != nil {
+			add(Finding{File: *hostsFile, Severity: consistency.SeverityWarning, Check: "read", Message: err.Error()})
+		} else {
+			report.Hosts = *hostsFile
+			hostEntries = hm.GetAll()
+		}
+	}
+
+	var ranges []dnsmasq.DHCPRange
+	var rangeErr error
+	if *dnsmasqConf != "none" {
+		conf, err := dnsmasq.ParseConfig(confPaths)
+		ranges, rangeErr = conf.Ranges, err
+		for _, w := range conf.Warnings {
+			add(Finding{File: w.File, Line: w.Line, Severity: consistency.SeverityWarning, Check: "dnsmasq-conf", Message: w.Message})
+		}
+	}
+	for _, r := range ranges {
+		report.Ranges = append(report.Ranges, fmt.Sprintf("%s (%s:%d)", r.String(), r.File, r.Line))
+	}
+
+	checked := consistency.Check(consistency.Input{
+		Static:     entries,
+		Hosts:      hostEntries,
+		Ranges:     ranges,
+		RangeError: rangeErr,
+	})
+	for _, issue := range checked.Issues {
+		if issue.Type == consistency.TypeNoRanges && *dnsmasqConf == "none" {
+			continue
+		}
+		if issue.Type == consistency.TypeIPConflict && onlyStatic(issue.Sources) {
+			continue // Reported as duplicate-ip
+		}
+		f := Finding{File: "dnsmasq", Severity: issue.Severity, Check: issue.Type, Message: issue.Message}
+		for _, source := range issue.Sources {
+			if kind, id, _ := strings.Cut(source, ":"); kind == "static" {
+				f.File, f.Line = entryLocation(models.StaticDHCPEntry{ID: id})
+				break
+			} else if kind == "hosts" && f.File == "dnsmasq" {
+				f.File = *hostsFile
+				f.Line, _ = strconv.Atoi(strings.TrimPrefix(id, "line_"))
+			}
+		}
+		add(f)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	report.Counts = map[string]int{consistency.SeverityError: 0, consistency.SeverityWarning: 0, consistency.SeverityInfo: 0}
+	for _, f := range report.Findings {
+		report.Counts[f.Severity]++
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		for _, f := range report.Findings {
+			if f.Line > 0 {
+				fmt.Printf("%s:%d: %s: %s [%s]\n", f.File, f.Line, f.Severity, f.Message, f.Check)
+			} else {
+				fmt.Printf("%s: %s: %s [%s]\n", f.File, f.Severity, f.Message, f.Check)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d reservations in %d files: %d errors, %d warnings\n", len(entries), len(files),
+			report.Counts[consistency.SeverityError], report.Counts[consistency.SeverityWarning])
+	}
+
+	if report.Counts[consistency.SeverityError] > 0 || (*strict && report.Counts[consistency.SeverityWarning] > 0) {
+		return 1
+	}
+	return 0
+}
+
+// staticFiles expands directories to the files dnsmasq would read from
+// them that contain dhcp-host lines. Files named directly are always
+// checked.
+func staticFiles(args []string) ([]string, error) {
+	var files []string
+	parser := static.NewParser()
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+
+		dirEntries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range dirEntries {
+			name := e.Name()
+			// The files dnsmasq skips in a conf-dir
+			if e.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") ||
+				(strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#")) {
+				continue
+			}
+			path := filepath.Join(arg, name)
+			if parsed, err := parser.ParseFile(path); err == nil && len(parsed) > 0 {
+				files = append(files, path)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files with dhcp-host lines in %s", strings.Join(args, ", "))
+	}
+	return files, nil
+}
+
+// entryLocation returns the file and line of an entry from its ID
+func entryLocation(entry models.StaticDHCPEntry) (string, int) {
+	i := strings.LastIndex(entry.ID, ":")
+	line, _ := strconv.Atoi(entry.ID[i+1:])
+	return entry.ID[:i], line
+}
+
+// onlyStatic reports whether every source of an issue is a reservation
+func onlyStatic(sources []string) bool {
+	for _, source := range sources {
+		if !strings.HasPrefix(source, "static:") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+// ===== cmd/dhcpmonctl/client.go =====
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to the HTTP API of a running dhcpmon
+type Client struct {
+	base  *url.URL
+	token string
+	http  *http.Client
+}
+
+// Response is the envelope of /api/ responses
+type Response struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Errors  []string        `json:"errors"`
+}
+
+// NewClient creates a client for the server at rawURL
+func NewClient(rawURL, token string) (*Client, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL %q: %w", rawURL, err)
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	return &Client{
+		base:  base,
+		token: token,
+		http:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Get fetches a ?api= endpoint and decodes the JSON body into out
+func (c *Client) Get(api string, params url.Values, out interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("api", api)
+	u := *c.base
+	u.Path += "/"
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, out)
+}
+
+// Post sends body as JSON to an /api/ path and returns the response. A
+// response that is not successful is returned along with the error.
+func (c *Client) Post(path string, body interface{}) (*Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	u := *c.base
+	u.Path += path
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp Response
+	if err := c.do(req, &resp); err != nil {
+		return &resp, err
+	}
+	if !resp.Success {
+		return &resp, responseError(resp.Message, resp.Errors)
+	}
+	return &resp, nil
+}
+
+// do sends a request and decodes the JSON response into out. Error
+// statuses are turned into an error carrying the server's message.
+func (c *Client) do(req *http.Request, out interface{}) error {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		// Endpoints report errors as message/errors or error
+		var e struct {
+			Message string   `json:"message"`
+			Error   string   `json:"error"`
+			Errors  []string `json:"errors"`
+		}
+		json.Unmarshal(body, &e)
+		if e.Message == "" {
+			e.Message = e.Error
+		}
+		if e.Message == "" {
+			e.Message = strings.TrimSpace(string(body))
+		}
+		if e.Message == "" {
+			e.Message = resp.Status
+		}
+		// Keep the body for callers that want the details
+		json.Unmarshal(body, out)
+		return responseError(e.Message, e.Errors)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", req.URL.Path, err)
+	}
+	return nil
+}
+
+// responseError joins a message and its details into one error
+func responseError(message string, details []string) error {
+	if len(details) == 0 {
+		return fmt.Errorf("%s", message)
+	}
+	return fmt.Errorf("%s:\n  %s", message, strings.Join(details, "\n  "))
+}
@@ -0,0 +1,56 @@
+// ===== cmd/dhcpmonctl/hosts.go =====
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"dhcpmon/pkg/models"
+)
+
+// runHosts implements "hosts list"
+func runHosts(c *Client, args []string) int {
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Fprintln(os.Stderr, "Usage: dhcpmonctl hosts list [options]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("hosts list", flag.ExitOnError)
+	output := fs.String("o", outputTable, "output format: table, json or csv")
+	name := fs.String("name", "", "only entries with a name or alias containing this text")
+	ip := fs.String("ip", "", "only entries with this address")
+	fs.Parse(args[1:])
+	if err := checkOutput(*output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var resp struct {
+		Data []models.HostEntry `json:"data"`
+	}
+	if err := c.Get("hosts.json", nil, &resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	entries := []models.HostEntry{}
+	var rows [][]string
+	for _, e := range resp.Data {
+		if *ip != "" && e.IP != *ip {
+			continue
+		}
+		if *name != "" && !strings.Contains(strings.ToLower(strings.Join(e.Names(), " ")), strings.ToLower(*name)) {
+			continue
+		}
+		entries = append(entries, e)
+		rows = append(rows, []string{e.IP, e.Name, strings.Join(e.Alias, " "), e.Comment})
+	}
+
+	if err := printRows(*output, []string{"IP", "NAME", "ALIASES", "COMMENT"}, rows, entries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+// ===== cmd/dhcpmonctl/leases.go =====
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"dhcpmon/pkg/models"
+)
+
+// Lease is a lease as served by ?api=leases.json
+type Lease struct {
+	Expire string           `json:"expire"`
+	Remain string           `json:"remain"`
+	Delta  time.Duration    `json:"delta"`
+	MAC    string           `json:"mac"`
+	Info   *models.OUIEntry `json:"info"`
+	IP     string           `json:"ip"`
+	IPSort uint32           `json:"ipSort"`
+	Name   string           `json:"name"`
+	ID     string           `json:"id"`
+	Tag    string           `json:"tag"`
+	Static bool             `json:"static"`
+	Label  *models.Label    `json:"label,omitempty"`
+}
+
+// Vendor returns the vendor of the device, as labelled or registered
+func (l Lease) Vendor() string {
+	if l.Label != nil && l.Label.Vendor != "" {
+		return l.Label.Vendor
+	}
+	if l.Info != nil {
+		return l.Info.Company
+	}
+	return ""
+}
+
+// runLeases implements "leases list"
+func runLeases(c *Client, args []string) int {
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Fprintln(os.Stderr, "Usage: dhcpmonctl leases list [options]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("leases list", flag.ExitOnError)
+	output := fs.String("o", outputTable, "output format: table, json or csv")
+	mac := fs.String("mac", "", "only this MAC address, or MAC prefix")
+	ip := fs.String("ip", "", "only this address, or addresses in this CIDR")
+	name := fs.String("name", "", "only hostnames containing this text")
+	tag := fs.String("tag", "", "only leases with this tag")
+	static := fs.Bool("static", false, "only static leases")
+	dynamic := fs.Bool("dynamic", false, "only dynamic leases")
+	fs.Parse(args[1:])
+
+	if err := checkOutput(*output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	match, err := leaseFilter(*mac, *ip, *name, *tag, *static, *dynamic)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var resp struct {
+		Data []Lease `json:"data"`
+	}
+	if err := c.Get("leases.json", nil, &resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	leases := []Lease{}
+	var rows [][]string
+	for _, l := range resp.Data {
+		if !match(l) {
+			continue
+		}
+		leases = append(leases, l)
+		kind, expire := "dynamic", l.Expire
+		if l.Static {
+			kind, expire = "static", ""
+		}
+		rows = append(rows, []string{l.IP, l.MAC, l.Name, kind, expire, l.Tag, l.Vendor()})
+	}
+
+	if err := printRows(*output, []string{"IP", "MAC", "HOSTNAME", "TYPE", "EXPIRES", "TAG", "VENDOR"}, rows, leases); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// leaseFilter builds the filter of "leases list"
+func leaseFilter(mac, ip, name, tag string, static, dynamic bool) (func(Lease) bool, error) {
+	if static && dynamic {
+		return nil, fmt.Errorf("-static and -dynamic exclude each other")
+	}
+
+	var network *net.IPNet
+	var address net.IP
+	if ip != "" {
+		if strings.Contains(ip, "/") {
+			_, n, err := net.ParseCIDR(ip)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -ip: %w", err)
+			}
+			network = n
+		} else if address = net.ParseIP(ip); address == nil {
+			return nil, fmt.Errorf("invalid -ip: %s", ip)
+		}
+	}
+	mac = strings.ToUpper(strings.ReplaceAll(mac, "-", ":"))
+	name = strings.ToLower(name)
+
+	return func(l Lease) bool {
+		if mac != "" && !strings.HasPrefix(l.MAC, mac) {
+			return false
+		}
+		if network != nil && !network.Contains(net.ParseIP(l.IP)) {
+			return false
+		}
+		if address != nil && !address.Equal(net.ParseIP(l.IP)) {
+			return false
+		}
+		if name != "" && !strings.Contains(strings.ToLower(l.Name), name) {
+			return false
+		}
+		if tag != "" && l.Tag != tag {
+			return false
+		}
+		return !(static && !l.Static) && !(dynamic && l.Static)
+	}, nil
+}
@@ -0,0 +1,111 @@
+// ===== cmd/dhcpmonctl/logs.go =====
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// LogEntry is a log line as served by ?api=logs.json
+type LogEntry struct {
+	ID        uint64 `json:"id"`
+	Timestamp string `json:"when"`
+	UnixTime  int64  `json:"utime"`
+	Channel   string `json:"channel"`
+	Host      string `json:"host,omitempty"`
+	Message   string `json:"message"`
+	Event     string `json:"event,omitempty"`
+	MAC       string `json:"mac,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// logPage is a response of ?api=logs.json
+type logPage struct {
+	Data []LogEntry `json:"data"`
+	Next uint64     `json:"next"`
+}
+
+// runLogs implements "logs tail"
+func runLogs(c *Client, args []string) int {
+	if len(args) == 0 || args[0] != "tail" {
+		fmt.Fprintln(os.Stderr, "Usage: dhcpmonctl logs tail [options]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("logs tail", flag.ExitOnError)
+	lines := fs.Int("n", 20, "number of lines to show first")
+	follow := fs.Bool("f", false, "keep polling for new lines")
+	interval := fs.Duration("interval", 2*time.Second, "polling interval with -f")
+	output := fs.String("o", "text", "output format: text, or json for one object per line")
+	text := fs.String("q", "", "only lines containing this text")
+	mac := fs.String("mac", "", "only lines mentioning this MAC address")
+	ip := fs.String("ip", "", "only lines mentioning this IP address")
+	channel := fs.String("channel", "", "only lines from this channel")
+	host := fs.String("host", "", "only lines received from this syslog host")
+	fs.Parse(args[1:])
+	if *output != "text" && *output != outputJSON {
+		fmt.Fprintf(os.Stderr, "unknown output format %q (use text or json)\n", *output)
+		return 2
+	}
+
+	params := url.Values{}
+	for key, value := range map[string]string{"q": *text, "mac": *mac, "ip": *ip, "channel": *channel, "host": *host} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+
+	params.Set("limit", strconv.Itoa(*lines))
+	var page logPage
+	if err := c.Get("logs.json", params, &page); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	printLogs(*output, page.Data)
+	if !*follow {
+		return 0
+	}
+
+	params.Del("limit")
+	cursor := page.Next
+	for {
+		time.Sleep(*interval)
+		params.Set("cursor", strconv.FormatUint(cursor, 10))
+		if err := c.Get("logs.json", params, &page); err != nil {
+			// Keep following across restarts of the server
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		printLogs(*output, page.Data)
+		if page.Next > 0 {
+			cursor = page.Next
+		}
+	}
+}
+
+// printLogs writes log lines as text or JSON lines
+func printLogs(format string, entries []LogEntry) {
+	if format == outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			enc.Encode(e)
+		}
+		return
+	}
+	for _, e := range entries {
+		when := e.Timestamp
+		if t, err := time.Parse(time.RFC3339, e.Timestamp); err == nil {
+			when = t.Local().Format("2006-01-02 15:04:05")
+		}
+		source := e.Channel
+		if e.Host != "" {
+			source += "@" + e.Host
+		}
+		fmt.Printf("%s %s %s\n", when, source, e.Message)
+	}
+}
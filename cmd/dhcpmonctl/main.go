@@ -0,0 +1,137 @@
+// ===== cmd/dhcpmonctl/main.go =====
+// dhcpmonctl is a command-line client for the HTTP API of a running
+// dhcpmon.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// defaultServer matches the default httplisten of dhcpmon
+const defaultServer = "http://127.0.0.1:8067"
+
+// command is a subcommand; run returns the exit status
+type command struct {
+	name string
+	help string
+	run  func(c *Client, args []string) int
+}
+
+var commands = []command{
+	{"leases", "list leases", runLeases},
+	{"static", "list, change, validate, save or reload static reservations", runStatic},
+	{"hosts", "list hosts file entries", runHosts},
+	{"logs", "show or follow the log", runLogs},
+}
+
+func main() {
+	fs := flag.NewFlagSet("dhcpmonctl", flag.ExitOnError)
+	cfgFile := fs.String("config", "", "client configuration file (default $DHCPMONCTL_CONFIG or "+defaultConfigFile()+")")
+	server := fs.String("server", "", "dhcpmon URL (default $DHCPMON_URL, the configuration, or "+defaultServer+")")
+	token := fs.String("token", "", "API token (default $DHCPMON_TOKEN or the configuration)")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "Usage: dhcpmonctl [options] command [arguments]")
+		fmt.Fprintln(out, "\nCommands:")
+		for _, cmd := range commands {
+			fmt.Fprintf(out, "  %-8s %s\n", cmd.name, cmd.help)
+		}
+		fmt.Fprintln(out, "\nOptions:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	settings, err := loadSettings(*cfgFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *server != "" {
+		settings.Server = *server
+	}
+	if *token != "" {
+		settings.Token = *token
+	}
+
+	client, err := NewClient(settings.Server, settings.Token)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(cmd.run(client, fs.Args()[1:]))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+	fs.Usage()
+	os.Exit(2)
+}
+
+// Settings are where the server is and how to authenticate
+type Settings struct {
+	Server string
+	Token  string
+}
+
+// loadSettings reads the configuration file, then the environment. An
+// explicitly named file must exist; the default one is optional.
+func loadSettings(cfgFile string) (Settings, error) {
+	settings := Settings{Server: defaultServer}
+
+	explicit := cfgFile != ""
+	if !explicit {
+		cfgFile = os.Getenv("DHCPMONCTL_CONFIG")
+		explicit = cfgFile != ""
+	}
+	if !explicit {
+		cfgFile = defaultConfigFile()
+	}
+
+	if _, err := os.Stat(cfgFile); err == nil || explicit {
+		cfg, err := ini.LoadSources(ini.LoadOptions{Insensitive: true}, cfgFile)
+		if err != nil {
+			return settings, fmt.Errorf("failed to load %s: %w", cfgFile, err)
+		}
+		section := cfg.Section("")
+		settings.Server = section.Key("server").MustString(settings.Server)
+		settings.Token = section.Key("token").String()
+		if path := section.Key("tokenfile").String(); path != "" && settings.Token == "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return settings, fmt.Errorf("failed to read token: %w", err)
+			}
+			settings.Token = strings.TrimSpace(string(data))
+		}
+	}
+
+	if v := os.Getenv("DHCPMON_URL"); v != "" {
+		settings.Server = v
+	}
+	if v := os.Getenv("DHCPMON_TOKEN"); v != "" {
+		settings.Token = v
+	}
+	return settings, nil
+}
+
+// defaultConfigFile is dhcpmonctl.ini in the user's configuration
+// directory
+func defaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "dhcpmonctl.ini"
+	}
+	return filepath.Join(dir, "dhcpmon", "dhcpmonctl.ini")
+}
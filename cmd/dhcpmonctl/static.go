@@ -0,0 +1,301 @@
+// ===== cmd/dhcpmonctl/static.go =====
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// StaticEntry is a static reservation as the API serves and accepts it
+type StaticEntry struct {
+	ID         string `json:"id,omitempty"`
+	MAC        string `json:"mac"`
+	IP         string `json:"ip,omitempty"`
+	Hostname   string `json:"hostname,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	LeaseTime  string `json:"leaseTime,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	Enabled    bool   `json:"enabled"`
+	Ignore     bool   `json:"ignore,omitempty"`
+	LineNumber int    `json:"lineNumber,omitempty"`
+}
+
+// staticRequest is the body of POST /api/static
+type staticRequest struct {
+	Action string       `json:"action"`
+	ID     string       `json:"id,omitempty"`
+	Entry  *StaticEntry `json:"entry,omitempty"`
+	Subnet string       `json:"subnet,omitempty"`
+}
+
+const staticUsage = `Usage: dhcpmonctl static command [options] [arguments]
+
+Commands:
+  list                     list reservations
+  add -mac MAC [options]   add a reservation; -ip auto picks a free address
+  update ID|MAC [options]  change the given fields of a reservation
+  rm ID|MAC...             delete reservations
+  enable ID|MAC...         enable reservations
+  disable ID|MAC...        disable reservations
+  validate                 check the reservations for errors
+  save                     write the reservations to the static file
+  reload                   read the reservations from the static file
+
+Changes are kept in memory until saved; add -save to save right away.
+A MAC address selects the enabled reservation of the device.`
+
+// runStatic implements the static subcommands
+func runStatic(c *Client, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, staticUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return staticList(c, args[1:])
+	case "add", "update":
+		return staticEdit(c, args[0], args[1:])
+	case "rm", "enable", "disable":
+		return staticSelect(c, args[0], args[1:])
+	case "validate", "save", "reload":
+		return staticSimple(c, args[0], args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "unknown static command %q\n\n%s\n", args[0], staticUsage)
+	return 2
+}
+
+// staticList implements "static list"
+func staticList(c *Client, args []string) int {
+	fs := flag.NewFlagSet("static list", flag.ExitOnError)
+	output := fs.String("o", outputTable, "output format: table, json or csv")
+	fs.Parse(args)
+	if err := checkOutput(*output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	entries, err := staticEntries(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		state := "enabled"
+		if !e.Enabled {
+			state = "disabled"
+		}
+		if e.Ignore {
+			state += ",ignore"
+		}
+		rows[i] = []string{e.ID, e.MAC, e.IP, e.Hostname, e.Tag, e.LeaseTime, state, e.Comment}
+	}
+	if err := printRows(*output, []string{"ID", "MAC", "IP", "HOSTNAME", "TAG", "LEASE", "STATE", "COMMENT"}, rows, entries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// staticEdit implements "static add" and "static update". An update
+// changes only the fields given on the command line.
+func staticEdit(c *Client, action string, args []string) int {
+	fs := flag.NewFlagSet("static "+action, flag.ExitOnError)
+	mac := fs.String("mac", "", "MAC address")
+	ip := fs.String("ip", "", "IP address, or auto for a free address outside the dynamic pools")
+	subnet := fs.String("subnet", "", "network to pick an address in for -ip auto")
+	hostname := fs.String("hostname", "", "hostname")
+	tag := fs.String("tag", "", "dnsmasq tag")
+	leaseTime := fs.String("lease", "", "lease time, e.g. 12h or infinite")
+	comment := fs.String("comment", "", "comment")
+	disabled := fs.Bool("disabled", false, "disable the reservation; -disabled=false enables it")
+	ignore := fs.Bool("ignore", false, "ignore the device's DHCP requests")
+	save := fs.Bool("save", false, "save the static file afterwards")
+	fs.Parse(args)
+
+	var entry StaticEntry
+	req := staticRequest{Action: action, Entry: &entry, Subnet: *subnet}
+	if action == "add" {
+		if fs.NArg() != 0 || *mac == "" {
+			fmt.Fprintln(os.Stderr, "Usage: dhcpmonctl static add -mac MAC [options]")
+			return 2
+		}
+	} else {
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "Usage: dhcpmonctl static update [options] ID|MAC")
+			return 2
+		}
+		entries, err := staticEntries(c)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		current, err := findStatic(entries, fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		entry = current
+		req.ID = current.ID
+	}
+
+	entry.Enabled = entry.Enabled || action == "add"
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "mac":
+			entry.MAC = *mac
+		case "ip":
+			entry.IP = *ip
+		case "hostname":
+			entry.Hostname = *hostname
+		case "tag":
+			entry.Tag = *tag
+		case "lease":
+			entry.LeaseTime = *leaseTime
+		case "comment":
+			entry.Comment = *comment
+		case "disabled":
+			entry.Enabled = !*disabled
+		case "ignore":
+			entry.Ignore = *ignore
+		}
+	})
+
+	resp, err := c.Post("/api/static", req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println(resp.Message)
+	return saveStatic(c, *save)
+}
+
+// staticSelect implements "static rm", "enable" and "disable"
+func staticSelect(c *Client, action string, args []string) int {
+	fs := flag.NewFlagSet("static "+action, flag.ExitOnError)
+	save := fs.Bool("save", false, "save the static file afterwards")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: dhcpmonctl static %s [-save] ID|MAC...\n", action)
+		return 2
+	}
+
+	apiAction := action
+	if action == "rm" {
+		apiAction = "delete"
+	}
+
+	// Resolve everything first; IDs only change when the file is reloaded,
+	// so they stay valid while the changes are made
+	entries, err := staticEntries(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	var ids []string
+	for _, arg := range fs.Args() {
+		entry, err := findStatic(entries, arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	status := 0
+	for i, id := range ids {
+		resp, err := c.Post("/api/static", staticRequest{Action: apiAction, ID: id})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(i), err)
+			status = 1
+			continue
+		}
+		fmt.Printf("%s: %s\n", fs.Arg(i), resp.Message)
+	}
+	if status != 0 {
+		return status
+	}
+	return saveStatic(c, *save)
+}
+
+// staticSimple implements "static validate", "save" and "reload"
+func staticSimple(c *Client, action string, args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintf(os.Stderr, "Usage: dhcpmonctl static %s\n", action)
+		return 2
+	}
+	resp, err := c.Post("/api/static", staticRequest{Action: action})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println(resp.Message)
+	return 0
+}
+
+// saveStatic saves the static file when save is set
+func saveStatic(c *Client, save bool) int {
+	if !save {
+		return 0
+	}
+	return staticSimple(c, "save", nil)
+}
+
+// staticEntries returns all static reservations
+func staticEntries(c *Client) ([]StaticEntry, error) {
+	var resp struct {
+		Data []StaticEntry `json:"data"`
+	}
+	if err := c.Get("static", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// findStatic returns the reservation with an ID, or the reservation of
+// a MAC address; of several, the enabled one
+func findStatic(entries []StaticEntry, arg string) (StaticEntry, error) {
+	mac, err := net.ParseMAC(arg)
+	if err != nil {
+		for _, e := range entries {
+			if e.ID == arg {
+				return e, nil
+			}
+		}
+		return StaticEntry{}, fmt.Errorf("no reservation with ID %s", arg)
+	}
+
+	var found []StaticEntry
+	for _, e := range entries {
+		if m, err := net.ParseMAC(e.MAC); err == nil && m.String() == mac.String() {
+			found = append(found, e)
+		}
+	}
+	if len(found) > 1 {
+		var enabled []StaticEntry
+		for _, e := range found {
+			if e.Enabled {
+				enabled = append(enabled, e)
+			}
+		}
+		if len(enabled) > 0 {
+			found = enabled
+		}
+	}
+	switch len(found) {
+	case 0:
+		return StaticEntry{}, fmt.Errorf("no reservation for %s", strings.ToUpper(mac.String()))
+	case 1:
+		return found[0], nil
+	}
+	ids := make([]string, len(found))
+	for i, e := range found {
+		ids[i] = e.ID
+	}
+	return StaticEntry{}, fmt.Errorf("%s has several reservations (%s); select one by ID", strings.ToUpper(mac.String()), strings.Join(ids, ", "))
+}
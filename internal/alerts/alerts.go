@@ -0,0 +1,381 @@
+// ===== internal/alerts/alerts.go =====
+package alerts
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"dhcpmon/internal/ipam"
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// Alert levels
+const (
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+)
+
+// Alert sources
+const (
+	SourcePool = "pool" // Pool utilization crossed a threshold
+	SourceLog  = "log"  // dnsmasq reported it has no address to offer
+)
+
+const (
+	// hysteresis is how many percentage points utilization must fall
+	// below a threshold before its alert is lowered or cleared, so a pool
+	// hovering around the threshold does not flap
+	hysteresis = 2.0
+
+	// exhaustionHold is how long an exhaustion reported by dnsmasq stays
+	// active after the last log line reporting it
+	exhaustionHold = 15 * time.Minute
+
+	// historySize is the number of alert changes kept
+	historySize = 200
+
+	// queueSize is the number of notifications waiting to be delivered
+	// before new ones are dropped
+	queueSize = 64
+)
+
+// exhaustionRe matches dnsmasq's "no address available" lines, e.g.
+// "DHCPDISCOVER(eth0) aa:bb:cc:dd:ee:ff no address available", with an
+// optional transaction ID in front when log-dhcp is set
+var exhaustionRe = regexp.MustCompile(`\bDHCP[A-Z]+\(([^)]+)\)\s+(?:(\S+)\s+)?no address(?:es)? available`)
+
+// Alert is a raised or resolved condition. The same Key is kept while a
+// condition lasts, whatever its level.
+type Alert struct {
+	Key         string     `json:"key"`
+	Level       string     `json:"level"`
+	Source      string     `json:"source"`
+	Subnet      string     `json:"subnet,omitempty"`
+	Pool        string     `json:"pool,omitempty"`
+	Interface   string     `json:"interface,omitempty"`
+	Utilization float64    `json:"utilization"`
+	Message     string     `json:"message"`
+	Count       int        `json:"count"` // Evaluations or log lines that reported it
+	FiredAt     time.Time  `json:"firedAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ResolvedAt  *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// Resolved reports whether the condition has cleared
+func (a Alert) Resolved() bool {
+	return a.ResolvedAt != nil
+}
+
+// Notifier delivers alerts to a notification channel. It is called when
+// an alert is raised, changes level and is resolved, from a single
+// goroutine and in order.
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface
+type NotifierFunc func(alert Alert) error
+
+// Notify calls f(alert)
+func (f NotifierFunc) Notify(alert Alert) error {
+	return f(alert)
+}
+
+// Manager raises alerts when pools fill up or run out, and keeps the
+// active ones and a history of changes
+type Manager struct {
+	warn float64 // Percent; 0 disables the level
+	crit float64
+
+	active     map[string]*Alert
+	history    []Alert
+	interfaces map[string]string // Subnet of each interface, from the last evaluation
+	notifiers  []Notifier
+	queue      chan Alert
+	started    bool
+	mu         sync.Mutex
+	stopCh     chan struct{}
+	doneCh     chan struct{}
+}
+
+// NewManager creates a manager raising warning and critical pool alerts
+// at the given utilization percentages
+func NewManager(warn, crit float64) *Manager {
+	return &Manager{
+		warn:       warn,
+		crit:       crit,
+		active:     make(map[string]*Alert),
+		interfaces: make(map[string]string),
+		queue:      make(chan Alert, queueSize),
+		stopCh:     make(chan struct{}),
+		doneCh:     make(chan struct{}),
+	}
+}
+
+// Thresholds returns the warning and critical utilization percentages
+func (m *Manager) Thresholds() (warn, crit float64) {
+	return m.warn, m.crit
+}
+
+// AddNotifier registers a notification channel
+func (m *Manager) AddNotifier(n Notifier) {
+	m.mu.Lock()
+	m.notifiers = append(m.notifiers, n)
+	m.mu.Unlock()
+}
+
+// Start begins delivering notifications
+func (m *Manager) Start() {
+	m.mu.Lock()
+	m.started = true
+	m.mu.Unlock()
+	go m.deliverLoop()
+}
+
+// Stop delivers the queued notifications and stops
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	started := m.started
+	m.mu.Unlock()
+
+	if started {
+		close(m.stopCh)
+		<-m.doneCh
+	}
+}
+
+// Active returns the active alerts, critical ones first
+func (m *Manager) Active() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(m.active))
+	for _, a := range m.active {
+		alerts = append(alerts, *a)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Level != alerts[j].Level {
+			return alerts[i].Level == LevelCritical
+		}
+		return alerts[i].FiredAt.Before(alerts[j].FiredAt)
+	})
+	return alerts
+}
+
+// History returns the recent alert changes, newest first
+func (m *Manager) History() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make([]Alert, len(m.history))
+	for i, a := range m.history {
+		alerts[len(alerts)-1-i] = a
+	}
+	return alerts
+}
+
+// EvaluatePools raises, changes and resolves pool utilization alerts
+// from the current usage, and expires exhaustion alerts that dnsmasq has
+// stopped reporting
+func (m *Manager) EvaluatePools(usage []ipam.SubnetUsage) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, subnet := range usage {
+		if subnet.Interface != "" {
+			m.interfaces[subnet.Interface] = subnet.Network
+		}
+		for _, pool := range subnet.Pools {
+			if pool.Size == 0 {
+				continue
+			}
+			key := "pool:" + pool.Range
+			seen[key] = true
+
+			current := ""
+			if a, ok := m.active[key]; ok {
+				current = a.Level
+			}
+			level := m.poolLevel(pool.Utilization, current)
+			if level == "" {
+				m.resolve(key, now)
+				continue
+			}
+
+			message := fmt.Sprintf("Pool %s is %.1f%% used (%d of %d addresses, %d free)",
+				pool.Range, pool.Utilization, pool.Size-pool.Free, pool.Size, pool.Free)
+			if pool.Free == 0 {
+				message = fmt.Sprintf("Pool %s is full (%d addresses in use)", pool.Range, pool.Size)
+			}
+			m.raise(Alert{
+				Key:         key,
+				Level:       level,
+				Source:      SourcePool,
+				Subnet:      subnet.Network,
+				Pool:        pool.Range,
+				Interface:   subnet.Interface,
+				Utilization: pool.Utilization,
+				Message:     message,
+			}, now)
+		}
+	}
+
+	for key, a := range m.active {
+		switch {
+		case a.Source == SourcePool && !seen[key]:
+			// The pool was removed from the configuration
+			m.resolve(key, now)
+		case a.Source == SourceLog && now.Sub(a.UpdatedAt) > exhaustionHold:
+			m.resolve(key, now)
+		}
+	}
+}
+
+// ObserveLog raises a critical alert as soon as dnsmasq logs that it has
+// no address available for a client. It is a logs.Manager subscriber.
+func (m *Manager) ObserveLog(entry models.LogEntry) {
+	match := exhaustionRe.FindStringSubmatch(entry.Message)
+	if match == nil {
+		return
+	}
+	// Ignore old lines replayed from the journal at startup
+	if time.Since(entry.Timestamp) > exhaustionHold {
+		return
+	}
+	iface, client := match[1], match[2]
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message := fmt.Sprintf("dnsmasq has no address available on %s", iface)
+	if client != "" {
+		message += fmt.Sprintf(" (last client %s)", client)
+	}
+	m.raise(Alert{
+		Key:       "exhausted:" + iface,
+		Level:     LevelCritical,
+		Source:    SourceLog,
+		Subnet:    m.interfaces[iface],
+		Interface: iface,
+		Message:   message,
+	}, entry.Timestamp)
+}
+
+// poolLevel returns the alert level for a utilization, given the level
+// currently raised
+func (m *Manager) poolLevel(utilization float64, current string) string {
+	switch {
+	case m.crit > 0 && (utilization >= m.crit || current == LevelCritical && utilization > m.crit-hysteresis):
+		return LevelCritical
+	case m.warn > 0 && (utilization >= m.warn || current != "" && utilization > m.warn-hysteresis):
+		return LevelWarning
+	}
+	return ""
+}
+
+// raise records an alert, notifying when it is new or its level changed.
+// The caller holds m.mu.
+func (m *Manager) raise(alert Alert, now time.Time) {
+	a, ok := m.active[alert.Key]
+	if ok && a.Level == alert.Level {
+		a.Message = alert.Message
+		a.Utilization = alert.Utilization
+		a.Count++
+		a.UpdatedAt = now
+		if alert.Subnet != "" {
+			a.Subnet = alert.Subnet
+		}
+		return
+	}
+
+	alert.Count = 1
+	alert.FiredAt = now
+	alert.UpdatedAt = now
+	if ok {
+		// Same condition at another level keeps its start time
+		alert.Count = a.Count + 1
+		alert.FiredAt = a.FiredAt
+	}
+	m.active[alert.Key] = &alert
+	m.record(alert)
+}
+
+// resolve clears the alert with key, if active. The caller holds m.mu.
+func (m *Manager) resolve(key string, now time.Time) {
+	a, ok := m.active[key]
+	if !ok {
+		return
+	}
+	delete(m.active, key)
+
+	resolved := *a
+	resolved.UpdatedAt = now
+	resolved.ResolvedAt = &now
+	if resolved.Source == SourceLog {
+		resolved.Message = fmt.Sprintf("dnsmasq has not reported exhaustion on %s for %s", resolved.Interface, exhaustionHold)
+	} else {
+		resolved.Message = fmt.Sprintf("Pool %s is back below the alert threshold", resolved.Pool)
+	}
+	m.record(resolved)
+}
+
+// record adds a change to the history and queues its notification. The
+// caller holds m.mu.
+func (m *Manager) record(alert Alert) {
+	m.history = append(m.history, alert)
+	if len(m.history) > historySize {
+		m.history = m.history[len(m.history)-historySize:]
+	}
+
+	select {
+	case m.queue <- alert:
+	default:
+		utils.Warnf("Warning: alert notification queue full, dropping %s alert %s", alert.Level, alert.Key)
+	}
+}
+
+// deliverLoop logs each alert change and hands it to the notifiers
+func (m *Manager) deliverLoop() {
+	defer close(m.doneCh)
+
+	for {
+		select {
+		case alert := <-m.queue:
+			m.deliver(alert)
+		case <-m.stopCh:
+			for {
+				select {
+				case alert := <-m.queue:
+					m.deliver(alert)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (m *Manager) deliver(alert Alert) {
+	state := alert.Level
+	if alert.Resolved() {
+		state = "resolved"
+	}
+	utils.Infof("Alert [%s] %s", state, alert.Message)
+
+	m.mu.Lock()
+	notifiers := append([]Notifier(nil), m.notifiers...)
+	m.mu.Unlock()
+
+	for _, n := range notifiers {
+		if err := n.Notify(alert); err != nil {
+			utils.Warnf("Warning: failed to send alert notification: %v", err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// ===== internal/alerts/alerts_test.go =====
+package alerts
+
+import (
+	"testing"
+
+	"dhcpmon/internal/ipam"
+)
+
+func TestPoolLevel(t *testing.T) {
+	m := NewManager(80, 90)
+	tests := []struct {
+		utilization float64
+		current     string
+		want        string
+	}{
+		{79.9, "", ""},
+		{80, "", LevelWarning},
+		{78.5, LevelWarning, LevelWarning}, // Within the hysteresis
+		{78, LevelWarning, ""},
+		{90, "", LevelCritical},
+		{90, LevelWarning, LevelCritical},
+		{88.5, LevelCritical, LevelCritical},
+		{88, LevelCritical, LevelWarning},
+		{78.5, LevelCritical, LevelWarning},
+		{77, LevelCritical, ""},
+	}
+	for _, tt := range tests {
+		if got := m.poolLevel(tt.utilization, tt.current); got != tt.want {
+			t.Errorf("poolLevel(%.1f, %q) = %q, want %q", tt.utilization, tt.current, got, tt.want)
+		}
+	}
+
+	// A threshold of 0 disables its level
+	m = NewManager(0, 90)
+	if got := m.poolLevel(85, ""); got != "" {
+		t.Errorf("without a warning threshold, poolLevel(85) = %q", got)
+	}
+	if got := m.poolLevel(89, LevelCritical); got != LevelCritical {
+		t.Errorf("without a warning threshold, poolLevel(89, critical) = %q", got)
+	}
+	if got := m.poolLevel(87, LevelCritical); got != "" {
+		t.Errorf("without a warning threshold, poolLevel(87, critical) = %q", got)
+	}
+}
+
+// poolUsage returns the usage of one 100-address pool at utilization
+func poolUsage(utilization float64) []ipam.SubnetUsage {
+	free := uint64(100 - utilization)
+	return []ipam.SubnetUsage{{
+		Network: "192.168.1.0/24",
+		Pools: []ipam.PoolUsage{{
+			Range:       "192.168.1.100-192.168.1.199",
+			Size:        100,
+			Free:        free,
+			Utilization: utilization,
+		}},
+	}}
+}
+
+func TestEvaluatePoolsHysteresis(t *testing.T) {
+	m := NewManager(80, 90)
+	steps := []struct {
+		utilization float64
+		level       string // Active level afterwards, "" when none
+		changes     int    // History entries so far
+	}{
+		{81, LevelWarning, 1},
+		{79, LevelWarning, 1}, // Held by the hysteresis
+		{81, LevelWarning, 1},
+		{77.9, "", 2}, // Resolved
+		{79, "", 2},
+		{91, LevelCritical, 3},
+		{89, LevelCritical, 3},
+		{87, LevelWarning, 4},
+		{50, "", 5},
+	}
+	for i, step := range steps {
+		m.EvaluatePools(poolUsage(step.utilization))
+
+		level := ""
+		if active := m.Active(); len(active) == 1 {
+			level = active[0].Level
+		} else if len(active) > 1 {
+			t.Fatalf("step %d: %d active alerts", i, len(active))
+		}
+		if level != step.level {
+			t.Errorf("step %d at %.1f%%: level %q, want %q", i, step.utilization, level, step.level)
+		}
+		if got := len(m.History()); got != step.changes {
+			t.Errorf("step %d at %.1f%%: %d changes, want %d", i, step.utilization, got, step.changes)
+		}
+	}
+
+	history := m.History()
+	if !history[0].Resolved() {
+		t.Errorf("last change is not a resolution: %+v", history[0])
+	}
+	// The critical alert lowered to a warning is the same condition
+	lowered, raised := history[1], history[2]
+	if lowered.Level != LevelWarning || raised.Level != LevelCritical || !lowered.FiredAt.Equal(raised.FiredAt) {
+		t.Errorf("lowered alert %s fired at %s, critical %s fired at %s",
+			lowered.Level, lowered.FiredAt, raised.Level, raised.FiredAt)
+	}
+}
+
+func TestEvaluatePoolsRemovedPool(t *testing.T) {
+	m := NewManager(80, 90)
+	m.EvaluatePools(poolUsage(95))
+	if len(m.Active()) != 1 {
+		t.Fatalf("no alert for a full pool")
+	}
+	m.EvaluatePools(nil)
+	if active := m.Active(); len(active) != 0 {
+		t.Errorf("alert for a removed pool still active: %+v", active)
+	}
+}
@@ -0,0 +1,249 @@
+// ===== internal/approvals/store.go =====
+package approvals
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// Store holds the approval state of devices that joined the network,
+// persisted as JSON. MACs without an entry were on the network before
+// approvals were tracked.
+type Store struct {
+	file    string
+	devices map[string]models.Approval
+	mu      sync.RWMutex
+}
+
+// NewStore creates an approval store backed by file; an empty file keeps
+// the decisions in memory only
+func NewStore(file string) *Store {
+	return &Store{
+		file:    file,
+		devices: make(map[string]models.Approval),
+	}
+}
+
+// Load reads the approvals from disk; a missing file is an empty store
+func (s *Store) Load() error {
+	if s.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read approvals: %w", err)
+	}
+
+	var stored []models.Approval
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", s.file, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.devices = make(map[string]models.Approval, len(stored))
+	for _, a := range stored {
+		mac, err := normalizeMAC(a.MAC)
+		if err != nil {
+			utils.Warnf("Warning: skipping approval for %q: %v", a.MAC, err)
+			continue
+		}
+		a.MAC = mac
+		s.devices[mac] = a
+	}
+
+	utils.Infof("Loaded %d device approvals", len(s.devices))
+	return nil
+}
+
+// List returns the approvals in state, or all of them when state is
+// empty, newest first
+func (s *Store) List(state string) []models.Approval {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Approval, 0, len(s.devices))
+	for _, a := range s.devices {
+		if state == "" || a.State == state {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].FirstSeen.Equal(result[j].FirstSeen) {
+			return result[i].FirstSeen.After(result[j].FirstSeen)
+		}
+		return result[i].MAC < result[j].MAC
+	})
+	return result
+}
+
+// Get returns the approval of mac
+func (s *Store) Get(mac string) (models.Approval, bool) {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return models.Approval{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.devices[mac]
+	return a, ok
+}
+
+// Counts returns the number of devices in each state
+func (s *Store) Counts() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[string]int{
+		models.ApprovalPending:  0,
+		models.ApprovalApproved: 0,
+		models.ApprovalBlocked:  0,
+	}
+	for _, a := range s.devices {
+		counts[a.State]++
+	}
+	return counts
+}
+
+// AddPending queues a device seen for the first time. It returns the
+// stored approval and whether it was added; a MAC that already has a
+// decision keeps it.
+func (s *Store) AddPending(a models.Approval) (models.Approval, bool, error) {
+	mac, err := normalizeMAC(a.MAC)
+	if err != nil {
+		return a, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.devices[mac]; ok {
+		return existing, false, nil
+	}
+
+	a.MAC = mac
+	a.State = models.ApprovalPending
+	a.DecidedAt = nil
+	a.DecidedBy = ""
+	if a.FirstSeen.IsZero() {
+		a.FirstSeen = time.Now()
+	}
+	s.devices[mac] = a
+	return a, true, s.save()
+}
+
+// Decide approves or blocks a device. A MAC that was never queued, such
+// as one on the network before approvals were tracked, is added.
+func (s *Store) Decide(mac, state, by, note string) (models.Approval, error) {
+	if state != models.ApprovalApproved && state != models.ApprovalBlocked {
+		return models.Approval{}, fmt.Errorf("invalid approval state %q", state)
+	}
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return models.Approval{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	a, ok := s.devices[mac]
+	if !ok {
+		a = models.Approval{MAC: mac, FirstSeen: now}
+	}
+	a.State = state
+	if state != models.ApprovalBlocked {
+		a.Quarantined = nil
+	}
+	a.DecidedAt = &now
+	a.DecidedBy = by
+	if note = strings.TrimSpace(note); note != "" {
+		a.Note = note
+	}
+	s.devices[mac] = a
+	return a, s.save()
+}
+
+// SetQuarantined records the keys of the reservations a quarantine of a
+// blocked device disabled
+func (s *Store) SetQuarantined(mac string, keys []string) error {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.devices[mac]
+	if !ok || a.State != models.ApprovalBlocked {
+		return fmt.Errorf("%s is not blocked", mac)
+	}
+	a.Quarantined = keys
+	s.devices[mac] = a
+	return s.save()
+}
+
+// Delete forgets a device, so it is queued again when next seen as new
+func (s *Store) Delete(mac string) error {
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.devices[mac]; !ok {
+		return fmt.Errorf("no approval for %s", mac)
+	}
+	delete(s.devices, mac)
+	return s.save()
+}
+
+// save writes the approvals atomically; the caller holds s.mu
+func (s *Store) save() error {
+	if s.file == "" {
+		return nil
+	}
+
+	stored := make([]models.Approval, 0, len(s.devices))
+	for _, a := range s.devices {
+		stored = append(stored, a)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].MAC < stored[j].MAC })
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(s.file, data, 0644); err != nil {
+		return fmt.Errorf("failed to save approvals: %w", err)
+	}
+	return nil
+}
+
+// normalizeMAC returns mac in upper case, colon-separated form
+func normalizeMAC(mac string) (string, error) {
+	hw, err := net.ParseMAC(strings.TrimSpace(mac))
+	if err != nil || len(hw) != 6 {
+		return "", fmt.Errorf("invalid MAC address %q", mac)
+	}
+	return strings.ToUpper(hw.String()), nil
+}
@@ -2,17 +2,26 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
-	"log"
+	"strings"
+	"time"
 	"gopkg.in/ini.v1"
+	
+	"dhcpmon/pkg/utils"
 )
 
+// megabyte is the unit used for log size settings
+const megabyte = 1024 * 1024
+
 // HTMLTemplates holds template file mappings
 type HTMLTemplates struct {
 	Bootstrap string
 	Leases    string
 	Hosts     string
+	Devices   string
+	IPAM      string
 	Logs      string
 	Help      string
 	About     string
@@ -24,17 +33,55 @@ type Config struct {
 	// File paths
 	LeasesFile    string
 	HTMLDir       string
-	MACDBFile     string
+	MACDBFiles    []string // Vendor sources, highest priority first
 	HostsFile     string
 	StaticFile    string
+	DNSMasqConf   []string // dnsmasq configuration files and directories, for dhcp-range
+	LogDir        string
+	DevicesFile   string // Device sighting history; empty keeps it in memory
+	LabelsFile    string // User-defined device labels and vendor overrides
+	ApprovalsFile string // Approval state of new devices
+	QuarantineTag string // dnsmasq tag set on quarantined devices
+	
+	// Log retention
+	LogMaxSize     int64         // Total bytes kept in LogDir
+	LogSegmentSize int64         // Bytes per log segment file
+	LogMaxAge      time.Duration // Segments older than this are removed
+	
+	// Pool utilization alert thresholds in percent; 0 disables a level
+	PoolWarn      float64
+	PoolCrit      float64
+	
+	// Notification channels
+	Notify        []NotifyChannel
 	
 	// Network settings
 	HTTPListen    string
+	LogLevel      string // debug, info, warning or error
+	APIToken      string // Bearer token that allows API changes when Edit is off
 	NmapOpts      string
 	
+	// Remote syslog receiver (disabled when SyslogListen is empty)
+	SyslogListen   string
+	SyslogProtocol string   // udp, tcp or both
+	SyslogPrograms []string // Program name prefixes to keep; empty keeps all
+	
 	// Binary paths
 	DNSMasq       string
+	DHCPRelease   string // dnsmasq's dhcp_release helper, to revoke IPv4 leases
+	DHCPRelease6  string // dhcp_release6, to revoke IPv6 leases
 	Nmap          string
+	Journalctl    string
+	Systemctl     string
+	
+	// Supervised dnsmasq (when SystemD is not set)
+	DNSMasqArgs       string
+	RestartPolicy     string        // always, on-failure or never
+	RestartBackoff    time.Duration // First restart delay, doubled per crash
+	RestartMaxBackoff time.Duration
+	
+	// systemd unit running dnsmasq when SystemD is set
+	SystemdUnit   string
 	
 	// Feature flags
 	SystemD       bool
@@ -47,6 +94,10 @@ type Config struct {
 	
 	// HTML Templates
 	Templates     HTMLTemplates
+	
+	// Where the settings came from
+	File          string            // Configuration file read; empty when none was
+	Sources       map[string]Source // Settings not left at their defaults, by INI key
 }
 
 // DefaultConfig returns a configuration with default values
@@ -55,9 +106,21 @@ func DefaultConfig() *Config {
 		LeasesFile:   "/var/lib/misc/dnsmasq.leases",
 		HTMLDir:      "/app/html",
 		HTTPListen:   "127.0.0.1:8067",
+		LogLevel:     "info",
+		SyslogProtocol: "udp",
+		SyslogPrograms: []string{"dnsmasq"},
 		DNSMasq:      "/usr/sbin/dnsmasq",
+		DHCPRelease:  "/usr/bin/dhcp_release",
+		DHCPRelease6: "/usr/bin/dhcp_release6",
+		DNSMasqArgs:  "--keep-in-foreground --log-facility=- --conf-dir=/etc/dnsmasq.d,*conf",
+		RestartPolicy:     "always",
+		RestartBackoff:    time.Second,
+		RestartMaxBackoff: 2 * time.Minute,
+		Systemctl:    "/bin/systemctl",
 		SystemD:      false,
-		MACDBFile:    "/app/macaddress.io-db.json",
+		SystemdUnit:  "dnsmasq.service",
+		Journalctl:   "/bin/journalctl",
+		MACDBFiles:   []string{"/app/macaddress.io-db.json"},
 		MACDBPreload: false,
 		Nmap:         "/usr/bin/nmap",
 		NmapOpts:     "-oG - -n -F 192.168.12.0/24",
@@ -66,17 +129,31 @@ func DefaultConfig() *Config {
 		HTTPSLinks:   true,
 		SSHLinks:     true,
 		StaticFile:   "/etc/dnsmasq.d/static.conf",
+		DNSMasqConf:  []string{"/etc/dnsmasq.conf", "/etc/dnsmasq.d"},
+		LogDir:       "/var/lib/dhcpmon/logs",
+		DevicesFile:  "/var/lib/dhcpmon/devices.json",
+		LabelsFile:   "/var/lib/dhcpmon/labels.json",
+		ApprovalsFile: "/var/lib/dhcpmon/approvals.json",
+		QuarantineTag: "quarantine",
+		LogMaxSize:     64 * megabyte,
+		LogSegmentSize: 4 * megabyte,
+		LogMaxAge:      30 * 24 * time.Hour,
+		PoolWarn:     80,
+		PoolCrit:     95,
 		NetworkTags:  false,
 		Edit:         true,
 		Templates: HTMLTemplates{
 			Bootstrap: "bootstrap.tmpl",
 			Leases:    "leases.tmpl", 
 			Hosts:     "hosts.tmpl",
+			Devices:   "devices.tmpl",
+			IPAM:      "ipam.tmpl",
 			Logs:      "logs.tmpl",
 			Help:      "help.tmpl",
 			About:     "about.tmpl",
 			System:    "system.tmpl",
 		},
+		Sources: make(map[string]Source),
 	}
 }
 
@@ -84,128 +161,307 @@ func DefaultConfig() *Config {
 func (c *Config) LoadFromFile(filename string) error {
 	cfg, err := ini.LoadSources(ini.LoadOptions{Insensitive: true}, filename)
 	if err != nil {
-		log.Printf("Skipping config file %s: %s", filename, err)
+		utils.Infof("Skipping config file %s: %s", filename, err)
 		return err
 	}
+	c.File = filename
+
+	c.apply(cfg, SourceFile)
+	c.Notify = loadNotifyChannels(cfg)
+	for _, section := range cfg.Sections() {
+		if strings.HasPrefix(section.Name(), notifySectionPrefix) {
+			c.setSource(section.Name(), SourceFile, "")
+		}
+	}
 
+	return nil
+}
+
+// apply sets the settings present in the main and html sections of an
+// INI file, recording origin as the source of each value applied
+func (c *Config) apply(cfg *ini.File, origin string) {
 	// Load main section
-	section := cfg.Section("")
-	c.LeasesFile = section.Key("leasesfile").MustString(c.LeasesFile)
-	c.HTMLDir = section.Key("htmldir").MustString(c.HTMLDir)
-	c.HTTPListen = section.Key("httplisten").MustString(c.HTTPListen)
-	c.DNSMasq = section.Key("dnsmasq").MustString(c.DNSMasq)
-	c.SystemD = section.Key("systemd").MustBool(c.SystemD)
-	c.MACDBFile = section.Key("macdbfile").MustString(c.MACDBFile)
-	c.MACDBPreload = section.Key("macdbpreload").MustBool(c.MACDBPreload)
-	c.Nmap = section.Key("nmap").MustString(c.Nmap)
-	c.NmapOpts = section.Key("nmapopts").MustString(c.NmapOpts)
-	c.HostsFile = section.Key("hostsfile").MustString(c.HostsFile)
-	c.HTTPLinks = section.Key("httplinks").MustBool(c.HTTPLinks)
-	c.HTTPSLinks = section.Key("httpslinks").MustBool(c.HTTPSLinks)
-	c.SSHLinks = section.Key("sshlinks").MustBool(c.SSHLinks)
-	c.StaticFile = section.Key("staticfile").MustString(c.StaticFile)
-	c.NetworkTags = section.Key("networktags").MustBool(c.NetworkTags)
-	c.Edit = section.Key("edit").MustBool(c.Edit)
+	s := settings{c: c, section: cfg.Section(""), origin: origin}
+	s.text("leasesfile", &c.LeasesFile)
+	s.text("htmldir", &c.HTMLDir)
+	s.text("httplisten", &c.HTTPListen)
+	s.text("apitoken", &c.APIToken)
+	s.text("loglevel", &c.LogLevel)
+	s.text("sysloglisten", &c.SyslogListen)
+	s.text("syslogprotocol", &c.SyslogProtocol)
+	s.list("syslogprograms", &c.SyslogPrograms)
+	s.text("dnsmasq", &c.DNSMasq)
+	s.text("dhcprelease", &c.DHCPRelease)
+	s.text("dhcprelease6", &c.DHCPRelease6)
+	s.text("dnsmasqargs", &c.DNSMasqArgs)
+	s.oneOf("restartpolicy", &c.RestartPolicy, restartPolicies)
+	s.duration("restartbackoff", &c.RestartBackoff)
+	s.duration("restartmaxbackoff", &c.RestartMaxBackoff)
+	s.text("systemctl", &c.Systemctl)
+	s.boolean("systemd", &c.SystemD)
+	s.text("systemdunit", &c.SystemdUnit)
+	s.text("journalctl", &c.Journalctl)
+	s.list("macdbfile", &c.MACDBFiles)
+	s.boolean("macdbpreload", &c.MACDBPreload)
+	s.text("nmap", &c.Nmap)
+	s.text("nmapopts", &c.NmapOpts)
+	s.text("hostsfile", &c.HostsFile)
+	s.boolean("httplinks", &c.HTTPLinks)
+	s.boolean("httpslinks", &c.HTTPSLinks)
+	s.boolean("sshlinks", &c.SSHLinks)
+	s.text("staticfile", &c.StaticFile)
+	s.list("dnsmasqconf", &c.DNSMasqConf)
+	s.boolean("networktags", &c.NetworkTags)
+	s.boolean("edit", &c.Edit)
+	// An empty file setting is meaningful (in-memory store)
+	s.textOrEmpty("logdir", &c.LogDir)
+	s.textOrEmpty("devicesfile", &c.DevicesFile)
+	s.textOrEmpty("labelsfile", &c.LabelsFile)
+	s.textOrEmpty("approvalsfile", &c.ApprovalsFile)
+	s.text("quarantinetag", &c.QuarantineTag)
+	s.megabytes("logmaxsize", &c.LogMaxSize)
+	s.megabytes("logsegmentsize", &c.LogSegmentSize)
+	s.duration("logmaxage", &c.LogMaxAge)
+	s.float("poolwarn", &c.PoolWarn)
+	s.float("poolcrit", &c.PoolCrit)
 
 	// Load HTML templates section
 	if htmlSection, err := cfg.GetSection("html"); err == nil {
-		c.Templates.Bootstrap = htmlSection.Key("bootstrap").MustString(c.Templates.Bootstrap)
-		c.Templates.Leases = htmlSection.Key("leases").MustString(c.Templates.Leases)
-		c.Templates.Hosts = htmlSection.Key("hosts").MustString(c.Templates.Hosts)
-		c.Templates.Logs = htmlSection.Key("logs").MustString(c.Templates.Logs)
-		c.Templates.Help = htmlSection.Key("help").MustString(c.Templates.Help)
-		c.Templates.About = htmlSection.Key("about").MustString(c.Templates.About)
-		c.Templates.System = htmlSection.Key("system").MustString(c.Templates.System)
+		s := settings{c: c, section: htmlSection, prefix: "html.", origin: origin}
+		s.text("bootstrap", &c.Templates.Bootstrap)
+		s.text("leases", &c.Templates.Leases)
+		s.text("hosts", &c.Templates.Hosts)
+		s.text("devices", &c.Templates.Devices)
+		s.text("ipam", &c.Templates.IPAM)
+		s.text("logs", &c.Templates.Logs)
+		s.text("help", &c.Templates.Help)
+		s.text("about", &c.Templates.About)
+		s.text("system", &c.Templates.System)
 	}
-
-	return nil
 }
 
 // LoadFromEnv loads configuration from environment variables
 func (c *Config) LoadFromEnv() {
-	if v := os.Getenv("LEASESFILE"); v != "" {
+	if v := c.getenv("LEASESFILE"); v != "" {
 		c.LeasesFile = v
 	}
-	if v := os.Getenv("HTMLDIR"); v != "" {
+	if v := c.getenv("HTMLDIR"); v != "" {
 		c.HTMLDir = v
 	}
-	if v := os.Getenv("HTTPLISTEN"); v != "" {
+	if v := c.getenv("HTTPLISTEN"); v != "" {
 		c.HTTPListen = v
 	}
-	if v := os.Getenv("DNSMASQ"); v != "" {
+	if v := c.getenv("APITOKEN"); v != "" {
+		c.APIToken = v
+	}
+	if v := c.getenv("LOGLEVEL"); v != "" {
+		c.LogLevel = v
+	}
+	if v := c.getenv("SYSLOGLISTEN"); v != "" {
+		c.SyslogListen = v
+	}
+	if v := c.getenv("SYSLOGPROTOCOL"); v != "" {
+		c.SyslogProtocol = v
+	}
+	if v, ok := c.lookupEnv("SYSLOGPROGRAMS"); ok {
+		c.SyslogPrograms = splitList(v)
+	}
+	if v := c.getenv("DNSMASQ"); v != "" {
 		c.DNSMasq = v
 	}
-	if v := os.Getenv("SYSTEMD"); v != "" {
+	if v := c.getenv("DHCPRELEASE"); v != "" {
+		c.DHCPRelease = v
+	}
+	if v := c.getenv("DHCPRELEASE6"); v != "" {
+		c.DHCPRelease6 = v
+	}
+	if v := c.getenv("SYSTEMD"); v != "" {
 		c.SystemD, _ = strconv.ParseBool(v)
 	}
-	if v := os.Getenv("MACDBFILE"); v != "" {
-		c.MACDBFile = v
+	if v := c.getenv("DNSMASQARGS"); v != "" {
+		c.DNSMasqArgs = v
+	}
+	if v := os.Getenv("RESTARTPOLICY"); v != "" {
+		if utils.ContainsString(restartPolicies, v) {
+			c.RestartPolicy = v
+			c.useEnv("RESTARTPOLICY", v)
+		} else {
+			utils.Errorf("Error: invalid RESTARTPOLICY %q (use always, on-failure or never); keeping %s", v, c.RestartPolicy)
+		}
+	}
+	if v := os.Getenv("RESTARTBACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.RestartBackoff = d
+			c.useEnv("RESTARTBACKOFF", v)
+		}
+	}
+	if v := os.Getenv("RESTARTMAXBACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.RestartMaxBackoff = d
+			c.useEnv("RESTARTMAXBACKOFF", v)
+		}
+	}
+	if v := c.getenv("SYSTEMCTL"); v != "" {
+		c.Systemctl = v
 	}
-	if v := os.Getenv("MACDBPRELOAD"); v != "" {
+	if v := c.getenv("SYSTEMDUNIT"); v != "" {
+		c.SystemdUnit = v
+	}
+	if v := c.getenv("JOURNALCTL"); v != "" {
+		c.Journalctl = v
+	}
+	if v := c.getenv("MACDBFILE"); v != "" {
+		c.MACDBFiles = splitList(v)
+	}
+	if v := c.getenv("MACDBPRELOAD"); v != "" {
 		c.MACDBPreload, _ = strconv.ParseBool(v)
 	}
-	if v := os.Getenv("NMAP"); v != "" {
+	if v := c.getenv("NMAP"); v != "" {
 		c.Nmap = v
 	}
-	if v := os.Getenv("NMAPOPTS"); v != "" {
+	if v := c.getenv("NMAPOPTS"); v != "" {
 		c.NmapOpts = v
 	}
-	if v := os.Getenv("HOSTSFILE"); v != "" {
+	if v := c.getenv("HOSTSFILE"); v != "" {
 		c.HostsFile = v
 	}
-	if v := os.Getenv("HTTPLINKS"); v != "" {
+	if v := c.getenv("HTTPLINKS"); v != "" {
 		c.HTTPLinks, _ = strconv.ParseBool(v)
 	}
-	if v := os.Getenv("HTTPSLINKS"); v != "" {
+	if v := c.getenv("HTTPSLINKS"); v != "" {
 		c.HTTPSLinks, _ = strconv.ParseBool(v)
 	}
-	if v := os.Getenv("SSHLINKS"); v != "" {
+	if v := c.getenv("SSHLINKS"); v != "" {
 		c.SSHLinks, _ = strconv.ParseBool(v)
 	}
-	if v := os.Getenv("STATICFILE"); v != "" {
+	if v := c.getenv("STATICFILE"); v != "" {
 		c.StaticFile = v
 	}
-	if v := os.Getenv("NETWORKTAGS"); v != "" {
+	if v, ok := c.lookupEnv("DNSMASQCONF"); ok {
+		c.DNSMasqConf = splitList(v)
+	}
+	if v := c.getenv("NETWORKTAGS"); v != "" {
 		c.NetworkTags, _ = strconv.ParseBool(v)
 	}
-	if v := os.Getenv("EDIT"); v != "" {
+	if v := c.getenv("EDIT"); v != "" {
 		c.Edit, _ = strconv.ParseBool(v)
 	}
+	if v, ok := c.lookupEnv("DEVICESFILE"); ok {
+		c.DevicesFile = v
+	}
+	if v, ok := c.lookupEnv("LABELSFILE"); ok {
+		c.LabelsFile = v
+	}
+	if v, ok := c.lookupEnv("APPROVALSFILE"); ok {
+		c.ApprovalsFile = v
+	}
+	if v := c.getenv("QUARANTINETAG"); v != "" {
+		c.QuarantineTag = v
+	}
+	if v, ok := c.lookupEnv("LOGDIR"); ok {
+		c.LogDir = v
+	}
+	if v := os.Getenv("LOGMAXSIZE"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			c.LogMaxSize = n * megabyte
+			c.useEnv("LOGMAXSIZE", v)
+		}
+	}
+	if v := os.Getenv("LOGSEGMENTSIZE"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			c.LogSegmentSize = n * megabyte
+			c.useEnv("LOGSEGMENTSIZE", v)
+		}
+	}
+	if v := os.Getenv("LOGMAXAGE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.LogMaxAge = d
+			c.useEnv("LOGMAXAGE", v)
+		}
+	}
+	if v := os.Getenv("POOLWARN"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.PoolWarn = f
+			c.useEnv("POOLWARN", v)
+		}
+	}
+	if v := os.Getenv("POOLCRIT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			c.PoolCrit = f
+			c.useEnv("POOLCRIT", v)
+		}
+	}
 	
 	// HTML template environment variables
-	if v := os.Getenv("HTML_BOOTSTRAP"); v != "" {
+	if v := c.getenv("HTML_BOOTSTRAP"); v != "" {
 		c.Templates.Bootstrap = v
 	}
-	if v := os.Getenv("HTML_LEASES"); v != "" {
+	if v := c.getenv("HTML_LEASES"); v != "" {
 		c.Templates.Leases = v
 	}
-	if v := os.Getenv("HTML_HOSTS"); v != "" {
+	if v := c.getenv("HTML_HOSTS"); v != "" {
 		c.Templates.Hosts = v
 	}
-	if v := os.Getenv("HTML_LOGS"); v != "" {
+	if v := c.getenv("HTML_DEVICES"); v != "" {
+		c.Templates.Devices = v
+	}
+	if v := c.getenv("HTML_IPAM"); v != "" {
+		c.Templates.IPAM = v
+	}
+	if v := c.getenv("HTML_LOGS"); v != "" {
 		c.Templates.Logs = v
 	}
-	if v := os.Getenv("HTML_HELP"); v != "" {
+	if v := c.getenv("HTML_HELP"); v != "" {
 		c.Templates.Help = v
 	}
-	if v := os.Getenv("HTML_ABOUT"); v != "" {
+	if v := c.getenv("HTML_ABOUT"); v != "" {
 		c.Templates.About = v
 	}
-	if v := os.Getenv("HTML_SYSTEM"); v != "" {
+	if v := c.getenv("HTML_SYSTEM"); v != "" {
 		c.Templates.System = v
 	}
 }
 
-// New creates a new configuration instance
+// restartPolicies are the values of restartpolicy
+var restartPolicies = []string{"always", "on-failure", "never"}
+
+// splitList splits a comma-separated setting, dropping empty items
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// New creates a new configuration instance; a missing or unreadable
+// configuration file is skipped
 func New(configFile string) (*Config, error) {
+	return Load(configFile, false, nil)
+}
+
+// Load creates a configuration from defaults, the configuration file,
+// environment variables and command-line flags, each overriding the
+// ones before. Flags are given by INI key. When required is set, the
+// configuration file must be readable.
+func Load(configFile string, required bool, flags map[string]string) (*Config, error) {
 	cfg := DefaultConfig()
 	
 	// Load from file first
-	cfg.LoadFromFile(configFile)
+	if err := cfg.LoadFromFile(configFile); err != nil && required {
+		return nil, fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
 	
 	// Override with environment variables
 	cfg.LoadFromEnv()
 	
+	// Override with command-line flags
+	if err := cfg.LoadFromFlags(flags); err != nil {
+		return nil, err
+	}
+	
 	return cfg, nil
 }
 
@@ -215,6 +471,8 @@ func (c *Config) GetTemplateMap() map[string]string {
 		"bootstrap": c.Templates.Bootstrap,
 		"leases":    c.Templates.Leases,
 		"hosts":     c.Templates.Hosts,
+		"devices":   c.Templates.Devices,
+		"ipam":      c.Templates.IPAM,
 		"logs":      c.Templates.Logs,
 		"help":      c.Templates.Help,
 		"about":     c.Templates.About,
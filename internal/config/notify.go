@@ -0,0 +1,89 @@
+// ===== internal/config/notify.go =====
+package config
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// notifySectionPrefix starts the name of each notification channel section
+const notifySectionPrefix = "notify."
+
+// NotifyChannel is a notification channel, configured in a
+// [notify.<name>] section
+type NotifyChannel struct {
+	Name       string
+	Type       string   // webhook, ntfy, gotify, smtp or mqtt
+	URL        string   // Endpoint; smtp://, smtps://, mqtt:// or mqtts:// for mail and MQTT
+	Events     []string // Event types sent; empty sends all
+	MinLevel   string   // info, warning or critical
+	RateLimit  int      // Messages per RatePeriod; 0 is unlimited
+	RatePeriod time.Duration
+	Timeout    time.Duration
+
+	// Message templates (text/template)
+	Title   string
+	Message string
+
+	// Type-specific settings
+	Headers  []string // Extra HTTP headers, "Name: value"
+	Token    string   // ntfy or Gotify access token
+	Username string   // SMTP or MQTT credentials
+	Password string
+	From     string   // SMTP sender
+	To       []string // SMTP recipients
+	Topic    string   // MQTT topic
+	ClientID string   // MQTT client ID
+	Retain   bool     // MQTT retained messages
+}
+
+// defaultNotifyChannel returns a channel with default values
+func defaultNotifyChannel(name string) NotifyChannel {
+	return NotifyChannel{
+		Name:       name,
+		Type:       "webhook",
+		MinLevel:   "info",
+		RateLimit:  20,
+		RatePeriod: time.Hour,
+		Timeout:    10 * time.Second,
+		Title:      "{{.Title}}",
+		Message:    "{{.Message}}",
+		Topic:      "dhcpmon/events",
+		ClientID:   "dhcpmon",
+	}
+}
+
+// loadNotifyChannels reads the [notify.<name>] sections
+func loadNotifyChannels(cfg *ini.File) []NotifyChannel {
+	var channels []NotifyChannel
+	for _, section := range cfg.Sections() {
+		name := section.Name()
+		if !strings.HasPrefix(name, notifySectionPrefix) {
+			continue
+		}
+
+		n := defaultNotifyChannel(strings.TrimPrefix(name, notifySectionPrefix))
+		n.Type = section.Key("type").MustString(n.Type)
+		n.URL = section.Key("url").String()
+		n.Events = splitList(section.Key("events").String())
+		n.MinLevel = section.Key("level").In(n.MinLevel, []string{"info", "warning", "critical"})
+		n.RateLimit = section.Key("ratelimit").MustInt(n.RateLimit)
+		n.RatePeriod = section.Key("rateperiod").MustDuration(n.RatePeriod)
+		n.Timeout = section.Key("timeout").MustDuration(n.Timeout)
+		n.Title = section.Key("title").MustString(n.Title)
+		n.Message = section.Key("message").MustString(n.Message)
+		n.Headers = splitList(section.Key("headers").String())
+		n.Token = section.Key("token").String()
+		n.Username = section.Key("username").String()
+		n.Password = section.Key("password").String()
+		n.From = section.Key("from").String()
+		n.To = splitList(section.Key("to").String())
+		n.Topic = section.Key("topic").MustString(n.Topic)
+		n.ClientID = section.Key("clientid").MustString(n.ClientID)
+		n.Retain = section.Key("retain").MustBool(n.Retain)
+		channels = append(channels, n)
+	}
+	return channels
+}
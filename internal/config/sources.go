@@ -0,0 +1,226 @@
+// ===== internal/config/sources.go =====
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"dhcpmon/pkg/utils"
+)
+
+// Origins of a setting, from lowest to highest precedence
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Source records where the effective value of a setting came from
+type Source struct {
+	Origin string `json:"origin"`
+	Value  string `json:"value"` // As given, before parsing
+}
+
+// Source returns where a setting, named by its INI key, came from
+func (c *Config) Source(key string) Source {
+	if s, ok := c.Sources[key]; ok {
+		return s
+	}
+	return Source{Origin: SourceDefault}
+}
+
+// SourceKeys returns the keys of the settings not left at their defaults,
+// sorted
+func (c *Config) SourceKeys() []string {
+	keys := make([]string, 0, len(c.Sources))
+	for key := range c.Sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LoadFromFlags sets the settings given on the command line, keyed as in
+// the INI file
+func (c *Config) LoadFromFlags(flags map[string]string) error {
+	if len(flags) == 0 {
+		return nil
+	}
+	cfg := ini.Empty(ini.LoadOptions{Insensitive: true})
+	for key, value := range flags {
+		section, name := "", key
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			section, name = key[:i], key[i+1:]
+		}
+		if _, err := cfg.Section(section).NewKey(name, value); err != nil {
+			return fmt.Errorf("invalid setting %s: %w", key, err)
+		}
+	}
+	c.apply(cfg, SourceFlag)
+	return nil
+}
+
+// setSource records the origin of a setting
+func (c *Config) setSource(key, origin, value string) {
+	if c.Sources == nil {
+		c.Sources = make(map[string]Source)
+	}
+	c.Sources[key] = Source{Origin: origin, Value: value}
+}
+
+// getenv returns an environment variable, recording it as the source of
+// its setting when set and not empty
+func (c *Config) getenv(name string) string {
+	v := os.Getenv(name)
+	if v != "" {
+		c.setSource(envKey(name), SourceEnv, v)
+	}
+	return v
+}
+
+// useEnv records an environment variable as the source of its setting,
+// for values applied only once they parse
+func (c *Config) useEnv(name, value string) {
+	c.setSource(envKey(name), SourceEnv, value)
+}
+
+// lookupEnv returns an environment variable, recording it as the source
+// of its setting when set, even to an empty value
+func (c *Config) lookupEnv(name string) (string, bool) {
+	v, ok := os.LookupEnv(name)
+	if ok {
+		c.setSource(envKey(name), SourceEnv, v)
+	}
+	return v, ok
+}
+
+// envKey returns the INI key of an environment variable; HTML_LEASES
+// is leases in the html section
+func envKey(name string) string {
+	name = strings.ToLower(name)
+	if rest, ok := strings.CutPrefix(name, "html_"); ok {
+		return "html." + rest
+	}
+	return name
+}
+
+// settings applies the keys of an INI section to a Config, recording
+// origin as the source of each value applied. A value that does not
+// parse is reported and leaves its setting as it was.
+type settings struct {
+	c       *Config
+	section *ini.Section
+	prefix  string // Of the section's keys in Sources: "" or "html."
+	origin  string
+}
+
+// value returns the value of a key given in the section
+func (s settings) value(name string) (string, bool) {
+	if !s.section.HasKey(name) {
+		return "", false
+	}
+	return s.section.Key(name).String(), true
+}
+
+// applied records the source of a value that was applied
+func (s settings) applied(name, value string) {
+	s.c.setSource(s.prefix+name, s.origin, value)
+}
+
+// invalid reports a value that was not applied
+func (s settings) invalid(name, value string, err error) {
+	utils.Errorf("Error: invalid %s%s %q (%s): %v; keeping the previous value", s.prefix, name, value, s.origin, err)
+}
+
+// text sets a string; an empty value leaves it as it was
+func (s settings) text(name string, dst *string) {
+	if v, ok := s.value(name); ok && v != "" {
+		*dst = v
+		s.applied(name, v)
+	}
+}
+
+// textOrEmpty sets a string, even to an empty value
+func (s settings) textOrEmpty(name string, dst *string) {
+	if v, ok := s.value(name); ok {
+		*dst = v
+		s.applied(name, v)
+	}
+}
+
+// list sets a comma-separated list, even to an empty one
+func (s settings) list(name string, dst *[]string) {
+	if v, ok := s.value(name); ok {
+		*dst = splitList(v)
+		s.applied(name, v)
+	}
+}
+
+// oneOf sets a string that must be one of allowed
+func (s settings) oneOf(name string, dst *string, allowed []string) {
+	v, ok := s.value(name)
+	if !ok || v == "" {
+		return
+	}
+	if !utils.ContainsString(allowed, v) {
+		s.invalid(name, v, fmt.Errorf("use one of %s", strings.Join(allowed, ", ")))
+		return
+	}
+	*dst = v
+	s.applied(name, v)
+}
+
+// boolean sets a boolean
+func (s settings) boolean(name string, dst *bool) {
+	if v, ok := s.value(name); ok && v != "" {
+		if b, err := s.section.Key(name).Bool(); err != nil {
+			s.invalid(name, v, err)
+		} else {
+			*dst = b
+			s.applied(name, v)
+		}
+	}
+}
+
+// duration sets a duration such as 90s or 2h
+func (s settings) duration(name string, dst *time.Duration) {
+	if v, ok := s.value(name); ok && v != "" {
+		if d, err := time.ParseDuration(v); err != nil {
+			s.invalid(name, v, err)
+		} else {
+			*dst = d
+			s.applied(name, v)
+		}
+	}
+}
+
+// float sets a number
+func (s settings) float(name string, dst *float64) {
+	if v, ok := s.value(name); ok && v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err != nil {
+			s.invalid(name, v, err)
+		} else {
+			*dst = f
+			s.applied(name, v)
+		}
+	}
+}
+
+// megabytes sets a size given in megabytes, stored in bytes
+func (s settings) megabytes(name string, dst *int64) {
+	if v, ok := s.value(name); ok && v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil {
+			s.invalid(name, v, err)
+		} else {
+			*dst = n * megabyte
+			s.applied(name, v)
+		}
+	}
+}
@@ -0,0 +1,496 @@
+// ===== internal/consistency/checker.go =====
+package consistency
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"dhcpmon/internal/dnsmasq"
+	"dhcpmon/pkg/models"
+)
+
+// Issue severities
+const (
+	SeverityError   = "error"   // dnsmasq will hand out or resolve the wrong thing
+	SeverityWarning = "warning" // Probably a mistake, or will become one
+	SeverityInfo    = "info"    // Worth knowing, not necessarily wrong
+)
+
+// Issue types
+const (
+	TypeIPConflict        = "ip-conflict"
+	TypeHostnameCollision = "hostname-collision"
+	TypeLeaseMismatch     = "lease-mismatch"
+	TypeOutsideRange      = "outside-range"
+	TypeNoRanges          = "no-ranges"
+)
+
+// Issue is one inconsistency between the static reservations, the hosts
+// file and the live leases
+type Issue struct {
+	Severity string   `json:"severity"`
+	Type     string   `json:"type"`
+	Message  string   `json:"message"`
+	IP       string   `json:"ip,omitempty"`
+	MAC      string   `json:"mac,omitempty"`
+	Hostname string   `json:"hostname,omitempty"`
+	Sources  []string `json:"sources"` // e.g. "static:line_12", "hosts:line_3", "lease"
+}
+
+// Input is the state to check
+type Input struct {
+	Static     []models.StaticDHCPEntry
+	Hosts      []models.HostEntry
+	Leases     []models.DHCPLease
+	Ranges     []dnsmasq.DHCPRange
+	RangeError error // Set when the dnsmasq configuration could not be read
+}
+
+// Report is the result of a check
+type Report struct {
+	Issues    []Issue        `json:"issues"`
+	Counts    map[string]int `json:"counts"` // Issues per severity
+	Ranges    []string       `json:"ranges"`
+	CheckedAt time.Time      `json:"checkedAt"`
+}
+
+// Check cross-validates the static reservations, hosts file and current
+// leases. Disabled reservations and expired leases are ignored.
+func Check(in Input) Report {
+	c := &checker{}
+
+	var static []models.StaticDHCPEntry
+	for _, entry := range in.Static {
+		if entry.Enabled {
+			static = append(static, entry)
+		}
+	}
+	now := time.Now()
+	var leases []models.DHCPLease
+	for _, lease := range in.Leases {
+		// Reservations appear in the lease list too; they are checked
+		// from in.Static
+		if lease.Static {
+			continue
+		}
+		if lease.IP != nil && (lease.Expire.IsZero() || lease.Expire.After(now)) {
+			leases = append(leases, lease)
+		}
+	}
+
+	c.checkStaticIPs(static)
+	c.checkStaticHostnames(static)
+	c.checkStaticAgainstHosts(static, in.Hosts)
+	c.checkLeases(static, in.Hosts, leases)
+	c.checkRanges(static, in.Ranges, in.RangeError)
+
+	report := Report{
+		Issues:    c.issues,
+		Counts:    map[string]int{SeverityError: 0, SeverityWarning: 0, SeverityInfo: 0},
+		Ranges:    make([]string, 0, len(in.Ranges)),
+		CheckedAt: now,
+	}
+	if report.Issues == nil {
+		report.Issues = []Issue{}
+	}
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return severityRank(report.Issues[i].Severity) < severityRank(report.Issues[j].Severity)
+	})
+	for _, issue := range report.Issues {
+		report.Counts[issue.Severity]++
+	}
+	for _, r := range in.Ranges {
+		report.Ranges = append(report.Ranges, r.String())
+	}
+	return report
+}
+
+// checker collects issues
+type checker struct {
+	issues []Issue
+}
+
+func (c *checker) add(issue Issue) {
+	c.issues = append(c.issues, issue)
+}
+
+// checkStaticIPs reports one IP reserved for several MACs
+func (c *checker) checkStaticIPs(static []models.StaticDHCPEntry) {
+	byIP := make(map[string][]models.StaticDHCPEntry)
+	var order []string
+	for _, entry := range static {
+		if entry.IP == nil {
+			continue
+		}
+		ip := entry.IP.String()
+		if _, ok := byIP[ip]; !ok {
+			order = append(order, ip)
+		}
+		byIP[ip] = append(byIP[ip], entry)
+	}
+
+	for _, ip := range order {
+		entries := byIP[ip]
+		macs := make(map[string]bool)
+		var list, sources []string
+		for _, entry := range entries {
+			mac := entry.GetFormattedMAC()
+			if !macs[mac] {
+				macs[mac] = true
+				list = append(list, mac)
+			}
+			sources = append(sources, staticSource(entry))
+		}
+		if len(macs) < 2 {
+			continue
+		}
+		c.add(Issue{
+			Severity: SeverityError,
+			Type:     TypeIPConflict,
+			Message:  fmt.Sprintf("%s is reserved for %d devices: %s", ip, len(list), strings.Join(list, ", ")),
+			IP:       ip,
+			Sources:  sources,
+		})
+	}
+}
+
+// checkStaticHostnames reports one hostname reserved on several IPs
+func (c *checker) checkStaticHostnames(static []models.StaticDHCPEntry) {
+	byName := make(map[string][]models.StaticDHCPEntry)
+	var order []string
+	for _, entry := range static {
+		if entry.Hostname == "" || entry.IP == nil {
+			continue
+		}
+		name := strings.ToLower(entry.Hostname)
+		if _, ok := byName[name]; !ok {
+			order = append(order, name)
+		}
+		byName[name] = append(byName[name], entry)
+	}
+
+	for _, name := range order {
+		entries := byName[name]
+		ips := make(map[string]bool)
+		var list, sources []string
+		for _, entry := range entries {
+			ip := entry.IP.String()
+			if !ips[ip] {
+				ips[ip] = true
+				list = append(list, ip)
+			}
+			sources = append(sources, staticSource(entry))
+		}
+		if len(ips) < 2 {
+			continue
+		}
+		c.add(Issue{
+			Severity: SeverityWarning,
+			Type:     TypeHostnameCollision,
+			Message:  fmt.Sprintf("Hostname %s is reserved on %s", entries[0].Hostname, strings.Join(list, ", ")),
+			Hostname: entries[0].Hostname,
+			Sources:  sources,
+		})
+	}
+}
+
+// checkStaticAgainstHosts reports reservations whose IP the hosts file
+// gives another name, and reservation hostnames the hosts file resolves
+// to another address
+func (c *checker) checkStaticAgainstHosts(static []models.StaticDHCPEntry, hosts []models.HostEntry) {
+	for _, entry := range static {
+		if entry.IP == nil {
+			continue
+		}
+		ip := entry.IP.String()
+
+		for _, host := range hosts {
+			hostIP := net.ParseIP(host.IP)
+			if hostIP == nil {
+				continue
+			}
+
+			if hostIP.Equal(entry.IP) && entry.Hostname != "" && !hasName(host, entry.Hostname) {
+				c.add(Issue{
+					Severity: SeverityWarning,
+					Type:     TypeIPConflict,
+					Message: fmt.Sprintf("%s is reserved for %s (%s) but the hosts file names it %s",
+						ip, entry.Hostname, entry.GetFormattedMAC(), strings.Join(host.Names(), " ")),
+					IP:       ip,
+					MAC:      entry.GetFormattedMAC(),
+					Hostname: entry.Hostname,
+					Sources:  []string{staticSource(entry), hostsSource(host)},
+				})
+			}
+
+			if entry.Hostname != "" && hasName(host, entry.Hostname) && !hostIP.Equal(entry.IP) && sameFamily(hostIP, entry.IP) {
+				c.add(Issue{
+					Severity: SeverityError,
+					Type:     TypeHostnameCollision,
+					Message: fmt.Sprintf("%s is reserved on %s but the hosts file resolves it to %s",
+						entry.Hostname, ip, host.IP),
+					IP:       ip,
+					MAC:      entry.GetFormattedMAC(),
+					Hostname: entry.Hostname,
+					Sources:  []string{staticSource(entry), hostsSource(host)},
+				})
+			}
+		}
+	}
+}
+
+// checkLeases compares the live leases with the reservations and the
+// hosts file
+func (c *checker) checkLeases(static []models.StaticDHCPEntry, hosts []models.HostEntry, leases []models.DHCPLease) {
+	reservedIP := make(map[string]models.StaticDHCPEntry)
+	byMAC := make(map[string][]models.StaticDHCPEntry)
+	for _, entry := range static {
+		if entry.IP != nil {
+			reservedIP[entry.IP.String()] = entry
+		}
+		byMAC[entry.GetFormattedMAC()] = append(byMAC[entry.GetFormattedMAC()], entry)
+	}
+
+	for _, lease := range leases {
+		ip := lease.IP.String()
+		mac := ""
+		if lease.MAC != nil {
+			mac = strings.ToUpper(lease.MAC.String())
+		}
+		name := lease.Name
+		if name == "*" {
+			name = ""
+		}
+
+		// Someone else holds a reserved address
+		if entry, ok := reservedIP[ip]; ok && mac != "" && entry.GetFormattedMAC() != mac {
+			c.add(Issue{
+				Severity: SeverityError,
+				Type:     TypeIPConflict,
+				Message: fmt.Sprintf("%s is reserved for %s but is leased to %s%s",
+					ip, entry.GetDisplayName()+" ("+entry.GetFormattedMAC()+")", mac, nameSuffix(name)),
+				IP:       ip,
+				MAC:      mac,
+				Hostname: name,
+				Sources:  []string{staticSource(entry), "lease"},
+			})
+		}
+
+		// A reserved device holds an address other than its reservation
+		if entries, ok := byMAC[mac]; ok {
+			reserved := false
+			var ips []string
+			for _, entry := range entries {
+				if entry.IP == nil {
+					continue
+				}
+				ips = append(ips, entry.IP.String())
+				if entry.IP.Equal(lease.IP) || !sameFamily(entry.IP, lease.IP) {
+					reserved = true
+				}
+			}
+			if len(ips) > 0 && !reserved {
+				c.add(Issue{
+					Severity: SeverityWarning,
+					Type:     TypeLeaseMismatch,
+					Message: fmt.Sprintf("%s%s is reserved %s but currently leases %s",
+						mac, nameSuffix(name), strings.Join(ips, ", "), ip),
+					IP:       ip,
+					MAC:      mac,
+					Hostname: name,
+					Sources:  append(staticSources(entries), "lease"),
+				})
+			}
+		}
+
+		for _, host := range hosts {
+			hostIP := net.ParseIP(host.IP)
+			if hostIP == nil {
+				continue
+			}
+
+			// The hosts file names an address that is leased to a device
+			// calling itself something else
+			if hostIP.Equal(lease.IP) && name != "" && !hasName(host, name) {
+				if entry, ok := reservedIP[ip]; !ok || entry.GetFormattedMAC() != mac {
+					c.add(Issue{
+						Severity: SeverityWarning,
+						Type:     TypeIPConflict,
+						Message: fmt.Sprintf("The hosts file names %s %s but it is leased to %s%s",
+							ip, strings.Join(host.Names(), " "), mac, nameSuffix(name)),
+						IP:       ip,
+						MAC:      mac,
+						Hostname: name,
+						Sources:  []string{hostsSource(host), "lease"},
+					})
+				}
+			}
+
+			// The leased name resolves elsewhere through the hosts file
+			if name != "" && hasName(host, name) && !hostIP.Equal(lease.IP) && sameFamily(hostIP, lease.IP) {
+				c.add(Issue{
+					Severity: SeverityWarning,
+					Type:     TypeHostnameCollision,
+					Message: fmt.Sprintf("%s (%s) leases %s but the hosts file resolves %s to %s",
+						name, mac, ip, name, host.IP),
+					IP:       ip,
+					MAC:      mac,
+					Hostname: name,
+					Sources:  []string{hostsSource(host), "lease"},
+				})
+			}
+		}
+	}
+
+	// The same name leased by two devices
+	byName := make(map[string][]models.DHCPLease)
+	var order []string
+	for _, lease := range leases {
+		if lease.Name == "" || lease.Name == "*" {
+			continue
+		}
+		name := strings.ToLower(lease.Name)
+		if _, ok := byName[name]; !ok {
+			order = append(order, name)
+		}
+		byName[name] = append(byName[name], lease)
+	}
+	for _, name := range order {
+		list := byName[name]
+		macs := make(map[string]bool)
+		var described []string
+		for _, lease := range list {
+			if lease.MAC == nil || macs[lease.MAC.String()] {
+				continue
+			}
+			macs[lease.MAC.String()] = true
+			described = append(described, strings.ToUpper(lease.MAC.String())+" ("+lease.IP.String()+")")
+		}
+		if len(macs) < 2 {
+			continue
+		}
+		c.add(Issue{
+			Severity: SeverityInfo,
+			Type:     TypeHostnameCollision,
+			Message:  fmt.Sprintf("%d devices lease the name %s: %s", len(macs), list[0].Name, strings.Join(described, ", ")),
+			Hostname: list[0].Name,
+			Sources:  []string{"lease"},
+		})
+	}
+}
+
+// checkRanges reports reservations that no dhcp-range covers. dnsmasq
+// only serves a reserved address on a network it has a range for, but
+// the network of a range without a netmask comes from the interface, so
+// an address outside the range itself may still be served.
+func (c *checker) checkRanges(static []models.StaticDHCPEntry, ranges []dnsmasq.DHCPRange, rangeErr error) {
+	if rangeErr != nil {
+		c.add(Issue{
+			Severity: SeverityWarning,
+			Type:     TypeNoRanges,
+			Message:  fmt.Sprintf("Could not read the dnsmasq configuration: %v", rangeErr),
+			Sources:  []string{"dnsmasq"},
+		})
+		return
+	}
+	if len(ranges) == 0 {
+		c.add(Issue{
+			Severity: SeverityInfo,
+			Type:     TypeNoRanges,
+			Message:  "No dhcp-range found in the dnsmasq configuration; reservations were not checked against ranges",
+			Sources:  []string{"dnsmasq"},
+		})
+		return
+	}
+
+	for _, entry := range static {
+		if entry.IP == nil {
+			continue
+		}
+
+		covered := false
+		for _, r := range ranges {
+			if r.Contains(entry.IP) {
+				covered = true
+				break
+			}
+			if subnet := r.Subnet(); subnet != nil && subnet.Contains(entry.IP) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+
+		c.add(Issue{
+			Severity: SeverityWarning,
+			Type:     TypeOutsideRange,
+			Message: fmt.Sprintf("%s reserved for %s is outside every dhcp-range",
+				entry.IP.String(), entry.GetDisplayName()+" ("+entry.GetFormattedMAC()+")"),
+			IP:       entry.IP.String(),
+			MAC:      entry.GetFormattedMAC(),
+			Hostname: entry.Hostname,
+			Sources:  []string{staticSource(entry)},
+		})
+	}
+}
+
+// hasName reports whether a hosts entry carries name, ignoring case.
+// DHCP clients send bare names that dnsmasq qualifies with its domain,
+// so "nas" and "nas.lan" are the same name; two qualified names must
+// match in full, so "nas.lan" is not "nas.other".
+func hasName(host models.HostEntry, name string) bool {
+	short, _, qualified := strings.Cut(name, ".")
+	for _, n := range host.Names() {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+		hostShort, _, hostQualified := strings.Cut(n, ".")
+		if (!qualified || !hostQualified) && strings.EqualFold(hostShort, short) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameFamily reports whether both addresses are IPv4 or both IPv6
+func sameFamily(a, b net.IP) bool {
+	return (a.To4() == nil) == (b.To4() == nil)
+}
+
+func nameSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " (" + name + ")"
+}
+
+func staticSource(entry models.StaticDHCPEntry) string {
+	return "static:" + entry.ID
+}
+
+func staticSources(entries []models.StaticDHCPEntry) []string {
+	sources := make([]string, len(entries))
+	for i, entry := range entries {
+		sources[i] = staticSource(entry)
+	}
+	return sources
+}
+
+func hostsSource(host models.HostEntry) string {
+	return "hosts:" + host.ID
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,120 @@
+// ===== internal/devices/fingerprint.go =====
+package devices
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"dhcpmon/pkg/models"
+)
+
+const (
+	// transactionTTL is how long the details of a DHCP transaction are
+	// kept while waiting for the line that names the client MAC
+	transactionTTL = time.Minute
+
+	// maxTransactions bounds the number of transactions tracked at once
+	maxTransactions = 1024
+)
+
+// xidLineRe matches the detail lines dnsmasq writes with log-dhcp, which
+// carry the DHCP transaction ID after the optional program prefix:
+//
+//	dnsmasq-dhcp[812]: 2915139810 requested options: 1:netmask, 3:router, ...
+var xidLineRe = regexp.MustCompile(`^(?:.*?dnsmasq-dhcp(?:\[\d+\])?: )?(\d+) (.+)$`)
+
+// optionCodeRe matches the option numbers of a "requested options" list
+var optionCodeRe = regexp.MustCompile(`(?:^|,\s*)(\d+):`)
+
+// transaction collects the log-dhcp lines of one DHCP exchange
+type transaction struct {
+	mac         string
+	options     []string
+	vendorClass string
+	hostname    string
+	lastOptions bool // Previous line was part of the options list
+	seen        time.Time
+}
+
+// ObserveLog extracts the client MAC, option 55 fingerprint, vendor
+// class and hostname from dnsmasq log-dhcp lines. The lines of one
+// exchange share a transaction ID; the fingerprint is recorded once the
+// line naming the MAC has been seen.
+func (t *Tracker) ObserveLog(entry models.LogEntry) {
+	match := xidLineRe.FindStringSubmatch(entry.Message)
+	if match == nil {
+		return
+	}
+	xid, detail := match[1], match[2]
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tx, ok := t.xids[xid]
+	if !ok {
+		t.expireTransactions(entry.Timestamp)
+		tx = &transaction{}
+		t.xids[xid] = tx
+	}
+	tx.seen = entry.Timestamp
+
+	isOptions := false
+	switch {
+	case strings.HasPrefix(detail, "requested options:"):
+		// Long lists are split over several consecutive lines
+		if !tx.lastOptions {
+			tx.options = nil
+		}
+		for _, code := range optionCodeRe.FindAllStringSubmatch(strings.TrimSpace(strings.TrimPrefix(detail, "requested options:")), -1) {
+			tx.options = append(tx.options, code[1])
+		}
+		isOptions = true
+	case strings.HasPrefix(detail, "vendor class:"):
+		tx.vendorClass = strings.TrimSpace(strings.TrimPrefix(detail, "vendor class:"))
+	case strings.HasPrefix(detail, "client provides name:"):
+		tx.hostname = strings.TrimSpace(strings.TrimPrefix(detail, "client provides name:"))
+	case entry.Event != "" && entry.MAC != "":
+		tx.mac = entry.MAC
+	}
+	tx.lastOptions = isOptions
+
+	if tx.mac == "" {
+		return
+	}
+
+	s := t.sighting(tx.mac, entry.Timestamp)
+	if len(tx.options) > 0 {
+		s.Fingerprint = strings.Join(tx.options, ",")
+	}
+	if tx.vendorClass != "" {
+		s.VendorClass = tx.vendorClass
+	}
+	if tx.hostname != "" {
+		s.Hostname = tx.hostname
+	}
+}
+
+// expireTransactions forgets transactions that have gone quiet; the
+// caller holds t.mu
+func (t *Tracker) expireTransactions(now time.Time) {
+	if len(t.xids) < maxTransactions/2 {
+		return
+	}
+
+	var oldestXID string
+	var oldest time.Time
+	for xid, tx := range t.xids {
+		if now.Sub(tx.seen) > transactionTTL {
+			delete(t.xids, xid)
+			continue
+		}
+		if oldestXID == "" || tx.seen.Before(oldest) {
+			oldestXID, oldest = xid, tx.seen
+		}
+	}
+
+	if len(t.xids) >= maxTransactions {
+		delete(t.xids, oldestXID)
+	}
+}
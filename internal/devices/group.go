@@ -0,0 +1,220 @@
+// ===== internal/devices/group.go =====
+package devices
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"dhcpmon/pkg/utils"
+)
+
+// Device is a physical device that may have used several MAC addresses
+type Device struct {
+	ID          string    `json:"id"` // First MAC the device was seen with
+	Name        string    `json:"name"`
+	Vendor      string    `json:"vendor,omitempty"`
+	Private     bool      `json:"private"`
+	ClientID    string    `json:"clientId,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	VendorClass string    `json:"vendorClass,omitempty"`
+	IP          string    `json:"ip,omitempty"`
+	Active      bool      `json:"active"`
+	MatchedBy   []string  `json:"matchedBy,omitempty"` // Signals that joined the MACs
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+	MACs        []Alias   `json:"macs"`
+}
+
+// Alias is one MAC address used by a device
+type Alias struct {
+	MAC       string    `json:"mac"`
+	IP        string    `json:"ip,omitempty"`
+	Hostname  string    `json:"hostname,omitempty"`
+	Active    bool      `json:"active"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// genericHostnames are default names shared by many devices, which say
+// nothing about which device is which
+var genericHostnames = map[string]bool{
+	"localhost": true,
+	"android":   true,
+	"iphone":    true,
+	"ipad":      true,
+	"unknown":   true,
+	"espressif": true,
+}
+
+// Devices groups the remembered sightings into devices. Private
+// (randomized) MACs are joined when they share a stable DHCP client ID,
+// or the same hostname with the same option 55 fingerprint. Globally
+// unique MACs are devices of their own.
+func (t *Tracker) Devices() []Device {
+	t.mu.Lock()
+	sightings := make([]Sighting, 0, len(t.sightings))
+	for _, s := range t.sightings {
+		sightings = append(sightings, *s)
+	}
+	active := t.active
+	t.mu.Unlock()
+
+	// Oldest first so that a device keeps the ID of its first MAC
+	sort.Slice(sightings, func(i, j int) bool {
+		if !sightings[i].FirstSeen.Equal(sightings[j].FirstSeen) {
+			return sightings[i].FirstSeen.Before(sightings[j].FirstSeen)
+		}
+		return sightings[i].MAC < sightings[j].MAC
+	})
+
+	parent := make([]int, len(sightings))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	matched := make(map[int]map[string]bool)
+	owners := make(map[string]int)
+	for i, s := range sightings {
+		if !s.Private {
+			continue
+		}
+		for _, key := range correlationKeys(s) {
+			if j, ok := owners[key]; ok {
+				a, b := find(i), find(j)
+				if a != b {
+					if b < a {
+						a, b = b, a
+					}
+					parent[b] = a
+				}
+				signal := key[:strings.IndexByte(key, ':')]
+				for _, k := range []int{i, j} {
+					if matched[k] == nil {
+						matched[k] = make(map[string]bool)
+					}
+					matched[k][signal] = true
+				}
+				continue
+			}
+			owners[key] = i
+		}
+	}
+
+	groups := make(map[int]*Device)
+	var order []int
+	for i, s := range sightings {
+		root := find(i)
+		device, ok := groups[root]
+		if !ok {
+			device = &Device{
+				ID:        s.MAC,
+				Private:   s.Private,
+				FirstSeen: s.FirstSeen,
+			}
+			groups[root] = device
+			order = append(order, root)
+		}
+
+		alias := Alias{
+			MAC:       s.MAC,
+			IP:        s.IP,
+			Hostname:  s.Hostname,
+			Active:    active[s.MAC],
+			FirstSeen: s.FirstSeen,
+			LastSeen:  s.LastSeen,
+		}
+		device.MACs = append(device.MACs, alias)
+
+		// The most recently seen MAC describes the device
+		if !s.LastSeen.Before(device.LastSeen) {
+			device.LastSeen = s.LastSeen
+			if s.IP != "" {
+				device.IP = s.IP
+			}
+			if s.Hostname != "" {
+				device.Name = s.Hostname
+			}
+			if s.Vendor != "" {
+				device.Vendor = s.Vendor
+			}
+			if s.ClientID != "" {
+				device.ClientID = s.ClientID
+			}
+			if s.Fingerprint != "" {
+				device.Fingerprint = s.Fingerprint
+			}
+			if s.VendorClass != "" {
+				device.VendorClass = s.VendorClass
+			}
+		}
+		if alias.Active {
+			device.Active = true
+		}
+		for signal := range matched[i] {
+			if !utils.ContainsString(device.MatchedBy, signal) {
+				device.MatchedBy = append(device.MatchedBy, signal)
+			}
+		}
+	}
+
+	devices := make([]Device, 0, len(order))
+	for _, root := range order {
+		device := groups[root]
+		if device.Name == "" {
+			device.Name = device.ID
+		}
+		sort.Slice(device.MACs, func(i, j int) bool {
+			return device.MACs[i].LastSeen.After(device.MACs[j].LastSeen)
+		})
+		sort.Strings(device.MatchedBy)
+		devices = append(devices, *device)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].LastSeen.After(devices[j].LastSeen)
+	})
+	return devices
+}
+
+// correlationKeys returns the signals that identify the device behind a
+// private MAC, each prefixed with the signal name
+func correlationKeys(s Sighting) []string {
+	var keys []string
+
+	if id := stableClientID(s.ClientID, s.MAC); id != "" {
+		keys = append(keys, "client-id:"+id)
+	}
+
+	name := strings.ToLower(strings.TrimSpace(s.Hostname))
+	if name != "" && !genericHostnames[name] {
+		// Without a fingerprint the hostname is only compared with other
+		// sightings that have none either
+		keys = append(keys, "hostname:"+name+"|"+s.Fingerprint)
+	}
+
+	return keys
+}
+
+// stableClientID returns the client ID in hex without separators, or ""
+// when it is absent or merely repeats the MAC (type 1 client IDs and
+// DUID-LL), which changes whenever the MAC rotates
+func stableClientID(clientID, mac string) string {
+	if clientID == "" || clientID == "*" {
+		return ""
+	}
+
+	id := strings.ToLower(strings.NewReplacer(":", "", "-", "").Replace(clientID))
+	macHex := strings.ToLower(strings.ReplaceAll(mac, ":", ""))
+	if strings.HasSuffix(id, macHex) {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,284 @@
+// ===== internal/devices/tracker.go =====
+package devices
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+const (
+	// saveInterval is how often changed sightings are written to disk
+	saveInterval = 30 * time.Second
+
+	// sightingRetention is how long a MAC that is no longer seen is
+	// remembered; phones rotate private MACs within days to weeks
+	sightingRetention = 90 * 24 * time.Hour
+)
+
+// Sighting is what has been observed about one MAC address
+type Sighting struct {
+	MAC         string    `json:"mac"`
+	IP          string    `json:"ip,omitempty"`
+	Hostname    string    `json:"hostname,omitempty"`
+	ClientID    string    `json:"clientId,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"` // DHCP option 55 list
+	VendorClass string    `json:"vendorClass,omitempty"` // DHCP option 60
+	Vendor      string    `json:"vendor,omitempty"`
+	Private     bool      `json:"private"`
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+// Tracker keeps a history of MAC sightings from leases and DHCP logs and
+// groups the MACs that belong to the same physical device
+type Tracker struct {
+	file      string
+	sightings map[string]*Sighting
+	active    map[string]bool // MACs with a current lease
+	xids      map[string]*transaction
+	fresh     map[string]bool // MACs first seen and not yet reported as new
+	seeded    bool            // History existed, or leases were seen once
+	onNew     func(Sighting)
+	dirty     bool
+	started   bool
+	mu        sync.Mutex
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+}
+
+// NewTracker creates a tracker persisted to file; an empty file keeps
+// the history in memory only
+func NewTracker(file string) *Tracker {
+	t := &Tracker{
+		file:      file,
+		sightings: make(map[string]*Sighting),
+		active:    make(map[string]bool),
+		xids:      make(map[string]*transaction),
+		fresh:     make(map[string]bool),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+	}
+
+	if file != "" {
+		if err := t.load(); err != nil && !os.IsNotExist(err) {
+			utils.Warnf("Warning: failed to load device history: %v", err)
+		}
+	}
+	t.seeded = len(t.sightings) > 0
+	return t
+}
+
+// OnNewDevice registers fn to be called with the sighting of each MAC
+// seen for the first time, once it holds a lease. The MACs of the first
+// leases seen with an empty history are not reported, so that a fresh
+// install does not announce every device on the network.
+func (t *Tracker) OnNewDevice(fn func(Sighting)) {
+	t.mu.Lock()
+	t.onNew = fn
+	t.mu.Unlock()
+}
+
+// Start begins periodically saving the sighting history
+func (t *Tracker) Start() {
+	t.mu.Lock()
+	t.started = true
+	t.mu.Unlock()
+	go t.saveLoop()
+}
+
+// Stop saves the sighting history and stops the save loop
+func (t *Tracker) Stop() {
+	t.mu.Lock()
+	started := t.started
+	t.mu.Unlock()
+
+	if !started {
+		t.saveIfDirty()
+		return
+	}
+	close(t.stopCh)
+	<-t.doneCh
+}
+
+// saveLoop writes changed sightings every saveInterval and on Stop
+func (t *Tracker) saveLoop() {
+	defer close(t.doneCh)
+
+	ticker := time.NewTicker(saveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.saveIfDirty()
+		case <-t.stopCh:
+			t.saveIfDirty()
+			return
+		}
+	}
+}
+
+// ObserveLeases records the current leases and marks which MACs are
+// active
+func (t *Tracker) ObserveLeases(leases []models.DHCPLease) {
+	now := time.Now()
+
+	t.mu.Lock()
+	t.active = make(map[string]bool, len(leases))
+	for _, lease := range leases {
+		if lease.MAC == nil {
+			continue
+		}
+		mac := strings.ToUpper(lease.MAC.String())
+		t.active[mac] = true
+
+		s := t.sighting(mac, now)
+		if lease.IP != nil {
+			s.IP = lease.IP.String()
+		}
+		if lease.Name != "" && lease.Name != "*" {
+			s.Hostname = lease.Name
+		}
+		if lease.ID != "" && lease.ID != "*" {
+			s.ClientID = strings.ToLower(lease.ID)
+		}
+		if lease.Info != nil && !s.Private {
+			s.Vendor = lease.Info.Company
+		}
+	}
+
+	var fresh []Sighting
+	for mac := range t.fresh {
+		if !t.active[mac] {
+			continue
+		}
+		if t.seeded {
+			fresh = append(fresh, *t.sightings[mac])
+		}
+		delete(t.fresh, mac)
+	}
+	t.seeded = true
+	onNew := t.onNew
+	t.mu.Unlock()
+
+	if onNew != nil {
+		for _, s := range fresh {
+			onNew(s)
+		}
+	}
+}
+
+// sighting returns the sighting of mac, creating it if needed, and
+// marks it as seen at when, which may be in the past for replayed logs;
+// the caller holds t.mu
+func (t *Tracker) sighting(mac string, when time.Time) *Sighting {
+	s, ok := t.sightings[mac]
+	if !ok {
+		s = &Sighting{
+			MAC:       mac,
+			Private:   isPrivateMAC(mac),
+			FirstSeen: when,
+		}
+		t.sightings[mac] = s
+		t.fresh[mac] = true
+	}
+	if when.Before(s.FirstSeen) {
+		s.FirstSeen = when
+	}
+	if when.After(s.LastSeen) {
+		s.LastSeen = when
+	}
+	t.dirty = true
+	return s
+}
+
+// Sightings returns a copy of every remembered sighting
+func (t *Tracker) Sightings() []Sighting {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]Sighting, 0, len(t.sightings))
+	for _, s := range t.sightings {
+		result = append(result, *s)
+	}
+	return result
+}
+
+// isPrivateMAC reports whether mac is locally administered, as the
+// randomized addresses used by phones and laptops are
+func isPrivateMAC(mac string) bool {
+	if len(mac) < 2 {
+		return false
+	}
+	var first byte
+	if _, err := fmt.Sscanf(mac[:2], "%02X", &first); err != nil {
+		return false
+	}
+	return first&0x02 != 0
+}
+
+// load reads the sighting history from disk
+func (t *Tracker) load() error {
+	data, err := os.ReadFile(t.file)
+	if err != nil {
+		return err
+	}
+
+	var stored struct {
+		Sightings []*Sighting `json:"sightings"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", t.file, err)
+	}
+
+	for _, s := range stored.Sightings {
+		t.sightings[s.MAC] = s
+	}
+	utils.Infof("Loaded %d device sightings", len(t.sightings))
+	return nil
+}
+
+// saveIfDirty prunes expired sightings and writes the history atomically
+func (t *Tracker) saveIfDirty() {
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return
+	}
+	t.dirty = false
+
+	cutoff := time.Now().Add(-sightingRetention)
+	stored := struct {
+		Sightings []*Sighting `json:"sightings"`
+	}{Sightings: make([]*Sighting, 0, len(t.sightings))}
+	for mac, s := range t.sightings {
+		if s.LastSeen.Before(cutoff) && !t.active[mac] {
+			delete(t.sightings, mac)
+			delete(t.fresh, mac)
+			continue
+		}
+		saved := *s
+		stored.Sightings = append(stored.Sightings, &saved)
+	}
+	t.mu.Unlock()
+
+	if t.file == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		utils.Errorf("Failed to encode device history: %v", err)
+		return
+	}
+	if err := utils.WriteFileAtomic(t.file, data, 0644); err != nil {
+		utils.Errorf("Failed to save device history: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// ===== internal/dhcp/leasefile.go =====
+package dhcp
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// LeaseRecord is a lease line of the dnsmasq leases file. IPv4 lines are
+// "expiry mac ip hostname client-id"; IPv6 lines put the IAID in place
+// of the MAC and the client DUID in place of the client ID.
+type LeaseRecord struct {
+	Expiry     string
+	HWAddr     string // MAC address, or IAID for IPv6
+	IP         net.IP
+	Hostname   string
+	ClientID   string
+	ServerDUID string // From the "duid" line, set for IPv6 leases
+}
+
+// FindLease returns the lease of ip in a dnsmasq leases file
+func FindLease(file string, ip net.IP) (LeaseRecord, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return LeaseRecord{}, fmt.Errorf("failed to read leases: %w", err)
+	}
+
+	serverDUID := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "duid" {
+			serverDUID = fields[1]
+			continue
+		}
+		if len(fields) < 5 {
+			continue
+		}
+		if leaseIP := net.ParseIP(fields[2]); leaseIP != nil && leaseIP.Equal(ip) {
+			record := LeaseRecord{
+				Expiry:   fields[0],
+				HWAddr:   fields[1],
+				IP:       leaseIP,
+				Hostname: fields[3],
+				ClientID: fields[4],
+			}
+			if ip.To4() == nil {
+				record.ServerDUID = serverDUID
+			}
+			return record, nil
+		}
+	}
+	return LeaseRecord{}, fmt.Errorf("no lease for %s in %s", ip, file)
+}
+
+// RemoveLease deletes the lease of ip from a dnsmasq leases file. dnsmasq
+// keeps its leases in memory and rewrites the file, so it must not be
+// running.
+func RemoveLease(file string, ip net.IP) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read leases: %w", err)
+	}
+
+	var kept []string
+	removed := false
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 5 && fields[0] != "duid" {
+			if leaseIP := net.ParseIP(fields[2]); leaseIP != nil && leaseIP.Equal(ip) {
+				removed = true
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	if !removed {
+		return fmt.Errorf("no lease for %s in %s", ip, file)
+	}
+
+	// Rewrite in place, as dnsmasq does, so watchers of the file see the
+	// change
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("failed to write leases: %w", err)
+	}
+	if err := os.WriteFile(file, []byte(strings.Join(kept, "")), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write leases: %w", err)
+	}
+	return nil
+}
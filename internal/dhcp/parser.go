@@ -165,6 +165,11 @@ func (p *Parser) parseStaticLine(line string) (models.DHCPLease, error) {
 			}
 		}
 		
+		// Ignored devices get no lease
+		if strings.EqualFold(value, "ignore") {
+			return lease, fmt.Errorf("ignored host")
+		}
+		
 		// Check for IP address
 		if ip := net.ParseIP(value); ip != nil {
 			lease.IP = ip
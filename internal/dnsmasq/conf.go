@@ -0,0 +1,450 @@
+// ===== internal/dnsmasq/conf.go =====
+package dnsmasq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DHCPRange is one dhcp-range option from the dnsmasq configuration
+type DHCPRange struct {
+	Tags      []string `json:"tags,omitempty"` // tag: conditions
+	SetTag    string   `json:"setTag,omitempty"`
+	Start     net.IP   `json:"start"`
+	End       net.IP   `json:"end,omitempty"`
+	Netmask   net.IP   `json:"netmask,omitempty"`
+	PrefixLen int      `json:"prefixLen,omitempty"` // IPv6 only
+	Mode      string   `json:"mode,omitempty"`      // static, proxy, ra-only, ...
+	LeaseTime string   `json:"leaseTime,omitempty"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+}
+
+// rangeModes are the dhcp-range keywords that take the place of the end
+// address or qualify an IPv6 range
+var rangeModes = map[string]bool{
+	"static":       true,
+	"proxy":        true,
+	"ra-only":      true,
+	"ra-names":     true,
+	"ra-stateless": true,
+	"ra-advrouter": true,
+	"slaac":        true,
+	"off-link":     true,
+}
+
+// Contains reports whether ip lies within the range. Ranges without an
+// end address (static and proxy) cover the subnet given by their netmask
+// or prefix length.
+func (r DHCPRange) Contains(ip net.IP) bool {
+	if ip == nil || r.Start == nil || (ip.To4() == nil) != (r.Start.To4() == nil) {
+		return false
+	}
+	if r.End != nil {
+		return bytes.Compare(ip.To16(), r.Start.To16()) >= 0 && bytes.Compare(ip.To16(), r.End.To16()) <= 0
+	}
+	if subnet := r.Subnet(); subnet != nil {
+		return subnet.Contains(ip)
+	}
+	return ip.Equal(r.Start)
+}
+
+// Subnet returns the network of the range, or nil when the configuration
+// leaves the netmask to the interface dnsmasq serves it on
+func (r DHCPRange) Subnet() *net.IPNet {
+	if r.Start == nil {
+		return nil
+	}
+	if v4 := r.Start.To4(); v4 != nil {
+		if r.Netmask == nil || r.Netmask.To4() == nil {
+			return nil
+		}
+		mask := net.IPMask(r.Netmask.To4())
+		return &net.IPNet{IP: v4.Mask(mask), Mask: mask}
+	}
+	if r.PrefixLen == 0 {
+		return nil
+	}
+	mask := net.CIDRMask(r.PrefixLen, 128)
+	return &net.IPNet{IP: r.Start.Mask(mask), Mask: mask}
+}
+
+// String renders the range the way it reads in the configuration
+func (r DHCPRange) String() string {
+	switch {
+	case r.End != nil:
+		return r.Start.String() + "-" + r.End.String()
+	case r.Mode != "":
+		return r.Start.String() + " (" + r.Mode + ")"
+	}
+	return r.Start.String()
+}
+
+// DHCPConfig is the DHCP part of the dnsmasq configuration
+type DHCPConfig struct {
+	Ranges     []DHCPRange  `json:"ranges"`
+	Options    []DHCPOption `json:"options"`
+	Domains    []Domain     `json:"domains"`
+	Interfaces []string     `json:"interfaces"`         // interface= options; empty serves all
+	Files      []string     `json:"files"`              // Configuration files read
+	Warnings   []Warning    `json:"warnings,omitempty"` // Lines read only in part
+}
+
+// Warning is a problem with a line that does not stop the configuration
+// being read
+type Warning struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// String renders the warning with its location
+func (w Warning) String() string {
+	return fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Message)
+}
+
+// Domain is a domain= option, which names the DHCP clients of an
+// address range, or of all clients when it has none
+type Domain struct {
+	Name      string     `json:"name"`
+	Network   *net.IPNet `json:"-"`
+	Start     net.IP     `json:"start,omitempty"`
+	End       net.IP     `json:"end,omitempty"`
+	Interface string     `json:"interface,omitempty"`
+	Local     bool       `json:"local,omitempty"`
+	File      string     `json:"file"`
+	Line      int        `json:"line"`
+}
+
+// MarshalJSON renders the network in CIDR notation
+func (d Domain) MarshalJSON() ([]byte, error) {
+	type alias Domain
+	network := ""
+	if d.Network != nil {
+		network = d.Network.String()
+	}
+	return json.Marshal(struct {
+		alias
+		Network string `json:"network,omitempty"`
+	}{alias(d), network})
+}
+
+// Covers reports whether the domain applies to clients with address ip;
+// a domain without a range covers every client
+func (d Domain) Covers(ip net.IP) bool {
+	switch {
+	case d.Network != nil:
+		return d.Network.Contains(ip)
+	case d.Start != nil && d.End != nil:
+		return bytes.Compare(ip.To16(), d.Start.To16()) >= 0 && bytes.Compare(ip.To16(), d.End.To16()) <= 0
+	}
+	return d.Interface == ""
+}
+
+// ParseConfig reads the dhcp-range, dhcp-option, domain and interface
+// options of the given configuration files and directories, following
+// conf-file and conf-dir includes. Missing paths are skipped; dnsmasq
+// treats them the same way for the default configuration file.
+func ParseConfig(paths []string) (*DHCPConfig, error) {
+	p := &confParser{conf: &DHCPConfig{}, seen: make(map[string]bool)}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return p.conf, err
+		}
+		if info.IsDir() {
+			err = p.parseDir(path, nil, nil)
+		} else {
+			err = p.parseFile(path)
+		}
+		if err != nil {
+			return p.conf, err
+		}
+	}
+	return p.conf, nil
+}
+
+// confParser walks the configuration files once each
+type confParser struct {
+	conf *DHCPConfig
+	seen map[string]bool
+}
+
+// parseFile reads the DHCP and include options of one file
+func (p *confParser) parseFile(name string) error {
+	if abs, err := filepath.Abs(name); err == nil {
+		name = abs
+	}
+	if p.seen[name] {
+		return nil
+	}
+	p.seen[name] = true
+	p.conf.Files = append(p.conf.Files, name)
+
+	file, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		option, value, _ := strings.Cut(line, "=")
+		option = strings.TrimSpace(option)
+		value = strings.TrimSpace(value)
+
+		switch option {
+		case "dhcp-range":
+			r, err := parseRange(value)
+			if err != nil {
+				// Nor does one bad range take the others down
+				p.warn(name, lineNumber, fmt.Sprintf("skipped %s: %v", option, err))
+				continue
+			}
+			r.File, r.Line = name, lineNumber
+			p.conf.Ranges = append(p.conf.Ranges, r)
+		case "dhcp-option", "dhcp-option-force":
+			opt, ok, err := parseOption(value)
+			if err != nil {
+				// One bad option does not take the ranges down with it
+				p.warn(name, lineNumber, fmt.Sprintf("skipped %s: %v", option, err))
+				continue
+			}
+			if ok && opt.Code == 0 {
+				p.warn(name, lineNumber, fmt.Sprintf("unknown DHCP option %q; it is not applied to subnets", opt.Name))
+			}
+			if ok {
+				opt.Force = option == "dhcp-option-force"
+				opt.File, opt.Line = name, lineNumber
+				p.conf.Options = append(p.conf.Options, opt)
+			}
+		case "domain":
+			d, err := parseDomain(value)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", name, lineNumber, err)
+			}
+			d.File, d.Line = name, lineNumber
+			p.conf.Domains = append(p.conf.Domains, d)
+		case "interface":
+			for _, iface := range strings.Split(value, ",") {
+				if iface = strings.TrimSpace(iface); iface != "" {
+					p.conf.Interfaces = append(p.conf.Interfaces, iface)
+				}
+			}
+		case "conf-file":
+			if err := p.parseFile(p.resolve(name, value)); err != nil {
+				return err
+			}
+		case "conf-dir":
+			parts := strings.Split(value, ",")
+			var include, exclude []string
+			for _, ext := range parts[1:] {
+				ext = strings.TrimSpace(ext)
+				if strings.HasPrefix(ext, "*") {
+					include = append(include, ext[1:])
+				} else if ext != "" {
+					exclude = append(exclude, ext)
+				}
+			}
+			if err := p.parseDir(p.resolve(name, strings.TrimSpace(parts[0])), include, exclude); err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// warn records a problem that does not stop the configuration being read
+func (p *confParser) warn(file string, line int, message string) {
+	p.conf.Warnings = append(p.conf.Warnings, Warning{File: file, Line: line, Message: message})
+}
+
+// resolve makes an included path relative to the including file absolute
+func (p *confParser) resolve(from, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(from), path)
+}
+
+// parseDir reads the files of a conf-dir in name order. As in dnsmasq,
+// hidden files and editor backups are skipped, and only files with one of
+// the include suffixes are read when any are given.
+func (p *confParser) parseDir(dir string, include, exclude []string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") ||
+			(strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#")) {
+			continue
+		}
+		if len(include) > 0 && !hasAnySuffix(name, include) {
+			continue
+		}
+		if hasAnySuffix(name, exclude) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := p.parseFile(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasAnySuffix reports whether name ends with one of suffixes
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange parses the value of a dhcp-range option:
+//
+//	[tag:<tag>,...][set:<tag>,]<start>[,<end>|<mode>][,<netmask>[,<broadcast>]][,<lease time>]
+//	[tag:<tag>,...][set:<tag>,]<start-IPv6>[,<end-IPv6>|constructor:<if>][,<mode>][,<prefix-len>][,<lease time>]
+func parseRange(value string) (DHCPRange, error) {
+	var r DHCPRange
+	fields := strings.Split(value, ",")
+	i := 0
+
+options:
+	for ; i < len(fields); i++ {
+		field := strings.TrimSpace(fields[i])
+		switch {
+		case strings.HasPrefix(field, "tag:"):
+			r.Tags = append(r.Tags, strings.TrimPrefix(field, "tag:"))
+		case strings.HasPrefix(field, "set:"):
+			r.SetTag = strings.TrimPrefix(field, "set:")
+		case strings.HasPrefix(field, "net:"):
+			// Pre-2.52 syntax for set:
+			r.SetTag = strings.TrimPrefix(field, "net:")
+		case net.ParseIP(field) == nil && i+1 < len(fields):
+			// Older still: a bare network ID before the start address
+			r.SetTag = field
+		default:
+			break options
+		}
+	}
+
+	if i >= len(fields) {
+		return r, fmt.Errorf("dhcp-range has no start address")
+	}
+	r.Start = net.ParseIP(strings.TrimSpace(fields[i]))
+	if r.Start == nil {
+		return r, fmt.Errorf("invalid dhcp-range start address %q", strings.TrimSpace(fields[i]))
+	}
+	ipv6 := r.Start.To4() == nil
+
+	for _, field := range fields[i+1:] {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if ip := net.ParseIP(field); ip != nil {
+			switch {
+			case r.End == nil && r.Mode == "" && r.Netmask == nil:
+				r.End = ip
+			case !ipv6 && r.Netmask == nil:
+				r.Netmask = ip
+			}
+			// A second IPv4 address after the netmask is the broadcast
+			// address, which is not needed here
+			continue
+		}
+
+		if rangeModes[field] || strings.HasPrefix(field, "constructor:") {
+			if r.Mode == "" {
+				r.Mode = field
+			} else {
+				r.Mode += "," + field
+			}
+			continue
+		}
+
+		if n, err := strconv.Atoi(field); err == nil && ipv6 && r.PrefixLen == 0 && n > 0 && n <= 128 {
+			r.PrefixLen = n
+			continue
+		}
+
+		r.LeaseTime = field
+	}
+
+	if r.End != nil && (r.End.To4() == nil) != ipv6 {
+		return r, fmt.Errorf("dhcp-range mixes IPv4 and IPv6 addresses")
+	}
+	return r, nil
+}
+
+// parseDomain parses the value of a domain option:
+//
+//	<domain>[,<address range>[,local]]
+//	<domain>,<interface>
+//
+// where the address range is a CIDR network or a start and end address
+func parseDomain(value string) (Domain, error) {
+	fields := strings.Split(value, ",")
+	d := Domain{Name: strings.TrimSpace(fields[0])}
+	if d.Name == "" {
+		return d, fmt.Errorf("domain has no name")
+	}
+
+	rest := fields[1:]
+	if n := len(rest); n > 0 && strings.TrimSpace(rest[n-1]) == "local" {
+		d.Local = true
+		rest = rest[:n-1]
+	}
+	if len(rest) == 0 {
+		return d, nil
+	}
+
+	first := strings.TrimSpace(rest[0])
+	if _, network, err := net.ParseCIDR(first); err == nil {
+		d.Network = network
+		return d, nil
+	}
+	if start := net.ParseIP(first); start != nil {
+		if len(rest) < 2 || net.ParseIP(strings.TrimSpace(rest[1])) == nil {
+			return d, fmt.Errorf("domain %s has a start address but no end address", d.Name)
+		}
+		d.Start, d.End = start, net.ParseIP(strings.TrimSpace(rest[1]))
+		return d, nil
+	}
+	d.Interface = first
+	return d, nil
+}
@@ -0,0 +1,54 @@
+// ===== internal/dnsmasq/controller.go =====
+package dnsmasq
+
+import (
+	"time"
+
+	"dhcpmon/internal/config"
+)
+
+// Controller starts, stops and reports on the dnsmasq service
+type Controller interface {
+	Start() error
+	Stop() error
+	Restart() error
+	// Reload asks dnsmasq to re-read hosts and option files (SIGHUP)
+	Reload() error
+	Status() Status
+}
+
+// ExitRecord describes one exit of a supervised dnsmasq process
+type ExitRecord struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"startedAt"`
+	ExitedAt  time.Time `json:"exitedAt"`
+	ExitCode  int       `json:"exitCode"`
+	Signal    string    `json:"signal,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Crash     bool      `json:"crash"` // Exit was not requested
+}
+
+// Status reports the state of the dnsmasq service
+type Status struct {
+	Mode        string       `json:"mode"` // supervised or systemd
+	Service     string       `json:"service"`
+	Running     bool         `json:"running"`
+	State       string       `json:"state"`
+	PID         int          `json:"pid,omitempty"`
+	StartedAt   *time.Time   `json:"startedAt,omitempty"`
+	Uptime      float64      `json:"uptime"` // Seconds
+	Restarts    int          `json:"restarts"`
+	NextRestart *time.Time   `json:"nextRestart,omitempty"`
+	Command     []string     `json:"command,omitempty"`
+	History     []ExitRecord `json:"history,omitempty"`
+}
+
+// NewController returns the controller for the configured mode: the
+// systemd unit when SystemD is set, otherwise a supervised child process
+// whose output is passed to sink
+func NewController(cfg *config.Config, sink func(channel, line string)) Controller {
+	if cfg.SystemD {
+		return NewSystemdUnit(cfg.Systemctl, cfg.SystemdUnit)
+	}
+	return NewSupervisor(cfg, sink)
+}
@@ -0,0 +1,201 @@
+// ===== internal/dnsmasq/options.go =====
+package dnsmasq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Option codes used to describe subnets
+const (
+	OptionNetmask      = 1
+	OptionRouter       = 3
+	OptionDNSServer    = 6
+	OptionDomainName   = 15
+	OptionBroadcast    = 28
+	OptionNTPServer    = 42
+	OptionDomainSearch = 119
+
+	Option6DNSServer    = 23
+	Option6DomainSearch = 24
+	Option6NTPServer    = 56
+)
+
+// DHCPOption is one dhcp-option or dhcp-option-force option
+type DHCPOption struct {
+	Tags   []string `json:"tags,omitempty"` // tag: conditions; "!x" requires x unset
+	Code   int      `json:"code"`           // 0 for names this package does not know
+	Name   string   `json:"name,omitempty"`
+	IPv6   bool     `json:"ipv6,omitempty"`
+	Values []string `json:"values"`
+	Force  bool     `json:"force,omitempty"`
+	File   string   `json:"file"`
+	Line   int      `json:"line"`
+}
+
+// optionNames are the dnsmasq names of the common DHCPv4 options
+var optionNames = map[string]int{
+	"netmask":                1,
+	"time-offset":            2,
+	"router":                 3,
+	"dns-server":             6,
+	"log-server":             7,
+	"lpr-server":             9,
+	"hostname":               12,
+	"boot-file-size":         13,
+	"domain-name":            15,
+	"swap-server":            16,
+	"root-path":              17,
+	"extension-path":         18,
+	"ip-forward-enable":      19,
+	"mtu":                    26,
+	"broadcast":              28,
+	"router-discovery":       31,
+	"router-solicitation":    32,
+	"static-route":           33,
+	"arp-timeout":            35,
+	"tcp-ttl":                37,
+	"tcp-keepalive":          38,
+	"nis-domain":             40,
+	"nis-server":             41,
+	"ntp-server":             42,
+	"vendor-encap":           43,
+	"netbios-ns":             44,
+	"netbios-dd":             45,
+	"netbios-nodetype":       46,
+	"netbios-scope":          47,
+	"lease-time":             51,
+	"server-identifier":      54,
+	"T1":                     58,
+	"T2":                     59,
+	"vendor-class":           60,
+	"tftp-server":            66,
+	"bootfile-name":          67,
+	"smtp-server":            69,
+	"pop3-server":            70,
+	"nntp-server":            71,
+	"irc-server":             74,
+	"user-class":             77,
+	"posix-timezone":         100,
+	"tzdb-timezone":          101,
+	"domain-search":          119,
+	"sip-server":             120,
+	"classless-static-route": 121,
+	"tftp-server-address":    150,
+	"server-ip-address":      255,
+}
+
+// option6Names are the dnsmasq names of the common DHCPv6 options
+var option6Names = map[string]int{
+	"dns-server":               23,
+	"domain-search":            24,
+	"sntp-server":              31,
+	"information-refresh-time": 32,
+	"ntp-server":               56,
+	"bootfile-url":             59,
+}
+
+// OptionName returns the dnsmasq name of an option code, or "" when it
+// has none
+func OptionName(code int, ipv6 bool) string {
+	names := optionNames
+	if ipv6 {
+		names = option6Names
+	}
+	for name, c := range names {
+		if c == code {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseOption parses the value of a dhcp-option option:
+//
+//	[tag:<tag>,...][encap:<opt>,][vi-encap:<enterprise>,][vendor:[<class>],]<opt>|option:<name>|option6:<opt>,[<value>...]
+//
+// Encapsulated and vendor options describe devices rather than subnets,
+// so they are skipped and ok is false. An option named by a name missing
+// from optionNames or option6Names is returned with code 0.
+func parseOption(value string) (opt DHCPOption, ok bool, err error) {
+	fields := splitOptionValues(value)
+
+	i := 0
+qualifiers:
+	for ; i < len(fields); i++ {
+		field := strings.TrimSpace(fields[i])
+		switch {
+		case strings.HasPrefix(field, "tag:"):
+			opt.Tags = append(opt.Tags, strings.TrimPrefix(field, "tag:"))
+		case strings.HasPrefix(field, "net:"):
+			opt.Tags = append(opt.Tags, strings.TrimPrefix(field, "net:"))
+		case strings.HasPrefix(field, "encap:"), strings.HasPrefix(field, "vi-encap:"),
+			strings.HasPrefix(field, "vendor:"), strings.HasPrefix(field, "rfc3925-encap:"):
+			return opt, false, nil
+		default:
+			break qualifiers
+		}
+	}
+
+	if i >= len(fields) {
+		return opt, false, fmt.Errorf("dhcp-option has no option code")
+	}
+
+	spec := strings.TrimSpace(fields[i])
+	switch {
+	case strings.HasPrefix(spec, "option6:"):
+		opt.IPv6 = true
+		spec = strings.TrimPrefix(spec, "option6:")
+	case strings.HasPrefix(spec, "option:"):
+		spec = strings.TrimPrefix(spec, "option:")
+	}
+
+	if code, err := strconv.Atoi(spec); err == nil {
+		opt.Code = code
+		opt.Name = OptionName(code, opt.IPv6)
+	} else {
+		names := optionNames
+		if opt.IPv6 {
+			names = option6Names
+		}
+		// A name missing from the tables keeps code 0; the option is
+		// still read, so its values are known
+		opt.Code, opt.Name = names[spec], spec
+	}
+
+	opt.Values = []string{}
+	for _, v := range fields[i+1:] {
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		if opt.IPv6 {
+			v = strings.TrimSuffix(strings.TrimPrefix(v, "["), "]")
+		}
+		opt.Values = append(opt.Values, v)
+	}
+	return opt, true, nil
+}
+
+// splitOptionValues splits an option on commas outside double quotes and
+// IPv6 brackets
+func splitOptionValues(value string) []string {
+	var fields []string
+	var current strings.Builder
+	quoted, bracketed := false, false
+
+	for _, char := range value {
+		switch {
+		case char == '"':
+			quoted = !quoted
+		case char == '[' && !quoted:
+			bracketed = true
+		case char == ']' && !quoted:
+			bracketed = false
+		case char == ',' && !quoted && !bracketed:
+			fields = append(fields, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteRune(char)
+	}
+	return append(fields, current.String())
+}
@@ -0,0 +1,65 @@
+// ===== internal/dnsmasq/release.go =====
+package dnsmasq
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Releaser revokes leases with dnsmasq's dhcp_release and dhcp_release6
+// helpers, which send dnsmasq a DHCPRELEASE on behalf of the client
+type Releaser struct {
+	release  string
+	release6 string
+}
+
+// NewReleaser creates a releaser using the helpers at the given paths
+func NewReleaser(release, release6 string) *Releaser {
+	return &Releaser{release: release, release6: release6}
+}
+
+// Available reports whether the helper for IPv4 or IPv6 is installed
+func (r *Releaser) Available(ipv6 bool) bool {
+	path := r.release
+	if ipv6 {
+		path = r.release6
+	}
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0111 != 0
+}
+
+// Release revokes the IPv4 lease of ip held by mac. clientID is the
+// client identifier from the leases file, or "*" when there is none.
+func (r *Releaser) Release(iface string, ip net.IP, mac net.HardwareAddr, clientID string) error {
+	args := []string{iface, ip.String(), mac.String()}
+	if clientID != "" && clientID != "*" {
+		args = append(args, clientID)
+	}
+	return r.run(r.release, args...)
+}
+
+// Release6 revokes the IPv6 lease of ip. The client and server DUIDs
+// and the IAID are taken from the leases file.
+func (r *Releaser) Release6(iface string, ip net.IP, iaid, clientID, serverID string) error {
+	return r.run(r.release6,
+		"--iface", iface,
+		"--client-id", clientID,
+		"--server-id", serverID,
+		"--iaid", iaid,
+		"--ip", ip.String())
+}
+
+// run executes a helper
+func (r *Releaser) run(path string, args ...string) error {
+	output, err := exec.Command(path, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v: %s", path, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
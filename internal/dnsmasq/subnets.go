@@ -0,0 +1,315 @@
+// ===== internal/dnsmasq/subnets.go =====
+package dnsmasq
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+
+	"dhcpmon/pkg/utils"
+)
+
+// Sources of a subnet's netmask
+const (
+	MaskFromConfig    = "config"    // netmask or prefix length in dhcp-range
+	MaskFromInterface = "interface" // a local interface on the network
+	MaskAssumed       = "assumed"   // /24 or /64, nothing better known
+)
+
+// defaultLeaseTime is what dnsmasq uses when dhcp-range sets none
+const defaultLeaseTime = "1h"
+
+// Subnet is a network dnsmasq serves, with its pools and the options
+// its clients receive
+type Subnet struct {
+	Network      *net.IPNet   `json:"-"`
+	Family       string       `json:"family"` // ipv4 or ipv6
+	MaskSource   string       `json:"maskSource"`
+	Interface    string       `json:"interface,omitempty"`
+	Tags         []string     `json:"tags,omitempty"` // Tags set or required by the pools, and the interface
+	Pools        []Pool       `json:"pools"`
+	Size         uint64       `json:"size"` // Dynamic addresses across all pools
+	LeaseTime    string       `json:"leaseTime"`
+	Router       []string     `json:"router,omitempty"`
+	DNS          []string     `json:"dns,omitempty"`
+	NTP          []string     `json:"ntp,omitempty"`
+	Domain       string       `json:"domain,omitempty"`
+	DomainSearch []string     `json:"domainSearch,omitempty"`
+	Options      []DHCPOption `json:"options"` // Options sent on this subnet
+}
+
+// Pool is one dhcp-range of a subnet
+type Pool struct {
+	DHCPRange
+	Size uint64 `json:"size"` // Addresses handed out dynamically
+}
+
+// MarshalJSON renders the network in CIDR notation
+func (s Subnet) MarshalJSON() ([]byte, error) {
+	type alias Subnet
+	return json.Marshal(struct {
+		alias
+		Network string `json:"network"`
+	}{alias(s), s.Network.String()})
+}
+
+// Contains reports whether ip belongs to the subnet
+func (s Subnet) Contains(ip net.IP) bool {
+	return s.Network.Contains(ip)
+}
+
+// InPool reports whether ip lies in one of the subnet's dynamic pools
+func (s Subnet) InPool(ip net.IP) bool {
+	for _, pool := range s.Pools {
+		if pool.End != nil && pool.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// InterfaceNetwork is a network configured on a local interface
+type InterfaceNetwork struct {
+	Name    string
+	Network *net.IPNet
+}
+
+// LocalNetworks returns the networks of the local interfaces, which give
+// the netmask of ranges that do not set one
+func LocalNetworks() []InterfaceNetwork {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+
+	var networks []InterfaceNetwork
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+				networks = append(networks, InterfaceNetwork{
+					Name:    iface.Name,
+					Network: &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask},
+				})
+			}
+		}
+	}
+	return networks
+}
+
+// Subnets groups the ranges into subnets and resolves the options and
+// domain of each. Ranges without a netmask take it from the local
+// interface on the same network, as dnsmasq does.
+func (c *DHCPConfig) Subnets(local []InterfaceNetwork) []Subnet {
+	var subnets []*Subnet
+	byNetwork := make(map[string]*Subnet)
+
+	for _, r := range c.Ranges {
+		network, source, iface := rangeNetwork(r, local)
+		key := network.String()
+
+		subnet, ok := byNetwork[key]
+		if !ok {
+			subnet = &Subnet{
+				Network:    network,
+				Family:     "ipv4",
+				MaskSource: source,
+				Interface:  iface,
+			}
+			if r.Start.To4() == nil {
+				subnet.Family = "ipv6"
+			}
+			if subnet.Interface != "" {
+				subnet.Tags = append(subnet.Tags, subnet.Interface)
+			}
+			byNetwork[key] = subnet
+			subnets = append(subnets, subnet)
+		}
+
+		pool := Pool{DHCPRange: r, Size: rangeSize(r)}
+		subnet.Pools = append(subnet.Pools, pool)
+		subnet.Size = saturatingAdd(subnet.Size, pool.Size)
+		if r.SetTag != "" && !utils.ContainsString(subnet.Tags, r.SetTag) {
+			subnet.Tags = append(subnet.Tags, r.SetTag)
+		}
+		// Clients of a range that requires a tag carry it, as with
+		// dhcp-host=...,set:x and dhcp-range=tag:x,...
+		for _, tag := range r.Tags {
+			if !strings.HasPrefix(tag, "!") && !utils.ContainsString(subnet.Tags, tag) {
+				subnet.Tags = append(subnet.Tags, tag)
+			}
+		}
+		if subnet.LeaseTime == "" && r.LeaseTime != "" {
+			subnet.LeaseTime = r.LeaseTime
+		}
+	}
+
+	result := make([]Subnet, 0, len(subnets))
+	for _, subnet := range subnets {
+		if subnet.LeaseTime == "" {
+			subnet.LeaseTime = defaultLeaseTime
+		}
+		c.applyOptions(subnet)
+		c.applyDomain(subnet)
+		result = append(result, *subnet)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Family != result[j].Family {
+			return result[i].Family == "ipv4"
+		}
+		return bytes.Compare(result[i].Network.IP.To16(), result[j].Network.IP.To16()) < 0
+	})
+	return result
+}
+
+// rangeNetwork returns the network of a range and where its mask came from
+func rangeNetwork(r DHCPRange, local []InterfaceNetwork) (*net.IPNet, string, string) {
+	iface := ""
+	for _, l := range local {
+		if l.Network.Contains(r.Start) {
+			iface = l.Name
+			if r.Subnet() == nil {
+				return l.Network, MaskFromInterface, iface
+			}
+			break
+		}
+	}
+
+	if network := r.Subnet(); network != nil {
+		return network, MaskFromConfig, iface
+	}
+
+	bits, size := 24, 32
+	if r.Start.To4() == nil {
+		bits, size = 64, 128
+	}
+	mask := net.CIDRMask(bits, size)
+	start := r.Start.To4()
+	if start == nil {
+		start = r.Start
+	}
+	return &net.IPNet{IP: start.Mask(mask), Mask: mask}, MaskAssumed, iface
+}
+
+// applyOptions resolves the options sent on a subnet. An option applies
+// when all its tags are set on the subnet (or, for "!tag", unset), and
+// tagged options override untagged ones with the same code.
+func (c *DHCPConfig) applyOptions(subnet *Subnet) {
+	ipv6 := subnet.Family == "ipv6"
+	chosen := make(map[int]DHCPOption)
+	var codes []int
+
+	for _, opt := range c.Options {
+		if opt.Code == 0 || opt.IPv6 != ipv6 || !tagsMatch(opt.Tags, subnet.Tags) {
+			continue
+		}
+		current, ok := chosen[opt.Code]
+		if !ok {
+			codes = append(codes, opt.Code)
+		}
+		if !ok || len(opt.Tags) > len(current.Tags) {
+			chosen[opt.Code] = opt
+		}
+	}
+
+	sort.Ints(codes)
+	subnet.Options = make([]DHCPOption, 0, len(codes))
+	for _, code := range codes {
+		opt := chosen[code]
+		subnet.Options = append(subnet.Options, opt)
+
+		switch {
+		case !ipv6 && code == OptionRouter:
+			subnet.Router = opt.Values
+		case !ipv6 && code == OptionDNSServer, ipv6 && code == Option6DNSServer:
+			subnet.DNS = opt.Values
+		case !ipv6 && code == OptionNTPServer, ipv6 && code == Option6NTPServer:
+			subnet.NTP = opt.Values
+		case !ipv6 && code == OptionDomainName:
+			if len(opt.Values) > 0 {
+				subnet.Domain = opt.Values[0]
+			}
+		case !ipv6 && code == OptionDomainSearch, ipv6 && code == Option6DomainSearch:
+			subnet.DomainSearch = opt.Values
+		}
+	}
+}
+
+// applyDomain sets the domain of a subnet from the domain options when no
+// domain-name option names it. A domain for an address range or interface
+// takes precedence over the global one.
+func (c *DHCPConfig) applyDomain(subnet *Subnet) {
+	if subnet.Domain != "" {
+		return
+	}
+
+	global := ""
+	for _, d := range c.Domains {
+		switch {
+		case d.Interface != "":
+			if d.Interface == subnet.Interface {
+				subnet.Domain = d.Name
+				return
+			}
+		case d.Network != nil || d.Start != nil:
+			if d.Covers(subnet.Network.IP) || (len(subnet.Pools) > 0 && d.Covers(subnet.Pools[0].Start)) {
+				subnet.Domain = d.Name
+				return
+			}
+		default:
+			// As with other options, a later global domain replaces an
+			// earlier one
+			global = d.Name
+		}
+	}
+	subnet.Domain = global
+}
+
+// tagsMatch reports whether the tag conditions of an option hold for a
+// subnet with the given tags
+func tagsMatch(conditions, tags []string) bool {
+	for _, condition := range conditions {
+		if strings.HasPrefix(condition, "!") {
+			if utils.ContainsString(tags, condition[1:]) {
+				return false
+			}
+		} else if !utils.ContainsString(tags, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// rangeSize returns the number of addresses in a dynamic range, or 0 for
+// static and proxy ranges, saturating for large IPv6 ranges
+func rangeSize(r DHCPRange) uint64 {
+	if r.End == nil {
+		return 0
+	}
+	start := new(big.Int).SetBytes(r.Start.To16())
+	end := new(big.Int).SetBytes(r.End.To16())
+	size := new(big.Int).Sub(end, start)
+	size.Add(size, big.NewInt(1))
+	if size.Sign() <= 0 {
+		return 0
+	}
+	if !size.IsUint64() {
+		return math.MaxUint64
+	}
+	return size.Uint64()
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
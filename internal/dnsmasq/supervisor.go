@@ -0,0 +1,363 @@
+// ===== internal/dnsmasq/supervisor.go =====
+package dnsmasq
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"dhcpmon/internal/config"
+	"dhcpmon/pkg/utils"
+)
+
+const (
+	// maxExitHistory is the number of exit records kept
+	maxExitHistory = 20
+
+	// stopTimeout is how long dnsmasq gets to exit after SIGTERM
+	stopTimeout = 10 * time.Second
+)
+
+// Restart policies
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "never"
+)
+
+// Supervisor runs dnsmasq as a child process and restarts it with
+// exponential backoff according to the restart policy
+type Supervisor struct {
+	binary      string
+	args        []string
+	policy      string
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	stableAfter time.Duration
+	sink        func(channel, line string)
+
+	mu          sync.Mutex
+	want        bool // Desired state: running or stopped
+	cmd         *exec.Cmd
+	startedAt   time.Time
+	exited      chan struct{}
+	stopping    bool
+	restarts    int
+	backoff     time.Duration
+	nextRestart time.Time
+	wake        chan struct{}
+	history     []ExitRecord
+	loop        bool
+}
+
+// NewSupervisor creates a supervisor for the configured dnsmasq binary
+func NewSupervisor(cfg *config.Config, sink func(channel, line string)) *Supervisor {
+	args := strings.Fields(cfg.DNSMasqArgs)
+	if !hasArg(args, "--keep-in-foreground") && !hasArg(args, "-k") {
+		// The supervisor can only track a process that does not daemonize
+		args = append([]string{"--keep-in-foreground"}, args...)
+	}
+
+	return &Supervisor{
+		binary:      cfg.DNSMasq,
+		args:        args,
+		policy:      cfg.RestartPolicy,
+		minBackoff:  cfg.RestartBackoff,
+		maxBackoff:  cfg.RestartMaxBackoff,
+		stableAfter: time.Minute,
+		sink:        sink,
+		backoff:     cfg.RestartBackoff,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// hasArg reports whether args contains the flag, with or without a value
+func hasArg(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Start starts dnsmasq and keeps it running
+func (s *Supervisor) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.want = true
+	s.backoff = s.minBackoff
+	s.nextRestart = time.Time{}
+
+	if !s.loop {
+		s.loop = true
+		go s.run()
+	} else {
+		s.notify()
+	}
+	return nil
+}
+
+// Stop stops dnsmasq and disables automatic restarts
+func (s *Supervisor) Stop() error {
+	s.mu.Lock()
+	s.want = false
+	s.nextRestart = time.Time{}
+	s.notify()
+	s.mu.Unlock()
+
+	return s.terminate()
+}
+
+// Restart stops the running dnsmasq and starts a new one immediately
+func (s *Supervisor) Restart() error {
+	s.mu.Lock()
+	s.want = true
+	s.backoff = s.minBackoff
+	s.mu.Unlock()
+
+	if err := s.terminate(); err != nil {
+		return err
+	}
+	return s.Start()
+}
+
+// Reload sends SIGHUP so dnsmasq re-reads its hosts and option files
+func (s *Supervisor) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cmd == nil || s.cmd.Process == nil {
+		return fmt.Errorf("dnsmasq is not running")
+	}
+	return s.cmd.Process.Signal(syscall.SIGHUP)
+}
+
+// Status reports the process state and exit history
+func (s *Supervisor) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Mode:     "supervised",
+		Service:  "dnsmasq",
+		Restarts: s.restarts,
+		Command:  append([]string{s.binary}, s.args...),
+		History:  append([]ExitRecord(nil), s.history...),
+		State:    "stopped",
+	}
+
+	if s.cmd != nil && s.cmd.Process != nil {
+		started := s.startedAt
+		status.Running = true
+		status.State = "running"
+		status.PID = s.cmd.Process.Pid
+		status.StartedAt = &started
+		status.Uptime = time.Since(started).Seconds()
+	} else if s.want && !s.nextRestart.IsZero() {
+		next := s.nextRestart
+		status.State = "restarting"
+		status.NextRestart = &next
+	} else if s.want {
+		status.State = "starting"
+	}
+
+	return status
+}
+
+// notify wakes the run loop; the caller must hold s.mu
+func (s *Supervisor) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run is the supervision loop
+func (s *Supervisor) run() {
+	for {
+		s.mu.Lock()
+		want := s.want
+		wait := time.Until(s.nextRestart)
+		s.mu.Unlock()
+
+		if !want {
+			<-s.wake
+			continue
+		}
+
+		if wait > 0 {
+			select {
+			case <-s.wake:
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		record := s.runOnce()
+
+		s.mu.Lock()
+		s.history = append(s.history, record)
+		if len(s.history) > maxExitHistory {
+			s.history = s.history[len(s.history)-maxExitHistory:]
+		}
+		s.scheduleRestart(record)
+		s.mu.Unlock()
+	}
+}
+
+// scheduleRestart applies the restart policy after an exit; the caller
+// must hold s.mu
+func (s *Supervisor) scheduleRestart(record ExitRecord) {
+	if !s.want {
+		return
+	}
+
+	if !record.Crash {
+		// Requested restart: start again right away
+		s.nextRestart = time.Time{}
+		return
+	}
+
+	switch s.policy {
+	case RestartNever:
+		s.want = false
+		return
+	case RestartOnFailure:
+		if record.ExitCode == 0 && record.Signal == "" && record.Error == "" {
+			s.want = false
+			return
+		}
+	}
+
+	// A process that ran long enough was healthy; reset the backoff
+	if record.ExitedAt.Sub(record.StartedAt) >= s.stableAfter {
+		s.backoff = s.minBackoff
+	}
+
+	s.restarts++
+	s.nextRestart = time.Now().Add(s.backoff)
+	utils.Infof("dnsmasq will be restarted in %s", s.backoff)
+
+	s.backoff *= 2
+	if s.backoff > s.maxBackoff {
+		s.backoff = s.maxBackoff
+	}
+}
+
+// runOnce starts dnsmasq and waits for it to exit
+func (s *Supervisor) runOnce() ExitRecord {
+	cmd := exec.Command(s.binary, s.args...)
+	record := ExitRecord{StartedAt: time.Now(), Crash: true}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		record.Error = fmt.Sprintf("failed to create stdout pipe: %v", err)
+		record.ExitedAt = time.Now()
+		return record
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		record.Error = fmt.Sprintf("failed to create stderr pipe: %v", err)
+		record.ExitedAt = time.Now()
+		return record
+	}
+
+	utils.Infof("Starting dnsmasq: %v", cmd.Args)
+	if err := cmd.Start(); err != nil {
+		utils.Errorf("Failed to start dnsmasq: %v", err)
+		record.Error = err.Error()
+		record.ExitCode = -1
+		record.ExitedAt = time.Now()
+		return record
+	}
+
+	exited := make(chan struct{})
+	s.mu.Lock()
+	s.cmd = cmd
+	s.startedAt = record.StartedAt
+	s.exited = exited
+	s.stopping = !s.want
+	if s.stopping {
+		// Stop was requested while the process was being started
+		cmd.Process.Signal(syscall.SIGTERM)
+	}
+	s.mu.Unlock()
+
+	record.PID = cmd.Process.Pid
+
+	var scanners sync.WaitGroup
+	scanners.Add(2)
+	go s.scan(stdout, "stdout", &scanners)
+	go s.scan(stderr, "stderr", &scanners)
+	scanners.Wait()
+
+	waitErr := cmd.Wait()
+	record.ExitedAt = time.Now()
+	record.ExitCode = cmd.ProcessState.ExitCode()
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		record.Signal = ws.Signal().String()
+	}
+
+	s.mu.Lock()
+	record.Crash = !s.stopping
+	s.cmd = nil
+	s.exited = nil
+	s.mu.Unlock()
+	close(exited)
+
+	if record.Crash {
+		utils.Warnf("dnsmasq (pid %d) exited unexpectedly: %v", record.PID, waitErr)
+	} else {
+		utils.Infof("dnsmasq (pid %d) stopped", record.PID)
+	}
+	return record
+}
+
+// scan passes each output line of dnsmasq to the sink
+func (s *Supervisor) scan(reader io.Reader, channel string, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if s.sink != nil {
+			s.sink(channel, scanner.Text())
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		utils.Errorf("Error scanning %s: %v", channel, err)
+	}
+}
+
+// terminate stops the running process, escalating to SIGKILL
+func (s *Supervisor) terminate() error {
+	s.mu.Lock()
+	cmd, exited := s.cmd, s.exited
+	if cmd == nil || cmd.Process == nil {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopping = true
+	s.mu.Unlock()
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop dnsmasq: %w", err)
+	}
+
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(stopTimeout):
+		utils.Warnf("dnsmasq did not exit after SIGTERM, killing it")
+		cmd.Process.Kill()
+		<-exited
+		return nil
+	}
+}
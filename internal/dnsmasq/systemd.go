@@ -0,0 +1,92 @@
+// ===== internal/dnsmasq/systemd.go =====
+package dnsmasq
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// systemdTimestamp is the format of systemctl show timestamps
+const systemdTimestamp = "Mon 2006-01-02 15:04:05 MST"
+
+// SystemdUnit controls dnsmasq through systemctl
+type SystemdUnit struct {
+	systemctl string
+	unit      string
+}
+
+// NewSystemdUnit creates a controller for a systemd unit
+func NewSystemdUnit(systemctl, unit string) *SystemdUnit {
+	return &SystemdUnit{systemctl: systemctl, unit: unit}
+}
+
+// Start starts the unit
+func (u *SystemdUnit) Start() error {
+	return u.run("start")
+}
+
+// Stop stops the unit
+func (u *SystemdUnit) Stop() error {
+	return u.run("stop")
+}
+
+// Restart restarts the unit
+func (u *SystemdUnit) Restart() error {
+	return u.run("restart")
+}
+
+// Reload reloads the unit (dnsmasq.service sends SIGHUP)
+func (u *SystemdUnit) Reload() error {
+	return u.run("reload")
+}
+
+// run executes a systemctl action on the unit
+func (u *SystemdUnit) run(action string) error {
+	output, err := exec.Command(u.systemctl, action, u.unit).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s %s failed: %v: %s", action, u.unit, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Status queries systemd for the unit state
+func (u *SystemdUnit) Status() Status {
+	status := Status{
+		Mode:    "systemd",
+		Service: u.unit,
+		State:   "unknown",
+	}
+
+	output, err := exec.Command(u.systemctl, "show", u.unit,
+		"--property=ActiveState,SubState,MainPID,ActiveEnterTimestamp,NRestarts").Output()
+	if err != nil {
+		return status
+	}
+
+	props := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			props[key] = strings.TrimSpace(value)
+		}
+	}
+
+	status.State = props["ActiveState"]
+	if sub := props["SubState"]; sub != "" {
+		status.State += " (" + sub + ")"
+	}
+	status.Running = props["ActiveState"] == "active"
+	status.PID, _ = strconv.Atoi(props["MainPID"])
+	status.Restarts, _ = strconv.Atoi(props["NRestarts"])
+
+	if status.Running {
+		if started, err := time.Parse(systemdTimestamp, props["ActiveEnterTimestamp"]); err == nil {
+			status.StartedAt = &started
+			status.Uptime = time.Since(started).Seconds()
+		}
+	}
+
+	return status
+}
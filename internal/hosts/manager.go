@@ -0,0 +1,348 @@
+// ===== internal/hosts/manager.go =====
+package hosts
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// entrySeq makes IDs of entries added within the same clock tick unique
+var entrySeq uint64
+
+// Manager handles hosts file management. The file is kept line by line
+// so that comments, blank lines and untouched entries are written back
+// exactly as they were read.
+type Manager struct {
+	parser     *Parser
+	filename   string
+	content    string                     // File content as last loaded or saved
+	lines      []string                   // The same, line by line
+	original   map[int]models.HostEntry   // Entries as loaded, by line number
+	entries    []models.HostEntry
+	mu         sync.RWMutex
+	lastModify time.Time
+}
+
+// NewManager creates a new hosts file manager
+func NewManager(filename string) *Manager {
+	return &Manager{
+		parser:   NewParser(),
+		filename: filename,
+		original: make(map[int]models.HostEntry),
+		entries:  make([]models.HostEntry, 0),
+	}
+}
+
+// Load loads host entries from the hosts file
+func (m *Manager) Load() error {
+	content, err := os.ReadFile(m.filename)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	if err := m.use(string(content)); err != nil {
+		return err
+	}
+	utils.Infof("Loaded %d host entries from %s", len(m.entries), m.filename)
+	return nil
+}
+
+// use parses content and makes it the loaded file; the caller holds m.mu
+func (m *Manager) use(content string) error {
+	entries, err := m.parser.ParseHosts(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse hosts: %w", err)
+	}
+	
+	m.content = content
+	m.lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if len(content) == 0 {
+		m.lines = nil
+	}
+	m.original = make(map[int]models.HostEntry, len(entries))
+	for _, entry := range entries {
+		m.original[entry.LineNumber] = entry
+	}
+	m.entries = entries
+	m.lastModify = time.Now()
+	return nil
+}
+
+// Save writes the host entries back to the hosts file. Unchanged lines
+// are kept verbatim, changed entries are rewritten in place, deleted
+// entries are dropped and new entries are appended. The file is replaced
+// atomically so dnsmasq never reads a partial file. Save refuses to
+// overwrite the file when it was edited outside dhcpmon since it was
+// loaded; reload it and make the change again.
+func (m *Manager) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	current, err := os.ReadFile(m.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	if string(current) != m.content {
+		return fmt.Errorf("hosts file %s changed since it was loaded; reload it and try again", m.filename)
+	}
+	
+	content := m.render()
+	if err := utils.WriteFileAtomic(m.filename, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to save host entries: %w", err)
+	}
+	utils.Infof("Saved %d host entries to %s", len(m.entries), m.filename)
+	
+	// Parse what was written so that line numbers and IDs match the file
+	return m.use(content)
+}
+
+// render builds the file content; the caller holds m.mu
+func (m *Manager) render() string {
+	byLine := make(map[int]models.HostEntry, len(m.entries))
+	var added []models.HostEntry
+	for _, entry := range m.entries {
+		if entry.LineNumber > 0 {
+			byLine[entry.LineNumber] = entry
+		} else {
+			added = append(added, entry)
+		}
+	}
+	
+	var b strings.Builder
+	for i, line := range m.lines {
+		lineNumber := i + 1
+		original, wasEntry := m.original[lineNumber]
+		if !wasEntry {
+			b.WriteString(line + "\n")
+			continue
+		}
+		
+		entry, kept := byLine[lineNumber]
+		switch {
+		case !kept:
+			// Deleted
+		case sameEntry(entry, original):
+			b.WriteString(line + "\n")
+		default:
+			b.WriteString(m.parser.FormatLine(entry) + "\n")
+		}
+	}
+	
+	for _, entry := range added {
+		b.WriteString(m.parser.FormatLine(entry) + "\n")
+	}
+	
+	return b.String()
+}
+
+// sameEntry reports whether two entries would render identically
+func sameEntry(a, b models.HostEntry) bool {
+	return a.IP == b.IP && a.Comment == b.Comment &&
+		strings.Join(a.Names(), " ") == strings.Join(b.Names(), " ")
+}
+
+// GetAll returns all host entries
+func (m *Manager) GetAll() []models.HostEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	entries := make([]models.HostEntry, len(m.entries))
+	copy(entries, m.entries)
+	return entries
+}
+
+// GetByID returns a host entry by ID
+func (m *Manager) GetByID(id string) (*models.HostEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	for _, entry := range m.entries {
+		if entry.ID == id {
+			entryCopy := entry
+			return &entryCopy, nil
+		}
+	}
+	
+	return nil, fmt.Errorf("entry with ID %s not found", id)
+}
+
+// Add adds a new host entry
+func (m *Manager) Add(entry models.HostEntry) error {
+	entry = normalize(entry)
+	if err := entry.Validate(); err != nil {
+		return fmt.Errorf("invalid entry: %w", err)
+	}
+	
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	if err := m.checkConflicts(entry, -1); err != nil {
+		return err
+	}
+	
+	// Generate new ID
+	entry.ID = fmt.Sprintf("entry_%d_%d", time.Now().Unix(), atomic.AddUint64(&entrySeq, 1))
+	entry.LineNumber = 0
+	
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// Update updates an existing host entry
+func (m *Manager) Update(id string, updatedEntry models.HostEntry) error {
+	updatedEntry = normalize(updatedEntry)
+	if err := updatedEntry.Validate(); err != nil {
+		return fmt.Errorf("invalid entry: %w", err)
+	}
+	
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	for i, entry := range m.entries {
+		if entry.ID == id {
+			if err := m.checkConflicts(updatedEntry, i); err != nil {
+				return err
+			}
+			
+			// Preserve original fields
+			updatedEntry.ID = entry.ID
+			updatedEntry.LineNumber = entry.LineNumber
+			
+			m.entries[i] = updatedEntry
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("entry with ID %s not found", id)
+}
+
+// Delete deletes a host entry
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	for i, entry := range m.entries {
+		if entry.ID == id {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return nil
+		}
+	}
+	
+	return fmt.Errorf("entry with ID %s not found", id)
+}
+
+// GetByIP returns entries for a specific IP address
+func (m *Manager) GetByIP(ip net.IP) []models.HostEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	var results []models.HostEntry
+	for _, entry := range m.entries {
+		if entryIP := net.ParseIP(entry.IP); entryIP != nil && entryIP.Equal(ip) {
+			results = append(results, entry)
+		}
+	}
+	
+	return results
+}
+
+// GetByName returns entries that have name as hostname or alias
+func (m *Manager) GetByName(name string) []models.HostEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	var results []models.HostEntry
+	for _, entry := range m.entries {
+		for _, entryName := range entry.Names() {
+			if strings.EqualFold(entryName, name) {
+				results = append(results, entry)
+				break
+			}
+		}
+	}
+	
+	return results
+}
+
+// Validate validates all entries and returns any errors
+func (m *Manager) Validate() []error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	var errors []error
+	for i, entry := range m.entries {
+		if err := entry.Validate(); err != nil {
+			errors = append(errors, fmt.Errorf("entry %d (line %d): %w", i+1, entry.LineNumber, err))
+		}
+		if err := m.checkConflicts(entry, i); err != nil {
+			errors = append(errors, fmt.Errorf("entry %d (line %d): %w", i+1, entry.LineNumber, err))
+		}
+	}
+	
+	return errors
+}
+
+// checkConflicts rejects a name that already points at another address
+// of the same family, or an address/name pair that is already present.
+// The entry at index skip is ignored; the caller holds m.mu.
+func (m *Manager) checkConflicts(entry models.HostEntry, skip int) error {
+	return checkConflicts(m.entries, entry, skip)
+}
+
+// checkConflicts checks entry against entries, ignoring the one at index
+// skip
+func checkConflicts(entries []models.HostEntry, entry models.HostEntry, skip int) error {
+	ip := net.ParseIP(entry.IP)
+	isV4 := ip != nil && ip.To4() != nil
+	
+	for j, existing := range entries {
+		if j == skip {
+			continue
+		}
+		existingIP := net.ParseIP(existing.IP)
+		if existingIP == nil || (existingIP.To4() != nil) != isV4 {
+			continue
+		}
+		
+		for _, name := range entry.Names() {
+			for _, existingName := range existing.Names() {
+				if !strings.EqualFold(name, existingName) {
+					continue
+				}
+				if existingIP.Equal(ip) {
+					return fmt.Errorf("%s %s already exists", entry.IP, name)
+				}
+				return fmt.Errorf("hostname %s already points to %s", name, existing.IP)
+			}
+		}
+	}
+	
+	return nil
+}
+
+// normalize trims whitespace and drops empty aliases
+func normalize(entry models.HostEntry) models.HostEntry {
+	entry.IP = strings.TrimSpace(entry.IP)
+	entry.Name = strings.TrimSpace(entry.Name)
+	entry.Comment = strings.TrimSpace(entry.Comment)
+	
+	var aliases []string
+	for _, alias := range entry.Alias {
+		for _, field := range strings.Fields(alias) {
+			aliases = append(aliases, field)
+		}
+	}
+	entry.Alias = aliases
+	return entry
+}
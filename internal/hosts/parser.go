@@ -2,6 +2,7 @@
 package hosts
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
 	
@@ -20,32 +21,57 @@ func NewParser() *Parser {
 func (p *Parser) ParseHosts(content string) ([]models.HostEntry, error) {
 	var entries []models.HostEntry
 	
-	for _, line := range strings.Split(content, "\n") {
-		line = p.stripComment(strings.TrimSpace(line))
-		if line == "" {
-			continue
+	for i, line := range strings.Split(content, "\n") {
+		if entry := p.parseLine(line, i+1); entry != nil {
+			entries = append(entries, *entry)
 		}
-		
-		fields := strings.Fields(line)
-		if len(fields) < 2 {
-			continue
-		}
-		
-		entry := models.HostEntry{
-			IP:   fields[0],
-			Name: fields[1],
-		}
-		
-		if len(fields) > 2 {
-			entry.Alias = fields[2:]
-		}
-		
-		entries = append(entries, entry)
 	}
 	
 	return entries, nil
 }
 
+// parseLine parses a single hosts file line, returning nil for blank
+// lines, comments and lines without a name
+func (p *Parser) parseLine(line string, lineNumber int) *models.HostEntry {
+	comment := ""
+	if idx := strings.IndexAny(line, "#;"); idx >= 0 {
+		comment = strings.TrimSpace(line[idx+1:])
+	}
+	
+	line = p.stripComment(strings.TrimSpace(line))
+	if line == "" {
+		return nil
+	}
+	
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return nil
+	}
+	
+	entry := &models.HostEntry{
+		ID:         fmt.Sprintf("line_%d", lineNumber),
+		IP:         fields[0],
+		Name:       fields[1],
+		Comment:    comment,
+		LineNumber: lineNumber,
+	}
+	
+	if len(fields) > 2 {
+		entry.Alias = fields[2:]
+	}
+	
+	return entry
+}
+
+// FormatLine renders an entry as a hosts file line
+func (p *Parser) FormatLine(entry models.HostEntry) string {
+	line := entry.IP + "\t" + strings.Join(append([]string{entry.Name}, entry.Alias...), " ")
+	if entry.Comment != "" {
+		line += "\t# " + entry.Comment
+	}
+	return line
+}
+
 // stripComment removes comments from a line
 func (p *Parser) stripComment(line string) string {
 	if idx := strings.IndexAny(line, "#;"); idx >= 0 {
@@ -53,4 +79,3 @@ func (p *Parser) stripComment(line string) string {
 	}
 	return line
 }
-
@@ -0,0 +1,91 @@
+// ===== internal/hosts/renumber.go =====
+package hosts
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// RenumberChange is a hosts entry whose address moves, with its line
+// before and after
+type RenumberChange struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Renumber moves every entry whose address mapIP maps to a new address;
+// mapIP returns nil for addresses it leaves alone. The moved entries are
+// checked against the others as Update checks them. Nothing is changed
+// when there are errors or dryRun is set.
+func (m *Manager) Renumber(mapIP func(net.IP) (net.IP, error), dryRun bool) ([]RenumberChange, []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changes := []RenumberChange{}
+	var errs []string
+	work := make([]models.HostEntry, len(m.entries))
+	copy(work, m.entries)
+	var moved []int
+
+	for i, entry := range work {
+		ip := net.ParseIP(entry.IP)
+		newIP, err := mapIP(ip)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s (%s): %v", entry.IP, entry.Name, err))
+			continue
+		}
+		if newIP == nil || newIP.Equal(ip) {
+			continue
+		}
+
+		work[i].IP = newIP.String()
+		moved = append(moved, i)
+		changes = append(changes, RenumberChange{
+			ID:     entry.ID,
+			Name:   entry.Name,
+			From:   entry.IP,
+			To:     work[i].IP,
+			Before: m.parser.FormatLine(entry),
+			After:  m.parser.FormatLine(work[i]),
+		})
+	}
+
+	for _, i := range moved {
+		if err := checkConflicts(work, work[i], i); err != nil {
+			errs = append(errs, fmt.Sprintf("%s (%s): %v", work[i].IP, work[i].Name, err))
+		}
+	}
+
+	if len(errs) == 0 && !dryRun {
+		m.entries = work
+	}
+	return changes, errs
+}
+
+// Snapshot returns the content of the hosts file, for Restore
+func (m *Manager) Snapshot() ([]byte, error) {
+	content, err := os.ReadFile(m.filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	return content, nil
+}
+
+// Restore writes back content returned by Snapshot and loads it
+func (m *Manager) Restore(content []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := utils.WriteFileAtomic(m.filename, content, 0644); err != nil {
+		return fmt.Errorf("failed to restore hosts file: %w", err)
+	}
+	return m.use(string(content))
+}
@@ -0,0 +1,422 @@
+// ===== internal/ipam/ipam.go =====
+package ipam
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"dhcpmon/internal/dnsmasq"
+	"dhcpmon/pkg/models"
+)
+
+// Address states
+const (
+	StateNetwork   = "network"   // Network address
+	StateBroadcast = "broadcast" // Broadcast address
+	StateGateway   = "gateway"   // Router or DNS server given to clients, or this host
+	StateReserved  = "reserved"  // Static reservation
+	StateLeased    = "leased"    // Dynamic lease
+	StateConflict  = "conflict"  // Reserved for one device, leased to another
+	StateHosts     = "hosts"     // Named in the hosts file only
+	StatePool      = "pool"      // Free, in a dynamic pool
+	StateFree      = "free"      // Free, outside the dynamic pools
+)
+
+// MaxGridSize is the largest subnet whose addresses are listed one by one
+const MaxGridSize = 4096
+
+// maxSuggestScan bounds the search for a free address in large subnets
+const maxSuggestScan = 1 << 16
+
+// Input is the state of the address space
+type Input struct {
+	Subnets []dnsmasq.Subnet
+	Static  []models.StaticDHCPEntry
+	Leases  []models.DHCPLease
+	Hosts   []models.HostEntry
+	Exclude []net.IP // Addresses in use outside DHCP, such as this host's
+}
+
+// Address is one address of a subnet grid
+type Address struct {
+	IP       string `json:"ip"`
+	State    string `json:"state"`
+	MAC      string `json:"mac,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	InPool   bool   `json:"inPool,omitempty"`
+}
+
+// PoolUsage is the utilization of one dynamic pool
+type PoolUsage struct {
+	Range       string  `json:"range"`
+	Mode        string  `json:"mode,omitempty"`
+	SetTag      string  `json:"setTag,omitempty"`
+	LeaseTime   string  `json:"leaseTime,omitempty"`
+	Size        uint64  `json:"size"`
+	Leased      uint64  `json:"leased"`
+	Reserved    uint64  `json:"reserved"` // Reservations inside the pool
+	Free        uint64  `json:"free"`
+	Utilization float64 `json:"utilization"` // Percent of the pool in use
+}
+
+// SubnetUsage is the utilization of one subnet
+type SubnetUsage struct {
+	Network     string      `json:"network"`
+	Family      string      `json:"family"`
+	Interface   string      `json:"interface,omitempty"`
+	Domain      string      `json:"domain,omitempty"`
+	Tags        []string    `json:"tags,omitempty"`
+	Size        uint64      `json:"size"`     // Usable addresses; 0 for IPv6
+	PoolSize    uint64      `json:"poolSize"` // Dynamic addresses
+	Leased      uint64      `json:"leased"`
+	Reserved    uint64      `json:"reserved"`
+	Utilization float64     `json:"utilization"` // Percent of the pools in use
+	Pools       []PoolUsage `json:"pools"`
+	Addresses   []Address   `json:"addresses,omitempty"`
+}
+
+// Analyze reports the utilization of every subnet. The address grid is
+// filled in for IPv4 subnets of up to MaxGridSize addresses when grid is
+// set.
+func Analyze(in Input, grid bool) []SubnetUsage {
+	usage := make([]SubnetUsage, 0, len(in.Subnets))
+	for _, subnet := range in.Subnets {
+		usage = append(usage, analyzeSubnet(in, subnet, grid))
+	}
+	return usage
+}
+
+func analyzeSubnet(in Input, subnet dnsmasq.Subnet, grid bool) SubnetUsage {
+	u := SubnetUsage{
+		Network:   subnet.Network.String(),
+		Family:    subnet.Family,
+		Interface: subnet.Interface,
+		Domain:    subnet.Domain,
+		Tags:      subnet.Tags,
+		Size:      usableSize(subnet.Network),
+		PoolSize:  subnet.Size,
+		Pools:     make([]PoolUsage, 0, len(subnet.Pools)),
+	}
+
+	reserved := reservedIPs(in.Static, subnet)
+	leased := leasedIPs(in.Leases, subnet)
+	u.Reserved = uint64(len(reserved))
+	u.Leased = uint64(len(leased))
+
+	var used uint64
+	for _, pool := range subnet.Pools {
+		p := PoolUsage{
+			Range:     pool.String(),
+			Mode:      pool.Mode,
+			SetTag:    pool.SetTag,
+			LeaseTime: pool.LeaseTime,
+			Size:      pool.Size,
+		}
+		if pool.End != nil {
+			inUse := make(map[string]bool)
+			for ip := range leased {
+				if pool.Contains(net.ParseIP(ip)) {
+					p.Leased++
+					inUse[ip] = true
+				}
+			}
+			for ip := range reserved {
+				if pool.Contains(net.ParseIP(ip)) {
+					p.Reserved++
+					inUse[ip] = true
+				}
+			}
+			n := uint64(len(inUse))
+			if n < p.Size {
+				p.Free = p.Size - n
+			}
+			p.Utilization = percent(n, p.Size)
+			used += n
+		}
+		u.Pools = append(u.Pools, p)
+	}
+	u.Utilization = percent(used, u.PoolSize)
+
+	if grid && subnet.Family == "ipv4" && u.Size+2 <= MaxGridSize {
+		u.Addresses = buildGrid(in, subnet, reserved, leased)
+	}
+	return u
+}
+
+// buildGrid lists every address of an IPv4 subnet with its state
+func buildGrid(in Input, subnet dnsmasq.Subnet, reserved map[string]models.StaticDHCPEntry, leased map[string]models.DHCPLease) []Address {
+	first, last := bounds(subnet.Network)
+	gateways := gatewayIPs(in, subnet)
+	hosts := hostNames(in.Hosts)
+
+	addresses := make([]Address, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		ip := uint32ToIP(n)
+		key := ip.String()
+		a := Address{IP: key, InPool: subnet.InPool(ip)}
+
+		entry, isReserved := reserved[key]
+		lease, isLeased := leased[key]
+		switch {
+		case n == first && last-first > 1:
+			a.State = StateNetwork
+		case n == last && last-first > 1:
+			a.State = StateBroadcast
+		case isReserved && isLeased && !sameMAC(entry.MAC, lease.MAC):
+			a.State = StateConflict
+			a.MAC = formatMAC(lease.MAC)
+			a.Hostname = entry.Hostname
+		case isReserved:
+			a.State = StateReserved
+			a.MAC = formatMAC(entry.MAC)
+			a.Hostname = entry.Hostname
+		case isLeased:
+			a.State = StateLeased
+			a.MAC = formatMAC(lease.MAC)
+			a.Hostname = lease.Name
+		case gateways[key]:
+			a.State = StateGateway
+		case hosts[key] != "":
+			a.State = StateHosts
+		case a.InPool:
+			a.State = StatePool
+		default:
+			a.State = StateFree
+		}
+		if a.Hostname == "" || a.Hostname == "*" {
+			a.Hostname = hosts[key]
+		}
+		addresses = append(addresses, a)
+	}
+	return addresses
+}
+
+// Hint selects the subnet to suggest an address in
+type Hint struct {
+	Network string // CIDR of the subnet
+	Tag     string // A tag set or required by the subnet's ranges; ignored when no subnet has it
+	Near    net.IP // An address in the subnet
+}
+
+// Suggest returns the lowest free IPv4 address outside the dynamic pools
+// of the subnet selected by hint, or of the first IPv4 subnet. Reserved,
+// leased, hosts file, gateway and excluded addresses are never returned.
+func Suggest(in Input, hint Hint) (net.IP, *dnsmasq.Subnet, error) {
+	subnet, err := selectSubnet(in.Subnets, hint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	used := make(map[string]bool)
+	for _, entry := range in.Static {
+		// Disabled reservations count as used too, so that enabling one
+		// again does not cause a conflict
+		if entry.IP != nil {
+			used[entry.IP.String()] = true
+		}
+	}
+	for _, lease := range in.Leases {
+		if lease.IP != nil {
+			used[lease.IP.String()] = true
+		}
+	}
+	for ip := range hostNames(in.Hosts) {
+		used[ip] = true
+	}
+	for ip := range gatewayIPs(in, *subnet) {
+		used[ip] = true
+	}
+
+	first, last := bounds(subnet.Network)
+	if last-first > 1 {
+		first, last = first+1, last-1
+	}
+	if last-first >= maxSuggestScan {
+		last = first + maxSuggestScan - 1
+	}
+	for n := first; n <= last; n++ {
+		ip := uint32ToIP(n)
+		if !used[ip.String()] && !subnet.InPool(ip) {
+			return ip, subnet, nil
+		}
+		if n == math.MaxUint32 {
+			break
+		}
+	}
+	return nil, subnet, fmt.Errorf("no free address outside the dynamic pools of %s", subnet.Network)
+}
+
+// selectSubnet picks the IPv4 subnet matching hint. Without a hint,
+// subnets serving clients without tags are preferred over those only
+// serving tagged ones, and subnets whose netmask is known over assumed
+// ones.
+func selectSubnet(subnets []dnsmasq.Subnet, hint Hint) (*dnsmasq.Subnet, error) {
+	if hint.Network == "" && hint.Tag == "" && hint.Near == nil {
+		for _, untagged := range []bool{true, false} {
+			for i := range subnets {
+				if subnets[i].Family == "ipv4" && subnets[i].MaskSource != dnsmasq.MaskAssumed &&
+					(!untagged || hasUntaggedPool(subnets[i])) {
+					return &subnets[i], nil
+				}
+			}
+		}
+	}
+
+	for i := range subnets {
+		subnet := &subnets[i]
+		if subnet.Family != "ipv4" {
+			continue
+		}
+		switch {
+		case hint.Network != "":
+			if subnet.Network.String() == hint.Network {
+				return subnet, nil
+			}
+		case hint.Near != nil:
+			if subnet.Contains(hint.Near) {
+				return subnet, nil
+			}
+		case hint.Tag != "":
+			for _, tag := range subnet.Tags {
+				if tag == hint.Tag {
+					return subnet, nil
+				}
+			}
+		default:
+			return subnet, nil
+		}
+	}
+
+	switch {
+	case hint.Network != "":
+		return nil, fmt.Errorf("no IPv4 subnet %s in the dnsmasq configuration", hint.Network)
+	case hint.Near != nil:
+		return nil, fmt.Errorf("no IPv4 subnet contains %s", hint.Near)
+	case hint.Tag != "":
+		// A tag that only selects options, not a subnet, leaves the
+		// choice to the default
+		return selectSubnet(subnets, Hint{})
+	}
+	return nil, fmt.Errorf("no IPv4 dhcp-range in the dnsmasq configuration")
+}
+
+// hasUntaggedPool reports whether a subnet has a range that requires no tag
+func hasUntaggedPool(subnet dnsmasq.Subnet) bool {
+	for _, pool := range subnet.Pools {
+		if len(pool.Tags) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedIPs returns the enabled reservations in a subnet by address
+func reservedIPs(static []models.StaticDHCPEntry, subnet dnsmasq.Subnet) map[string]models.StaticDHCPEntry {
+	reserved := make(map[string]models.StaticDHCPEntry)
+	for _, entry := range static {
+		if entry.Enabled && entry.IP != nil && subnet.Contains(entry.IP) {
+			reserved[entry.IP.String()] = entry
+		}
+	}
+	return reserved
+}
+
+// leasedIPs returns the current dynamic leases in a subnet by address
+func leasedIPs(leases []models.DHCPLease, subnet dnsmasq.Subnet) map[string]models.DHCPLease {
+	now := time.Now()
+	leased := make(map[string]models.DHCPLease)
+	for _, lease := range leases {
+		if lease.Static || lease.IP == nil || !subnet.Contains(lease.IP) {
+			continue
+		}
+		if !lease.Expire.IsZero() && lease.Expire.Before(now) {
+			continue
+		}
+		leased[lease.IP.String()] = lease
+	}
+	return leased
+}
+
+// gatewayIPs returns the router and DNS addresses given to the clients of
+// a subnet, and the excluded addresses
+func gatewayIPs(in Input, subnet dnsmasq.Subnet) map[string]bool {
+	gateways := make(map[string]bool)
+	for _, values := range [][]string{subnet.Router, subnet.DNS, subnet.NTP} {
+		for _, v := range values {
+			if ip := net.ParseIP(v); ip != nil && subnet.Contains(ip) {
+				gateways[ip.String()] = true
+			}
+		}
+	}
+	for _, ip := range in.Exclude {
+		if subnet.Contains(ip) {
+			gateways[ip.String()] = true
+		}
+	}
+	return gateways
+}
+
+// hostNames returns the first hosts file name of each address
+func hostNames(hosts []models.HostEntry) map[string]string {
+	names := make(map[string]string)
+	for _, host := range hosts {
+		ip := net.ParseIP(host.IP)
+		if ip == nil {
+			continue
+		}
+		if _, ok := names[ip.String()]; !ok {
+			names[ip.String()] = host.Name
+		}
+	}
+	return names
+}
+
+// usableSize returns the number of host addresses in an IPv4 network,
+// or 0 for IPv6 networks, which are too large to count meaningfully
+func usableSize(network *net.IPNet) uint64 {
+	ones, bits := network.Mask.Size()
+	if bits != 32 {
+		return 0
+	}
+	size := uint64(1) << uint(bits-ones)
+	if size > 2 {
+		size -= 2
+	}
+	return size
+}
+
+// bounds returns the first and last address of an IPv4 network
+func bounds(network *net.IPNet) (uint32, uint32) {
+	ones, _ := network.Mask.Size()
+	mask := uint32(0)
+	if ones > 0 {
+		mask = ^uint32(0) << uint(32-ones)
+	}
+	first := binary.BigEndian.Uint32(network.IP.To4()) & mask
+	return first, first | ^mask
+}
+
+func uint32ToIP(n uint32) net.IP {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip
+}
+
+func percent(n, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(total)*1000) / 10
+}
+
+func sameMAC(a, b net.HardwareAddr) bool {
+	return strings.EqualFold(a.String(), b.String())
+}
+
+func formatMAC(mac net.HardwareAddr) string {
+	return strings.ToUpper(mac.String())
+}
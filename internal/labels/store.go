@@ -0,0 +1,270 @@
+// ===== internal/labels/store.go =====
+package labels
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// prefix is a parsed label key: the first bits of a MAC address
+type prefix struct {
+	bits  uint8
+	value uint64
+}
+
+// Store holds device labels, persisted as JSON. Labels are looked up by
+// the longest matching key, so a label on a full MAC wins over a label on
+// its vendor prefix.
+type Store struct {
+	file   string
+	labels map[prefix]models.Label
+	bits   []uint8 // Key lengths present, longest first
+	mu     sync.RWMutex
+}
+
+// NewStore creates a label store backed by file; an empty file keeps
+// labels in memory only
+func NewStore(file string) *Store {
+	return &Store{
+		file:   file,
+		labels: make(map[prefix]models.Label),
+	}
+}
+
+// Load reads the labels from disk; a missing file is an empty store
+func (s *Store) Load() error {
+	if s.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	var stored []models.Label
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", s.file, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.labels = make(map[prefix]models.Label, len(stored))
+	s.bits = nil
+	for _, label := range stored {
+		key, normalized, err := parseKey(label.Key)
+		if err != nil {
+			utils.Warnf("Warning: skipping label %q: %v", label.Key, err)
+			continue
+		}
+		label.Key = normalized
+		s.insert(key, label)
+	}
+
+	utils.Infof("Loaded %d device labels", len(s.labels))
+	return nil
+}
+
+// List returns every label ordered by key
+func (s *Store) List() []models.Label {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.Label, 0, len(s.labels))
+	for _, label := range s.labels {
+		result = append(result, label)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// Set adds or replaces the label for label.Key and saves the store
+func (s *Store) Set(label models.Label) (models.Label, error) {
+	key, normalized, err := parseKey(label.Key)
+	if err != nil {
+		return label, err
+	}
+	label.Key = normalized
+	label.Updated = time.Now()
+
+	for _, field := range []*string{&label.Name, &label.Type, &label.Owner, &label.Location, &label.Notes, &label.Vendor} {
+		*field = strings.TrimSpace(*field)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.labels[key]
+	s.insert(key, label)
+	if err := s.save(); err != nil {
+		// Keep memory in step with the file
+		if existed {
+			s.labels[key] = previous
+		} else {
+			delete(s.labels, key)
+		}
+		return label, err
+	}
+	return label, nil
+}
+
+// Delete removes the label for key and saves the store
+func (s *Store) Delete(keyStr string) error {
+	key, normalized, err := parseKey(keyStr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.labels[key]
+	if !ok {
+		return fmt.Errorf("no label for %s", normalized)
+	}
+	delete(s.labels, key)
+	if err := s.save(); err != nil {
+		s.labels[key] = previous
+		return err
+	}
+	return nil
+}
+
+// Lookup returns the most specific label matching mac
+func (s *Store) Lookup(mac string) (models.Label, bool) {
+	key, _, err := parseKey(mac)
+	if err != nil || key.bits != 48 {
+		return models.Label{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, bits := range s.bits {
+		if label, ok := s.labels[prefix{bits: bits, value: key.value >> (48 - uint(bits))}]; ok {
+			return label, true
+		}
+	}
+	return models.Label{}, false
+}
+
+// Apply returns info with the vendor overridden by a matching label,
+// along with the label itself. The OUI entry is copied so the shared
+// vendor database entry is never modified.
+func (s *Store) Apply(mac string, info *models.OUIEntry) (*models.OUIEntry, *models.Label) {
+	label, ok := s.Lookup(mac)
+	if !ok {
+		return info, nil
+	}
+
+	if label.Vendor != "" {
+		override := models.OUIEntry{}
+		if info != nil {
+			override = *info
+		}
+		override.Company = label.Vendor
+		info = &override
+	}
+	return info, &label
+}
+
+// insert stores a label; the caller holds s.mu
+func (s *Store) insert(key prefix, label models.Label) {
+	s.labels[key] = label
+	for _, bits := range s.bits {
+		if bits == key.bits {
+			return
+		}
+	}
+	s.bits = append(s.bits, key.bits)
+	sort.Slice(s.bits, func(i, j int) bool { return s.bits[i] > s.bits[j] })
+}
+
+// save writes the labels atomically; the caller holds s.mu
+func (s *Store) save() error {
+	if s.file == "" {
+		return nil
+	}
+
+	stored := make([]models.Label, 0, len(s.labels))
+	for _, label := range s.labels {
+		stored = append(stored, label)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Key < stored[j].Key })
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(s.file, data, 0644); err != nil {
+		return fmt.Errorf("failed to save labels: %w", err)
+	}
+	return nil
+}
+
+// parseKey parses a label key: a MAC address, or a prefix of 6 to 12 hex
+// digits with an optional /bits length. It returns the canonical form of
+// the key (upper case, colon separated).
+func parseKey(keyStr string) (prefix, string, error) {
+	keyStr = strings.TrimSpace(keyStr)
+	addr, maskStr, hasMask := strings.Cut(keyStr, "/")
+
+	var key prefix
+	digits := 0
+	for _, r := range addr {
+		if r == ':' || r == '-' || r == '.' {
+			continue
+		}
+		nibble, err := strconv.ParseUint(string(r), 16, 8)
+		if err != nil {
+			return prefix{}, "", fmt.Errorf("invalid MAC address or prefix %q", keyStr)
+		}
+		key.value = key.value<<4 | nibble
+		digits++
+	}
+	if digits < 6 || digits > 12 {
+		return prefix{}, "", fmt.Errorf("invalid MAC address or prefix %q: need 6 to 12 hex digits", keyStr)
+	}
+	key.bits = uint8(digits * 4)
+
+	if hasMask {
+		mask, err := strconv.Atoi(maskStr)
+		if err != nil || mask < 24 || mask > int(key.bits) {
+			return prefix{}, "", fmt.Errorf("invalid prefix length in %q", keyStr)
+		}
+		key.value >>= uint(int(key.bits) - mask)
+		key.bits = uint8(mask)
+	}
+
+	// Canonical form: the significant hex digits in colon-separated pairs
+	n := (int(key.bits) + 3) / 4
+	hex := fmt.Sprintf("%0*X", n, key.value<<(uint(n*4)-uint(key.bits)))
+	var parts []string
+	for i := 0; i < len(hex); i += 2 {
+		end := i + 2
+		if end > len(hex) {
+			end = len(hex)
+		}
+		parts = append(parts, hex[i:end])
+	}
+	normalized := strings.Join(parts, ":")
+	if key.bits%4 != 0 {
+		normalized += "/" + strconv.Itoa(int(key.bits))
+	}
+
+	return key, normalized, nil
+}
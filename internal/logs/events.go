@@ -0,0 +1,49 @@
+// ===== internal/logs/events.go =====
+package logs
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"dhcpmon/pkg/models"
+)
+
+var (
+	// dhcpEventRe matches dnsmasq DHCP transaction names such as DHCPACK(eth0)
+	dhcpEventRe = regexp.MustCompile(`\b(DHCP[A-Z]+6?)(\([^)]*\))?`)
+	macRe       = regexp.MustCompile(`\b([0-9A-Fa-f]{2}[:-]){5}[0-9A-Fa-f]{2}\b`)
+	ipv4Re      = regexp.MustCompile(`\b(\d{1,3}\.){3}\d{1,3}\b`)
+)
+
+// ExtractEvent fills the Event, MAC and IP fields of a log entry from
+// dnsmasq DHCP log lines such as:
+//
+//	dnsmasq-dhcp[812]: DHCPACK(eth0) 192.168.1.20 aa:bb:cc:dd:ee:ff laptop
+//
+// Lines that are not DHCP transactions are left untouched.
+func ExtractEvent(entry *models.LogEntry) {
+	match := dhcpEventRe.FindStringSubmatchIndex(entry.Message)
+	if match == nil {
+		return
+	}
+
+	entry.Event = entry.Message[match[2]:match[3]]
+
+	// Addresses are only looked for after the event name so that
+	// numbers in the syslog prefix are not mistaken for them
+	rest := entry.Message[match[1]:]
+
+	if mac := macRe.FindString(rest); mac != "" {
+		if hw, err := net.ParseMAC(mac); err == nil {
+			entry.MAC = strings.ToUpper(hw.String())
+		}
+	}
+
+	for _, candidate := range ipv4Re.FindAllString(rest, -1) {
+		if ip := net.ParseIP(candidate); ip != nil {
+			entry.IP = ip.String()
+			break
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// ===== internal/logs/journal.go =====
+package logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+const (
+	// journalCursorFile holds the last ingested journal cursor inside LogDir
+	journalCursorFile = "journal.cursor"
+
+	// cursorSaveInterval bounds how often the cursor is written to disk
+	cursorSaveInterval = 5 * time.Second
+
+	journalMinBackoff = time.Second
+	journalMaxBackoff = time.Minute
+)
+
+// JournalOutput represents systemd journal output
+type JournalOutput struct {
+	Cursor    string          `json:"__CURSOR"`
+	Timestamp string          `json:"__REALTIME_TIMESTAMP"`
+	Message   json.RawMessage `json:"MESSAGE"`
+	Transport string          `json:"_TRANSPORT"`
+}
+
+// Text returns the journal message. journald encodes messages that are
+// not valid UTF-8 as an array of bytes instead of a string.
+func (j *JournalOutput) Text() string {
+	var text string
+	if err := json.Unmarshal(j.Message, &text); err == nil {
+		return text
+	}
+
+	var raw []byte
+	var ints []int
+	if err := json.Unmarshal(j.Message, &ints); err == nil {
+		for _, b := range ints {
+			raw = append(raw, byte(b))
+		}
+		return strings.ToValidUTF8(string(raw), "?")
+	}
+
+	return ""
+}
+
+// followJournal runs journalctl --follow for the configured unit and
+// feeds its output into the log store, restarting it if it exits
+func (m *Manager) followJournal() {
+	backoff := journalMinBackoff
+	cursor := m.loadJournalCursor()
+
+	for {
+		started := time.Now()
+		var err error
+		cursor, err = m.readJournal(cursor)
+		if err != nil {
+			utils.Infof("journalctl for %s exited: %v", m.cfg.SystemdUnit, err)
+		}
+
+		// A reader that ran for a while was healthy; start over quickly
+		if time.Since(started) > journalMaxBackoff {
+			backoff = journalMinBackoff
+		}
+
+		select {
+		case <-m.stopCh:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > journalMaxBackoff {
+			backoff = journalMaxBackoff
+		}
+	}
+}
+
+// readJournal follows the journal from cursor until journalctl exits or
+// the manager stops, returning the last cursor seen
+func (m *Manager) readJournal(cursor string) (string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-m.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	args := []string{
+		"--unit=" + m.cfg.SystemdUnit,
+		"--output=json",
+		"--follow",
+		"--no-pager",
+	}
+	if cursor != "" {
+		args = append(args, "--after-cursor="+cursor)
+	} else if m.cfg.LogMaxAge > 0 {
+		// First run: backfill as much history as the store would retain
+		since := time.Now().Add(-m.cfg.LogMaxAge).Format("2006-01-02 15:04:05")
+		args = append(args, "--since="+since)
+	} else {
+		args = append(args, "--lines=all")
+	}
+
+	cmd := exec.CommandContext(ctx, m.cfg.Journalctl, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return cursor, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	utils.Infof("Following journal: %s %v", m.cfg.Journalctl, args)
+	if err := cmd.Start(); err != nil {
+		return cursor, fmt.Errorf("failed to start journalctl: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lastSave := time.Now()
+	saved := cursor
+
+	for scanner.Scan() {
+		var journalEntry JournalOutput
+		if err := json.Unmarshal(scanner.Bytes(), &journalEntry); err != nil {
+			continue
+		}
+
+		if entry := journalEntry.toLogEntry(); entry != nil {
+			m.addLogEntry(entry)
+		}
+
+		if journalEntry.Cursor != "" {
+			cursor = journalEntry.Cursor
+		}
+		if cursor != saved && time.Since(lastSave) > cursorSaveInterval {
+			m.saveJournalCursor(cursor)
+			saved = cursor
+			lastSave = time.Now()
+		}
+	}
+
+	if cursor != saved {
+		m.saveJournalCursor(cursor)
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return cursor, nil
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	return cursor, err
+}
+
+// toLogEntry converts a journal record to a log entry
+func (j *JournalOutput) toLogEntry() *models.LogEntry {
+	timestamp, err := strconv.ParseInt(j.Timestamp, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &models.LogEntry{
+		Timestamp: time.UnixMicro(timestamp),
+		UnixTime:  timestamp / 1000,
+		Channel:   j.Transport,
+		Message:   j.Text(),
+	}
+}
+
+// journalCursorPath returns where the cursor is persisted, if anywhere
+func (m *Manager) journalCursorPath() string {
+	if m.cfg.LogDir == "" {
+		return ""
+	}
+	return filepath.Join(m.cfg.LogDir, journalCursorFile)
+}
+
+// loadJournalCursor reads the cursor saved by a previous run
+func (m *Manager) loadJournalCursor() string {
+	path := m.journalCursorPath()
+	if path == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			utils.Warnf("Warning: failed to read journal cursor: %v", err)
+		}
+		return ""
+	}
+
+	cursor := strings.TrimSpace(string(data))
+	if cursor != "" {
+		utils.Infof("Resuming journal after saved cursor")
+	}
+	return cursor
+}
+
+// saveJournalCursor atomically persists the cursor
+func (m *Manager) saveJournalCursor(cursor string) {
+	path := m.journalCursorPath()
+	if path == "" {
+		return
+	}
+
+	if err := utils.WriteFileAtomic(path, []byte(cursor+"\n"), 0644); err != nil {
+		utils.Warnf("Warning: failed to save journal cursor: %v", err)
+	}
+}
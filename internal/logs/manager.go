@@ -2,54 +2,61 @@
 package logs
 
 import (
-	"bufio"
-	"container/list"
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
-	"os/exec"
-	"strings"
-	"strconv"
 	"sync"
 	"time"
 	
 	"dhcpmon/internal/config"
 	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
 )
 
-const maxLogEntries = 100
-
-// JournalOutput represents systemd journal output
-type JournalOutput struct {
-	Cursor    string `json:"__CURSOR"`
-	Timestamp string `json:"__REALTIME_TIMESTAMP"`
-	Message   string `json:"MESSAGE"`
-	Transport string `json:"_TRANSPORT"`
-}
+// pruneInterval is how often log retention limits are enforced
+const pruneInterval = time.Hour
 
 // Manager handles log collection and storage
 type Manager struct {
 	cfg    *config.Config
-	logs   *list.List
+	store  *Store
 	mu     sync.RWMutex
 	stopCh chan struct{}
+	
+	// Called with every stored entry, e.g. to parse DHCP details
+	subscribers []func(models.LogEntry)
 }
 
 // NewManager creates a new log manager
 func NewManager(cfg *config.Config) *Manager {
 	return &Manager{
 		cfg:    cfg,
-		logs:   list.New(),
 		stopCh: make(chan struct{}),
 	}
 }
 
 // Start begins log collection
 func (m *Manager) Start() error {
-	if !m.cfg.SystemD {
-		// Start dnsmasq and collect its logs
-		go m.startDNSMasq()
+	store, err := NewStore(m.cfg.LogDir, m.cfg.LogMaxSize, m.cfg.LogSegmentSize, m.cfg.LogMaxAge)
+	if err != nil {
+		utils.Warnf("Warning: falling back to in-memory log store: %v", err)
+		store, _ = NewStore("", 0, 0, 0)
+	}
+
+	m.mu.Lock()
+	m.store = store
+	m.mu.Unlock()
+
+	go m.pruneLoop()
+
+	if m.cfg.SyslogListen != "" {
+		// Receive logs from dnsmasq instances on other hosts
+		if err := m.startSyslog(); err != nil {
+			utils.Warnf("Warning: syslog receiver not started: %v", err)
+		}
+	}
+
+	if m.cfg.SystemD {
+		// Follow the dnsmasq unit in the systemd journal; otherwise the
+		// supervised dnsmasq output arrives through Ingest
+		go m.followJournal()
 	}
 	return nil
 }
@@ -57,129 +64,91 @@ func (m *Manager) Start() error {
 // Stop stops log collection
 func (m *Manager) Stop() {
 	close(m.stopCh)
+
+	if store := m.getStore(); store != nil {
+		store.Close()
+	}
 }
 
-// GetLogs returns current log entries
-func (m *Manager) GetLogs() []models.LogEntry {
+// getStore returns the log store once Start has opened it
+func (m *Manager) getStore() *Store {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	
-	var entries []models.LogEntry
-	for e := m.logs.Front(); e != nil; e = e.Next() {
-		entries = append(entries, *(e.Value.(*models.LogEntry)))
-	}
-	
-	return entries
+	return m.store
 }
 
-// GetSystemdLogs retrieves logs from systemd journal
-func (m *Manager) GetSystemdLogs() ([]models.LogEntry, error) {
-	cmd := exec.Command("/bin/journalctl",
-		"--unit=dnsmasq.service",
-		"--output=json")
-	
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get systemd logs: %w", err)
-	}
-	
-	var entries []models.LogEntry
-	for _, line := range strings.Split(string(output), "\n") {
-		if line == "" {
-			continue
-		}
-		
-		var journalEntry JournalOutput
-		if err := json.Unmarshal([]byte(line), &journalEntry); err != nil {
-			continue
-		}
-		
-		timestamp, err := strconv.ParseInt(journalEntry.Timestamp, 10, 64)
-		if err != nil {
-			continue
-		}
-		
-		entry := models.LogEntry{
-			Timestamp: time.Unix(timestamp/1000000, timestamp%1000000),
-			UnixTime:  timestamp / 1000,
-			Channel:   journalEntry.Transport,
-			Message:   journalEntry.Message,
+// pruneLoop periodically enforces the retention limits of the store
+func (m *Manager) pruneLoop() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if store := m.getStore(); store != nil {
+				store.Prune()
+			}
+		case <-m.stopCh:
+			return
 		}
-		
-		entries = append(entries, entry)
 	}
-	
-	return entries, nil
 }
 
-// addLogEntry adds a new log entry to the collection
-func (m *Manager) addLogEntry(entry *models.LogEntry) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	// Remove old entries if we exceed the limit
-	if m.logs.Len() >= maxLogEntries {
-		m.logs.Remove(m.logs.Front())
+// GetLogs returns the most recent log entries
+func (m *Manager) GetLogs() []models.LogEntry {
+	result, err := m.QueryLogs(Query{})
+	if err != nil {
+		utils.Errorf("Failed to query logs: %v", err)
+		return nil
 	}
-	
-	m.logs.PushBack(entry)
+	return result.Entries
 }
 
-// startDNSMasq starts dnsmasq and collects its output
-func (m *Manager) startDNSMasq() {
-	cmdArgs := []string{
-		m.cfg.DNSMasq,
-		"--keep-in-foreground",
-		"--conf-dir=/etc/dnsmasq.d,*conf",
+// QueryLogs searches the log store
+func (m *Manager) QueryLogs(q Query) (Result, error) {
+	store := m.getStore()
+	if store == nil {
+		return Paginate(nil, q), nil
 	}
-	
-	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
-	
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		log.Printf("Failed to create stdout pipe: %v", err)
-		return
-	}
-	
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		log.Printf("Failed to create stderr pipe: %v", err)
+	return store.Query(q)
+}
+
+// addLogEntry adds a new log entry to the store
+func (m *Manager) addLogEntry(entry *models.LogEntry) {
+	ExtractEvent(entry)
+
+	store := m.getStore()
+	if store == nil {
 		return
 	}
-	
-	log.Printf("Starting dnsmasq: %v", cmdArgs)
-	if err := cmd.Start(); err != nil {
-		log.Printf("Failed to start dnsmasq: %v", err)
-		return
+
+	if err := store.Append(entry); err != nil {
+		utils.Errorf("Failed to store log entry: %v", err)
 	}
-	
-	// Start log scanners
-	go m.scanLogs(stdout, "stdout")
-	go m.scanLogs(stderr, "stderr")
-	
-	// Wait for command to finish
-	if err := cmd.Wait(); err != nil {
-		log.Printf("dnsmasq exited with error: %v", err)
+
+	m.mu.RLock()
+	subscribers := m.subscribers
+	m.mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(*entry)
 	}
 }
 
-// scanLogs scans output from a reader and creates log entries
-func (m *Manager) scanLogs(reader io.Reader, channel string) {
-	scanner := bufio.NewScanner(reader)
-	
-	for scanner.Scan() {
-		entry := &models.LogEntry{
-			Timestamp: time.Now(),
-			UnixTime:  time.Now().Unix(),
-			Channel:   channel,
-			Message:   scanner.Text(),
-		}
-		
-		m.addLogEntry(entry)
-	}
-	
-	if err := scanner.Err(); err != nil {
-		log.Printf("Error scanning %s: %v", channel, err)
-	}
+// Subscribe registers fn to be called with every log entry as it is
+// stored. Subscribers run on the ingesting goroutine and must not block.
+func (m *Manager) Subscribe(fn func(models.LogEntry)) {
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, fn)
+	m.mu.Unlock()
 }
 
+// Ingest stores a line of dnsmasq output received on channel
+func (m *Manager) Ingest(channel, line string) {
+	now := time.Now()
+	m.addLogEntry(&models.LogEntry{
+		Timestamp: now,
+		UnixTime:  now.UnixMilli(),
+		Channel:   channel,
+		Message:   line,
+	})
+}
@@ -0,0 +1,506 @@
+// ===== internal/logs/store.go =====
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+const (
+	// memoryLogEntries caps the store when no log directory is configured
+	memoryLogEntries = 1000
+
+	// DefaultQueryLimit is used when a query does not specify a limit
+	DefaultQueryLimit = 500
+
+	// MaxQueryLimit bounds the number of entries returned by a single query
+	MaxQueryLimit = 5000
+
+	segmentPrefix = "log-"
+	segmentSuffix = ".jsonl"
+)
+
+// Query describes a log search
+type Query struct {
+	From    time.Time // Only entries at or after this time
+	To      time.Time // Only entries before this time
+	Channel string    // Exact channel match
+	Host    string    // Source host of remotely received entries
+	Text    string    // Case-insensitive substring of the message
+	MAC     string    // MAC address seen in the entry
+	IP      string    // IP address seen in the entry
+	After   uint64    // Only entries with an ID greater than this cursor
+	Before  uint64    // Only entries with an ID lower than this cursor
+	Limit   int       // Maximum number of entries to return
+}
+
+// Result is a page of log entries in ascending ID order
+type Result struct {
+	Entries []models.LogEntry `json:"data"`
+	Next    uint64            `json:"next"` // Cursor to poll for newer entries
+	Prev    uint64            `json:"prev"` // Cursor to page back to older entries
+	More    bool              `json:"more"` // More entries exist in the paging direction
+}
+
+// Match reports whether an entry satisfies the query filters
+func (q *Query) Match(entry *models.LogEntry) bool {
+	if !q.From.IsZero() && entry.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !entry.Timestamp.Before(q.To) {
+		return false
+	}
+	if q.Channel != "" && !strings.EqualFold(entry.Channel, q.Channel) {
+		return false
+	}
+	if q.Host != "" && !strings.EqualFold(entry.Host, q.Host) {
+		return false
+	}
+	if q.MAC != "" && !q.matchMAC(entry) {
+		return false
+	}
+	if q.IP != "" && entry.IP != q.IP && !strings.Contains(entry.Message, q.IP) {
+		return false
+	}
+	if q.Text != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(q.Text)) {
+		return false
+	}
+	return true
+}
+
+// matchMAC compares MAC addresses independently of case and separator
+func (q *Query) matchMAC(entry *models.LogEntry) bool {
+	want := strings.ToLower(strings.NewReplacer(":", "", "-", "").Replace(q.MAC))
+	have := strings.ToLower(strings.NewReplacer(":", "", "-", "").Replace(entry.MAC))
+	if have != "" && have == want {
+		return true
+	}
+	return strings.Contains(strings.ToLower(entry.Message), strings.ToLower(q.MAC))
+}
+
+// limit returns the effective page size
+func (q *Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultQueryLimit
+	}
+	if q.Limit > MaxQueryLimit {
+		return MaxQueryLimit
+	}
+	return q.Limit
+}
+
+// inIDRange reports whether an ID lies between the query cursors
+func (q *Query) inIDRange(id uint64) bool {
+	if q.After > 0 && id <= q.After {
+		return false
+	}
+	if q.Before > 0 && id >= q.Before {
+		return false
+	}
+	return true
+}
+
+// segment describes one append-only JSON-lines file of the store
+type segment struct {
+	path    string
+	firstID uint64
+	lastID  uint64
+	first   time.Time
+	last    time.Time
+	size    int64
+}
+
+// Store is a disk-backed log store with size- and age-based retention.
+// Entries are appended to JSON-lines segment files which are rotated once
+// they reach the segment size; whole segments are pruned when the store
+// exceeds its size budget or their newest entry is older than the maximum age.
+type Store struct {
+	dir         string
+	maxSize     int64
+	maxAge      time.Duration
+	segmentSize int64
+
+	mu       sync.RWMutex
+	segments []*segment
+	active   *os.File
+	nextID   uint64
+	memory   []models.LogEntry
+	closed   bool
+}
+
+// NewStore opens (or creates) a log store in dir. An empty dir keeps
+// the most recent entries in memory only.
+func NewStore(dir string, maxSize, segmentSize int64, maxAge time.Duration) (*Store, error) {
+	s := &Store{
+		dir:         dir,
+		maxSize:     maxSize,
+		maxAge:      maxAge,
+		segmentSize: segmentSize,
+		nextID:      1,
+	}
+
+	if dir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory %s: %w", dir, err)
+	}
+
+	if err := s.loadSegments(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// loadSegments scans existing segment files to recover IDs and time ranges
+func (s *Store) loadSegments() error {
+	paths, err := filepath.Glob(filepath.Join(s.dir, segmentPrefix+"*"+segmentSuffix))
+	if err != nil {
+		return fmt.Errorf("failed to list log segments: %w", err)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		seg, err := scanSegment(path)
+		if err != nil {
+			utils.Warnf("Warning: skipping log segment %s: %v", path, err)
+			continue
+		}
+		if seg.lastID == 0 {
+			os.Remove(path)
+			continue
+		}
+		s.segments = append(s.segments, seg)
+		if seg.lastID >= s.nextID {
+			s.nextID = seg.lastID + 1
+		}
+	}
+
+	utils.Infof("Opened log store %s: %d segments, next ID %d", s.dir, len(s.segments), s.nextID)
+	return nil
+}
+
+// scanSegment reads a segment file and truncates any torn trailing write
+func scanSegment(path string) (*segment, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	seg := &segment{path: path}
+	reader := bufio.NewReader(file)
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			var entry models.LogEntry
+			if jsonErr := json.Unmarshal(line, &entry); jsonErr == nil {
+				if seg.firstID == 0 {
+					seg.firstID = entry.ID
+					seg.first = entry.Timestamp
+				}
+				seg.lastID = entry.ID
+				seg.last = entry.Timestamp
+			}
+			offset += int64(len(line))
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if info, err := file.Stat(); err == nil && info.Size() > offset {
+		utils.Warnf("Truncating incomplete entry at end of %s", path)
+		if err := file.Truncate(offset); err != nil {
+			return nil, err
+		}
+	}
+
+	seg.size = offset
+	return seg, nil
+}
+
+// Append assigns an ID to the entry and stores it
+func (s *Store) Append(entry *models.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("log store is closed")
+	}
+
+	entry.ID = s.nextID
+	s.nextID++
+
+	if s.dir == "" {
+		if len(s.memory) >= memoryLogEntries {
+			s.memory = s.memory[1:]
+		}
+		s.memory = append(s.memory, *entry)
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	seg := s.currentSegment()
+	if seg == nil || seg.size >= s.segmentSize {
+		if seg, err = s.rotate(entry); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.active.Write(data); err != nil {
+		return fmt.Errorf("failed to write log entry: %w", err)
+	}
+
+	if seg.firstID == 0 {
+		seg.firstID = entry.ID
+		seg.first = entry.Timestamp
+	}
+	seg.lastID = entry.ID
+	seg.last = entry.Timestamp
+	seg.size += int64(len(data))
+	return nil
+}
+
+// currentSegment returns the segment being appended to, if it is open
+func (s *Store) currentSegment() *segment {
+	if s.active == nil || len(s.segments) == 0 {
+		return nil
+	}
+	return s.segments[len(s.segments)-1]
+}
+
+// rotate closes the active segment and starts a new one at entry
+func (s *Store) rotate(entry *models.LogEntry) (*segment, error) {
+	if s.active != nil {
+		s.active.Close()
+		s.active = nil
+	}
+
+	path := filepath.Join(s.dir, fmt.Sprintf("%s%020d%s", segmentPrefix, entry.ID, segmentSuffix))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log segment: %w", err)
+	}
+
+	s.active = file
+	seg := &segment{path: path}
+	s.segments = append(s.segments, seg)
+	s.pruneLocked(time.Now())
+	return seg, nil
+}
+
+// Prune removes segments that fall outside the retention limits
+func (s *Store) Prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked(time.Now())
+}
+
+// pruneLocked drops the oldest segments, never the one being written
+func (s *Store) pruneLocked(now time.Time) {
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+
+	for len(s.segments) > 1 {
+		oldest := s.segments[0]
+		expired := s.maxAge > 0 && !oldest.last.IsZero() && now.Sub(oldest.last) > s.maxAge
+		oversize := s.maxSize > 0 && total > s.maxSize
+		if !expired && !oversize {
+			break
+		}
+
+		if err := os.Remove(oldest.path); err != nil && !os.IsNotExist(err) {
+			utils.Warnf("Warning: failed to remove log segment %s: %v", oldest.path, err)
+			break
+		}
+		total -= oldest.size
+		s.segments = s.segments[1:]
+	}
+}
+
+// Query returns a page of entries matching q. Without an After cursor the
+// newest matching entries are returned (tail); with After the entries
+// following the cursor are returned so that callers can poll for new lines.
+func (s *Store) Query(q Query) (Result, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.dir == "" {
+		return Paginate(s.memory, q), nil
+	}
+
+	limit := q.limit()
+	var matched []models.LogEntry
+	more := false
+
+	if q.After > 0 {
+		// Forward scan from the cursor
+		for _, seg := range s.segments {
+			if !s.segmentOverlaps(seg, q) {
+				continue
+			}
+			entries, err := readSegment(seg.path, q)
+			if err != nil {
+				return Result{}, err
+			}
+			matched = append(matched, entries...)
+			if len(matched) > limit {
+				matched = matched[:limit]
+				more = true
+				break
+			}
+		}
+	} else {
+		// Backward scan collecting the newest entries
+		for i := len(s.segments) - 1; i >= 0; i-- {
+			seg := s.segments[i]
+			if !s.segmentOverlaps(seg, q) {
+				continue
+			}
+			entries, err := readSegment(seg.path, q)
+			if err != nil {
+				return Result{}, err
+			}
+			matched = append(entries, matched...)
+			if len(matched) > limit {
+				matched = matched[len(matched)-limit:]
+				more = true
+				break
+			}
+		}
+	}
+
+	return s.result(matched, q, more), nil
+}
+
+// segmentOverlaps checks whether a segment can contain matching entries
+func (s *Store) segmentOverlaps(seg *segment, q Query) bool {
+	if seg.lastID == 0 {
+		return false
+	}
+	if q.After > 0 && seg.lastID <= q.After {
+		return false
+	}
+	if q.Before > 0 && seg.firstID >= q.Before {
+		return false
+	}
+	if !q.From.IsZero() && seg.last.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !seg.first.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// result builds a Result with cursors for the matched entries
+func (s *Store) result(entries []models.LogEntry, q Query, more bool) Result {
+	res := Result{Entries: entries, More: more, Next: q.After}
+	if len(entries) > 0 {
+		res.Prev = entries[0].ID
+		res.Next = entries[len(entries)-1].ID
+	} else if q.After == 0 {
+		res.Next = s.nextID - 1
+	}
+	if res.Entries == nil {
+		res.Entries = []models.LogEntry{}
+	}
+	return res
+}
+
+// readSegment returns the entries of a segment file that match q
+func readSegment(path string, q Query) ([]models.LogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open log segment: %w", err)
+	}
+	defer file.Close()
+
+	var entries []models.LogEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var entry models.LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if q.inIDRange(entry.ID) && q.Match(&entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// Paginate applies a query to an in-memory slice of entries
+func Paginate(all []models.LogEntry, q Query) Result {
+	limit := q.limit()
+	var matched []models.LogEntry
+
+	for i := range all {
+		if q.inIDRange(all[i].ID) && q.Match(&all[i]) {
+			matched = append(matched, all[i])
+		}
+	}
+
+	more := len(matched) > limit
+	if more {
+		if q.After > 0 {
+			matched = matched[:limit]
+		} else {
+			matched = matched[len(matched)-limit:]
+		}
+	}
+
+	res := Result{Entries: matched, More: more, Next: q.After}
+	if len(matched) > 0 {
+		res.Prev = matched[0].ID
+		res.Next = matched[len(matched)-1].ID
+	} else if q.After == 0 && len(all) > 0 {
+		res.Next = all[len(all)-1].ID
+	}
+	if res.Entries == nil {
+		res.Entries = []models.LogEntry{}
+	}
+	return res
+}
+
+// Close closes the active segment
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.active != nil {
+		err := s.active.Close()
+		s.active = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+// ===== internal/logs/syslog.go =====
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+const (
+	// syslogChannel is the log channel for remotely received messages
+	syslogChannel = "syslog"
+
+	// maxSyslogMessage bounds a single UDP datagram or TCP frame
+	maxSyslogMessage = 64 * 1024
+)
+
+// SyslogMessage is a parsed RFC 3164 or RFC 5424 message
+type SyslogMessage struct {
+	Timestamp time.Time
+	Host      string
+	Program   string
+	Message   string
+}
+
+// ParseSyslog parses a syslog message in either RFC 5424 or the
+// traditional BSD (RFC 3164) format used by busybox and OpenWrt's logd
+func ParseSyslog(line string, now time.Time) (*SyslogMessage, error) {
+	line = strings.TrimRight(line, "\r\n\x00")
+
+	if !strings.HasPrefix(line, "<") {
+		return nil, fmt.Errorf("missing priority")
+	}
+	end := strings.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return nil, fmt.Errorf("invalid priority")
+	}
+	if _, err := strconv.Atoi(line[1:end]); err != nil {
+		return nil, fmt.Errorf("invalid priority: %w", err)
+	}
+	rest := line[end+1:]
+
+	if strings.HasPrefix(rest, "1 ") {
+		return parseRFC5424(rest[2:], now)
+	}
+	return parseRFC3164(rest, now)
+}
+
+// parseRFC5424 parses "TIMESTAMP HOST APP PROCID MSGID SD MSG"
+func parseRFC5424(rest string, now time.Time) (*SyslogMessage, error) {
+	fields := strings.SplitN(rest, " ", 6)
+	if len(fields) < 6 {
+		return nil, fmt.Errorf("truncated RFC 5424 header")
+	}
+
+	msg := &SyslogMessage{Timestamp: now}
+	if fields[0] != "-" {
+		if ts, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+			msg.Timestamp = ts
+		}
+	}
+	if fields[1] != "-" {
+		msg.Host = fields[1]
+	}
+	if fields[2] != "-" {
+		msg.Program = fields[2]
+	}
+
+	msg.Message = skipStructuredData(fields[5])
+	return msg, nil
+}
+
+// skipStructuredData removes the RFC 5424 STRUCTURED-DATA element(s)
+func skipStructuredData(s string) string {
+	if strings.HasPrefix(s, "-") {
+		return strings.TrimPrefix(strings.TrimPrefix(s, "-"), " ")
+	}
+
+	depth, escaped, quoted := 0, false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+			if depth == 0 && (i+1 >= len(s) || s[i+1] != '[') {
+				return strings.TrimPrefix(strings.TrimPrefix(s[i+1:], " "), "\ufeff")
+			}
+		}
+	}
+	return s
+}
+
+// parseRFC3164 parses "Mmm dd hh:mm:ss HOST TAG[PID]: MSG"
+func parseRFC3164(rest string, now time.Time) (*SyslogMessage, error) {
+	msg := &SyslogMessage{Timestamp: now}
+
+	if len(rest) >= 16 && rest[15] == ' ' {
+		if ts, err := time.ParseInLocation(time.Stamp, rest[:15], now.Location()); err == nil {
+			msg.Timestamp = time.Date(now.Year(), ts.Month(), ts.Day(),
+				ts.Hour(), ts.Minute(), ts.Second(), 0, now.Location())
+			// Messages from late December received in January
+			if msg.Timestamp.After(now.Add(24 * time.Hour)) {
+				msg.Timestamp = msg.Timestamp.AddDate(-1, 0, 0)
+			}
+			rest = rest[16:]
+
+			// The hostname is optional; a field ending in ':' is the tag
+			if sp := strings.IndexByte(rest, ' '); sp > 0 && !strings.HasSuffix(rest[:sp], ":") {
+				msg.Host = rest[:sp]
+				rest = rest[sp+1:]
+			}
+		}
+	}
+
+	if colon := strings.Index(rest, ": "); colon > 0 && !strings.ContainsAny(rest[:colon], " ") {
+		tag := rest[:colon]
+		if bracket := strings.IndexByte(tag, '['); bracket > 0 {
+			tag = tag[:bracket]
+		}
+		msg.Program = tag
+		rest = rest[colon+2:]
+	}
+
+	msg.Message = rest
+	return msg, nil
+}
+
+// startSyslog opens the configured syslog listeners. Both are bound
+// before either is served, so a failure leaves nothing listening.
+func (m *Manager) startSyslog() error {
+	proto := strings.ToLower(m.cfg.SyslogProtocol)
+	if proto != "udp" && proto != "tcp" && proto != "both" {
+		return fmt.Errorf("unknown syslog protocol %q", m.cfg.SyslogProtocol)
+	}
+
+	var conn net.PacketConn
+	if proto == "udp" || proto == "both" {
+		var err error
+		conn, err = net.ListenPacket("udp", m.cfg.SyslogListen)
+		if err != nil {
+			return fmt.Errorf("failed to listen for syslog on udp %s: %w", m.cfg.SyslogListen, err)
+		}
+	}
+
+	var listener net.Listener
+	if proto == "tcp" || proto == "both" {
+		var err error
+		listener, err = net.Listen("tcp", m.cfg.SyslogListen)
+		if err != nil {
+			if conn != nil {
+				conn.Close()
+			}
+			return fmt.Errorf("failed to listen for syslog on tcp %s: %w", m.cfg.SyslogListen, err)
+		}
+	}
+
+	if conn != nil {
+		utils.Infof("Listening for syslog on udp %s", m.cfg.SyslogListen)
+		go m.serveSyslogUDP(conn)
+	}
+	if listener != nil {
+		utils.Infof("Listening for syslog on tcp %s", m.cfg.SyslogListen)
+		go m.serveSyslogTCP(listener)
+	}
+
+	return nil
+}
+
+// serveSyslogUDP receives one message per datagram
+func (m *Manager) serveSyslogUDP(conn net.PacketConn) {
+	go func() {
+		<-m.stopCh
+		conn.Close()
+	}()
+
+	buf := make([]byte, maxSyslogMessage)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-m.stopCh:
+			default:
+				utils.Errorf("Syslog UDP receive failed: %v", err)
+			}
+			return
+		}
+		m.ingestSyslog(string(buf[:n]), addr)
+	}
+}
+
+// serveSyslogTCP accepts syslog connections
+func (m *Manager) serveSyslogTCP(listener net.Listener) {
+	go func() {
+		<-m.stopCh
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-m.stopCh:
+			default:
+				utils.Errorf("Syslog TCP accept failed: %v", err)
+			}
+			return
+		}
+		go m.handleSyslogConn(conn)
+	}
+}
+
+// handleSyslogConn reads RFC 6587 frames, either octet-counted
+// ("LEN SP MSG") or newline-delimited
+func (m *Manager) handleSyslogConn(conn net.Conn) {
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-m.stopCh:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	reader := bufio.NewReaderSize(conn, maxSyslogMessage)
+	for {
+		frame, err := readSyslogFrame(reader)
+		if frame != "" {
+			m.ingestSyslog(frame, conn.RemoteAddr())
+		}
+		if err != nil {
+			if err != io.EOF {
+				utils.Infof("Syslog connection from %s closed: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+	}
+}
+
+// readSyslogFrame reads a single framed message from a TCP stream
+func readSyslogFrame(reader *bufio.Reader) (string, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	if first[0] >= '1' && first[0] <= '9' {
+		lenStr, err := reader.ReadString(' ')
+		if err != nil {
+			return "", err
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(lenStr))
+		if err != nil || length <= 0 || length > maxSyslogMessage {
+			return "", fmt.Errorf("invalid frame length %q", lenStr)
+		}
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	line, err := reader.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// ingestSyslog parses a received message and stores it if it is from
+// one of the configured programs
+func (m *Manager) ingestSyslog(raw string, addr net.Addr) {
+	msg, err := ParseSyslog(raw, time.Now())
+	if err != nil {
+		utils.Infof("Ignoring malformed syslog message from %s: %v", addr, err)
+		return
+	}
+
+	if !m.acceptSyslogProgram(msg.Program) {
+		return
+	}
+
+	host := msg.Host
+	if host == "" {
+		if udp, ok := addr.(*net.UDPAddr); ok {
+			host = udp.IP.String()
+		} else if tcp, ok := addr.(*net.TCPAddr); ok {
+			host = tcp.IP.String()
+		}
+	}
+
+	m.addLogEntry(&models.LogEntry{
+		Timestamp: msg.Timestamp,
+		UnixTime:  msg.Timestamp.UnixMilli(),
+		Channel:   syslogChannel,
+		Host:      host,
+		Message:   msg.Message,
+	})
+}
+
+// acceptSyslogProgram matches the program name against the configured
+// prefixes; an empty list accepts everything
+func (m *Manager) acceptSyslogProgram(program string) bool {
+	if len(m.cfg.SyslogPrograms) == 0 {
+		return true
+	}
+	for _, prefix := range m.cfg.SyslogPrograms {
+		if strings.HasPrefix(program, prefix) {
+			return true
+		}
+	}
+	return false
+}
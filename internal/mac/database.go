@@ -2,184 +2,311 @@
 package mac
 
 import (
-	"bufio"
-	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"strings"
 	"sync"
-	
+	"time"
+
 	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
 )
 
-// Database handles MAC address OUI lookups
+// Database handles MAC address OUI lookups. Vendor prefixes are indexed
+// by assignment block size so that a lookup is a handful of map probes;
+// without preloading only the file offset of each entry is kept and the
+// entry is read on first use. The index can be rebuilt from the source
+// files at any time with Reload, which swaps it in atomically.
 type Database struct {
-	cache  map[string]*models.OUIEntry
-	file   *os.File
-	mu     sync.RWMutex
-	preloaded bool
+	files   []string
+	preload bool
+
+	index   *index
+	cache   map[prefixKey]*models.OUIEntry
+	sources []SourceStatus
+	loaded  time.Time
+	mu      sync.RWMutex
+	reload  sync.Mutex // Serializes Reload and Import
+	unknown *models.OUIEntry
+	private *models.OUIEntry
 }
 
-// NewDatabase creates a new MAC database instance
-func NewDatabase(filename string, preload bool) (*Database, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open MAC database: %w", err)
+// SourceStatus describes one vendor file as of the last load
+type SourceStatus struct {
+	Path    string `json:"path"`
+	Format  string `json:"format,omitempty"`
+	Entries int    `json:"entries"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Stats summarizes the loaded vendor database
+type Stats struct {
+	Sources  []SourceStatus `json:"sources"`
+	Prefixes int            `json:"prefixes"`
+	Cached   int            `json:"cached"`
+	LoadedAt time.Time      `json:"loadedAt"`
+}
+
+// retiredIndexGrace is how long a replaced index stays open so that
+// lookups that already hold it can finish reading entries
+const retiredIndexGrace = time.Minute
+
+// NewDatabase creates a new MAC database instance from one or more vendor
+// files. Each file may be macaddress.io JSON lines, an IEEE registry CSV
+// (oui.csv, mam.csv, oui36.csv) or a Wireshark manuf file; the format is
+// detected from its contents. Files listed first win when two sources
+// define the same prefix. A source that cannot be read is skipped with a
+// warning so that lookups keep working with whatever is available.
+func NewDatabase(filenames []string, preload bool) (*Database, error) {
+	idx, sources, loaded := buildIndex(filenames, preload)
+	if loaded == 0 {
+		utils.Warnf("Warning: no MAC database loaded; vendors will be reported as UNKNOWN")
 	}
 
 	db := &Database{
-		cache: make(map[string]*models.OUIEntry),
-		file:  file,
+		files:   filenames,
+		preload: preload,
+		index:   idx,
+		cache:   make(map[prefixKey]*models.OUIEntry),
+		sources: sources,
+		loaded:  time.Now(),
 	}
 
 	// Initialize default entries
 	db.initializeDefaults()
 
-	if preload {
-		if err := db.preloadDatabase(); err != nil {
-			log.Printf("Warning: failed to preload MAC database: %v", err)
+	return db, nil
+}
+
+// buildIndex indexes every readable source and reports how many loaded
+func buildIndex(filenames []string, preload bool) (*index, []SourceStatus, int) {
+	idx := newIndex()
+	sources := make([]SourceStatus, 0, len(filenames))
+	loaded := 0
+
+	for _, filename := range filenames {
+		status := indexFile(filename, idx, preload)
+		if status.Error != "" {
+			utils.Warnf("Warning: skipping MAC database %s: %s", filename, status.Error)
+		} else {
+			loaded++
 		}
+		sources = append(sources, status)
 	}
 
-	return db, nil
+	return idx, sources, loaded
+}
+
+// indexFile adds the prefixes of one vendor file to the index
+func indexFile(filename string, idx *index, preload bool) SourceStatus {
+	status := SourceStatus{Path: filename}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		status.Error = fmt.Sprintf("failed to open MAC database: %v", err)
+		return status
+	}
+
+	format, count, err := indexOpenFile(file, idx, preload)
+	status.Format = format
+	status.Entries = count
+	if err != nil {
+		file.Close()
+		status.Error = err.Error()
+		return status
+	}
+
+	if format == FormatJSON && !preload {
+		// Entries are read from the file on first use
+		idx.files = append(idx.files, file)
+		utils.Infof("Indexed %d MAC prefixes from %s", count, filename)
+	} else {
+		// Everything is in memory; the file is no longer needed
+		file.Close()
+		utils.Infof("Loaded %d MAC entries from %s (%s)", count, filename, format)
+	}
+
+	return status
+}
+
+// indexOpenFile detects the format of an open vendor file and indexes it
+func indexOpenFile(file *os.File, idx *index, preload bool) (string, int, error) {
+	format, err := detectFileFormat(file)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var count int
+	switch format {
+	case FormatJSON:
+		count, err = indexJSONLines(file, idx, preload)
+	case FormatIEEE:
+		count, err = indexIEEECSV(file, idx)
+	case FormatManuf:
+		count, err = indexManuf(file, idx)
+	}
+	if err == nil && count == 0 {
+		err = fmt.Errorf("no vendor entries found")
+	}
+	return format, count, err
+}
+
+// Reload rebuilds the index from the source files and swaps it in. The
+// current index is kept when none of the sources can be loaded.
+func (db *Database) Reload() error {
+	db.reload.Lock()
+	defer db.reload.Unlock()
+	return db.rebuild()
+}
+
+// rebuild reindexes the sources; the caller holds db.reload
+func (db *Database) rebuild() error {
+	idx, sources, loaded := buildIndex(db.files, db.preload)
+	if loaded == 0 && len(db.files) > 0 {
+		idx.close()
+		return fmt.Errorf("no MAC database could be loaded; keeping the current one")
+	}
+
+	db.mu.Lock()
+	old := db.index
+	db.index = idx
+	db.cache = make(map[prefixKey]*models.OUIEntry)
+	db.sources = sources
+	db.loaded = time.Now()
+	db.mu.Unlock()
+
+	time.AfterFunc(retiredIndexGrace, func() { old.close() })
+
+	utils.Infof("MAC database reloaded: %d prefixes from %d of %d sources", len(idx.records), loaded, len(db.files))
+	return nil
+}
+
+// Import validates a vendor file and installs it as dest, which must be
+// one of the configured sources, then reloads the database. The file is
+// written next to dest and renamed into place so a failed upload never
+// replaces a working source.
+func (db *Database) Import(r io.Reader, dest string) (SourceStatus, error) {
+	status := SourceStatus{Path: dest}
+
+	db.reload.Lock()
+	defer db.reload.Unlock()
+
+	known := false
+	for _, file := range db.files {
+		if file == dest {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return status, fmt.Errorf("%s is not a configured MAC database", dest)
+	}
+
+	validated := false
+	err := utils.WriteAtomic(dest, 0644, func(f *os.File) error {
+		if _, err := io.Copy(f, r); err != nil {
+			return fmt.Errorf("failed to write upload: %w", err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		// Validate with a throwaway index before touching the real file
+		var err error
+		status.Format, status.Entries, err = indexOpenFile(f, newIndex(), false)
+		if err != nil {
+			return fmt.Errorf("invalid MAC database: %w", err)
+		}
+		validated = true
+		return nil
+	})
+	if err != nil {
+		if validated {
+			return status, fmt.Errorf("failed to install MAC database: %w", err)
+		}
+		return status, err
+	}
+
+	utils.Infof("Imported %d %s MAC entries into %s", status.Entries, status.Format, dest)
+	return status, db.rebuild()
+}
+
+// Files returns the configured source files, highest priority first
+func (db *Database) Files() []string {
+	return db.files
+}
+
+// Stats reports the sources and size of the loaded index
+func (db *Database) Stats() Stats {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return Stats{
+		Sources:  append([]SourceStatus(nil), db.sources...),
+		Prefixes: len(db.index.records),
+		Cached:   len(db.cache),
+		LoadedAt: db.loaded,
+	}
 }
 
 // initializeDefaults sets up default OUI entries for unknown and private MACs
 func (db *Database) initializeDefaults() {
-	db.cache["UNKNOWN"] = &models.OUIEntry{
+	db.unknown = &models.OUIEntry{
 		OUI:     "00:00:00:00:00:00",
 		Private: false,
 		Company: "UNKNOWN",
 		Address: "UNKNOWN",
 	}
 
-	privateMAC := &models.OUIEntry{
+	db.private = &models.OUIEntry{
 		Private: true,
 		Company: "Local/Privacy MAC",
 		Address: "UNKNOWN",
 	}
-
-	// Private MAC patterns
-	patterns := []string{"PRIVATE-2", "PRIVATE-6", "PRIVATE-A", "PRIVATE-E"}
-	for _, pattern := range patterns {
-		db.cache[pattern] = privateMAC
-	}
-}
-
-// preloadDatabase loads all MAC entries into memory
-func (db *Database) preloadDatabase() error {
-	db.file.Seek(0, 0)
-	scanner := bufio.NewScanner(db.file)
-	
-	count := 0
-	for scanner.Scan() {
-		var entry models.OUIEntry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			continue
-		}
-		
-		prefix := strings.ToUpper(entry.OUI)
-		db.cache[prefix] = &entry
-		count++
-	}
-	
-	db.preloaded = true
-	log.Printf("Preloaded %d MAC entries", count)
-	return scanner.Err()
 }
 
 // Lookup finds OUI information for a MAC address
 func (db *Database) Lookup(mac string) *models.OUIEntry {
-	mac = strings.ToUpper(mac)
-	
-	// First check cache with read lock
+	value, ok := parseMAC(mac)
+	if !ok {
+		return db.unknown
+	}
+
 	db.mu.RLock()
-	
-	// Try cache first with progressively shorter prefixes
-	for i := len(mac); i >= 0; i-- {
-		if entry, exists := db.cache[mac[0:i]]; exists {
+	idx := db.index
+	key, rec, found := idx.find(value)
+	if found {
+		if entry, cached := db.cache[key]; cached {
 			db.mu.RUnlock()
 			return entry
 		}
 	}
-	
-	// Check for private MAC patterns
-	if len(mac) > 1 {
-		switch mac[1] {
-		case '2':
-			if entry := db.cache["PRIVATE-2"]; entry != nil {
-				db.mu.RUnlock()
-				return entry
-			}
-		case '6':
-			if entry := db.cache["PRIVATE-6"]; entry != nil {
-				db.mu.RUnlock()
-				return entry
-			}
-		case 'A':
-			if entry := db.cache["PRIVATE-A"]; entry != nil {
-				db.mu.RUnlock()
-				return entry
-			}
-		case 'E':
-			if entry := db.cache["PRIVATE-E"]; entry != nil {
-				db.mu.RUnlock()
-				return entry
-			}
-		}
-	}
-	
-	// Check if preloaded - if so, return unknown
-	if db.preloaded {
-		unknown := db.cache["UNKNOWN"]
-		db.mu.RUnlock()
-		return unknown
-	}
-	
-	// Release read lock before file search
 	db.mu.RUnlock()
-	
-	// If not preloaded, search the file
-	if entry := db.searchFile(mac); entry != nil {
-		return entry
-	}
-	
-	// Return unknown if nothing found
-	db.mu.RLock()
-	unknown := db.cache["UNKNOWN"]
-	db.mu.RUnlock()
-	return unknown
-}
 
-// searchFile searches the database file for a MAC prefix
-func (db *Database) searchFile(mac string) *models.OUIEntry {
-	db.file.Seek(0, 0)
-	scanner := bufio.NewScanner(db.file)
-	
-	for scanner.Scan() {
-		var entry models.OUIEntry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			continue
-		}
-		
-		prefix := strings.ToUpper(entry.OUI)
-		if strings.HasPrefix(mac, prefix) {
-			// Cache the result with write lock
+	if found {
+		entry, err := idx.load(rec)
+		if err == nil {
 			db.mu.Lock()
-			db.cache[prefix] = &entry
+			if db.index == idx {
+				db.cache[key] = entry
+			}
 			db.mu.Unlock()
-			return &entry
+			return entry
 		}
+		utils.Errorf("MAC lookup for %s failed: %v", mac, err)
 	}
-	
-	return nil
+
+	// Locally administered addresses have no registered vendor
+	if value&(0x02<<40) != 0 {
+		return db.private
+	}
+
+	return db.unknown
 }
 
-// Close closes the database file
+// Close closes the database files
 func (db *Database) Close() error {
-	if db.file != nil {
-		return db.file.Close()
-	}
-	return nil
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.index.close()
 }
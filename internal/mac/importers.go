@@ -0,0 +1,246 @@
+// ===== internal/mac/importers.go =====
+package mac
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"dhcpmon/pkg/models"
+)
+
+// Supported vendor database formats
+const (
+	FormatJSON  = "json"  // macaddress.io JSON lines
+	FormatIEEE  = "ieee"  // IEEE oui.csv, mam.csv and oui36.csv
+	FormatManuf = "manuf" // Wireshark manuf
+)
+
+// DetectFormat guesses the format of a vendor database from its first
+// non-empty, non-comment line
+func DetectFormat(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "{"):
+			return FormatJSON, nil
+		case strings.HasPrefix(line, "Registry,"):
+			return FormatIEEE, nil
+		default:
+			if fields := strings.Fields(line); len(fields) >= 2 {
+				if _, ok := parseManufPrefix(fields[0]); ok {
+					return FormatManuf, nil
+				}
+			}
+			return "", fmt.Errorf("unrecognized MAC database format")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("MAC database is empty")
+}
+
+// detectFileFormat detects the format of an open file and rewinds it
+func detectFileFormat(file *os.File) (string, error) {
+	format, err := DetectFormat(file)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
+// indexIEEECSV adds the records of an IEEE registry CSV export. The
+// Assignment column holds 6, 7 or 9 hex digits for MA-L, MA-M and MA-S.
+func indexIEEECSV(r io.Reader, idx *index) (int, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read IEEE CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	registryCol, ok1 := columns["Registry"]
+	assignmentCol, ok2 := columns["Assignment"]
+	orgCol, ok3 := columns["Organization Name"]
+	addressCol, hasAddress := columns["Organization Address"]
+	if !ok1 || !ok2 || !ok3 {
+		return 0, fmt.Errorf("IEEE CSV is missing Registry, Assignment or Organization Name columns")
+	}
+
+	count := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to read IEEE CSV: %w", err)
+		}
+		if len(row) <= orgCol || len(row) <= assignmentCol || len(row) <= registryCol {
+			continue
+		}
+
+		key, ok := parsePrefix(row[assignmentCol])
+		if !ok {
+			continue
+		}
+
+		entry := &models.OUIEntry{
+			OUI:       formatPrefix(key),
+			Company:   strings.TrimSpace(row[orgCol]),
+			BlockSize: strings.TrimSpace(row[registryCol]),
+		}
+		if hasAddress && len(row) > addressCol {
+			entry.Address = strings.TrimSpace(row[addressCol])
+			entry.CountryCode = countryFromAddress(entry.Address)
+		}
+		entry.Private = strings.EqualFold(entry.Company, "Private")
+
+		idx.add(key, record{entry: entry})
+		count++
+	}
+
+	return count, nil
+}
+
+// countryFromAddress returns the ISO country code of an IEEE registry
+// address, which ends with the country code and usually a postcode, e.g.
+// "445 Hoes Lane Piscataway NJ US 08554"
+func countryFromAddress(address string) string {
+	fields := strings.Fields(address)
+	for i := len(fields) - 1; i >= 0 && i >= len(fields)-2; i-- {
+		if f := fields[i]; len(f) == 2 && strings.ToUpper(f) == f && !strings.ContainsAny(f, "0123456789") {
+			return f
+		}
+	}
+	return ""
+}
+
+// indexManuf adds the records of a Wireshark manuf file:
+//
+//	00:00:0C	Cisco	Cisco Systems, Inc
+//	00:1B:C5:00:00:00/36	Converging	Converging Systems Inc.
+func indexManuf(r io.Reader, idx *index) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	count := 0
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 2 {
+			fields = strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+		}
+
+		key, ok := parseManufPrefix(strings.TrimSpace(fields[0]))
+		if !ok {
+			continue
+		}
+
+		company := strings.TrimSpace(fields[1])
+		if len(fields) > 2 && strings.TrimSpace(fields[2]) != "" {
+			company = strings.TrimSpace(fields[2])
+		}
+
+		entry := &models.OUIEntry{
+			OUI:       formatPrefix(key),
+			Company:   company,
+			BlockSize: blockSizeName(key.bits),
+		}
+
+		idx.add(key, record{entry: entry})
+		count++
+	}
+
+	return count, scanner.Err()
+}
+
+// parseManufPrefix parses a manuf address with an optional /bits mask
+func parseManufPrefix(field string) (prefixKey, bool) {
+	addr, maskStr, hasMask := strings.Cut(field, "/")
+
+	key, ok := parsePrefix(addr)
+	if !ok {
+		return prefixKey{}, false
+	}
+	if !hasMask {
+		return key, true
+	}
+
+	mask, err := strconv.Atoi(maskStr)
+	if err != nil || mask <= 0 || mask > int(key.bits) {
+		return prefixKey{}, false
+	}
+	key.value >>= uint(int(key.bits) - mask)
+	key.bits = uint8(mask)
+	return key, true
+}
+
+// formatPrefix renders a prefix as colon-separated hex, e.g. 70:B3:D5:E9:6
+func formatPrefix(key prefixKey) string {
+	digits := int(key.bits+3) / 4
+	hex := fmt.Sprintf("%0*X", digits, key.value<<(uint(digits*4)-uint(key.bits)))
+
+	var buf bytes.Buffer
+	for i := 0; i < len(hex); i += 2 {
+		if i > 0 {
+			buf.WriteByte(':')
+		}
+		end := i + 2
+		if end > len(hex) {
+			end = len(hex)
+		}
+		buf.WriteString(hex[i:end])
+	}
+	if key.bits%4 != 0 {
+		fmt.Fprintf(&buf, "/%d", key.bits)
+	}
+	return buf.String()
+}
+
+// blockSizeName names the IEEE registry of a prefix length
+func blockSizeName(bits uint8) string {
+	switch bits {
+	case 24:
+		return "MA-L"
+	case 28:
+		return "MA-M"
+	case 36:
+		return "MA-S"
+	}
+	return ""
+}
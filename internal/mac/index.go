@@ -0,0 +1,194 @@
+// ===== internal/mac/index.go =====
+package mac
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"dhcpmon/pkg/models"
+)
+
+// prefixKey identifies an assignment block: the first bits of a MAC
+// address (24 for MA-L, 28 for MA-M, 36 for MA-S)
+type prefixKey struct {
+	bits  uint8
+	value uint64
+}
+
+// record locates the vendor entry of one prefix, either in memory or as
+// a line in a JSON-lines file
+type record struct {
+	entry  *models.OUIEntry
+	file   *os.File
+	offset int64
+	length int32
+}
+
+// index maps MAC prefixes to vendor records
+type index struct {
+	records map[prefixKey]record
+	bits    []uint8 // Prefix lengths present, longest first
+	files   []*os.File
+}
+
+// newIndex creates an empty index
+func newIndex() *index {
+	return &index{records: make(map[prefixKey]record)}
+}
+
+// add inserts a record for prefix unless a record already exists, so
+// sources indexed earlier take precedence
+func (idx *index) add(key prefixKey, rec record) {
+	if _, exists := idx.records[key]; exists {
+		return
+	}
+	idx.records[key] = rec
+
+	for _, bits := range idx.bits {
+		if bits == key.bits {
+			return
+		}
+	}
+	idx.bits = append(idx.bits, key.bits)
+	sort.Slice(idx.bits, func(i, j int) bool { return idx.bits[i] > idx.bits[j] })
+}
+
+// find returns the longest-prefix record for a 48-bit MAC value
+func (idx *index) find(mac uint64) (prefixKey, record, bool) {
+	for _, bits := range idx.bits {
+		key := prefixKey{bits: bits, value: mac >> (48 - uint(bits))}
+		if rec, ok := idx.records[key]; ok {
+			return key, rec, true
+		}
+	}
+	return prefixKey{}, record{}, false
+}
+
+// load reads the entry of a record, from the file if it is not in memory
+func (idx *index) load(rec record) (*models.OUIEntry, error) {
+	if rec.entry != nil {
+		return rec.entry, nil
+	}
+
+	buf := make([]byte, rec.length)
+	if _, err := rec.file.ReadAt(buf, rec.offset); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read MAC database entry: %w", err)
+	}
+
+	var entry models.OUIEntry
+	if err := json.Unmarshal(buf, &entry); err != nil {
+		return nil, fmt.Errorf("failed to decode MAC database entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// close releases the files backing the index
+func (idx *index) close() error {
+	var firstErr error
+	for _, file := range idx.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	idx.files = nil
+	return firstErr
+}
+
+// indexJSONLines builds an index over a macaddress.io JSON-lines file.
+// Only prefixes and line offsets are kept unless preload is set, in which
+// case every entry is decoded into memory and the file is closed.
+func indexJSONLines(file *os.File, idx *index, preload bool) (int, error) {
+	reader := bufio.NewReaderSize(file, 256*1024)
+	var offset int64
+	count := 0
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if key, entry, ok := parseJSONLine(line, preload); ok {
+				rec := record{entry: entry, offset: offset, length: int32(len(line))}
+				if entry == nil {
+					rec.file = file
+				}
+				idx.add(key, rec)
+				count++
+			}
+			offset += int64(len(line))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to read MAC database: %w", err)
+		}
+	}
+
+	return count, nil
+}
+
+// parseJSONLine extracts the prefix of a JSON-lines record, decoding the
+// whole entry only when it is going to be kept in memory
+func parseJSONLine(line []byte, full bool) (prefixKey, *models.OUIEntry, bool) {
+	if full {
+		var entry models.OUIEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return prefixKey{}, nil, false
+		}
+		key, ok := parsePrefix(entry.OUI)
+		return key, &entry, ok
+	}
+
+	var head struct {
+		OUI string `json:"oui"`
+	}
+	if err := json.Unmarshal(line, &head); err != nil {
+		return prefixKey{}, nil, false
+	}
+	key, ok := parsePrefix(head.OUI)
+	return key, nil, ok
+}
+
+// parsePrefix converts a prefix such as "00:1B:C5" or "70-B3-D5-E9-6"
+// into a prefix key; the number of hex digits gives the prefix length
+func parsePrefix(prefix string) (prefixKey, bool) {
+	var value uint64
+	digits := 0
+
+	for _, r := range prefix {
+		var nibble uint64
+		switch {
+		case r >= '0' && r <= '9':
+			nibble = uint64(r - '0')
+		case r >= 'a' && r <= 'f':
+			nibble = uint64(r-'a') + 10
+		case r >= 'A' && r <= 'F':
+			nibble = uint64(r-'A') + 10
+		case r == ':' || r == '-' || r == '.':
+			continue
+		default:
+			return prefixKey{}, false
+		}
+		value = value<<4 | nibble
+		digits++
+	}
+
+	if digits < 6 || digits > 12 {
+		return prefixKey{}, false
+	}
+	return prefixKey{bits: uint8(digits * 4), value: value}, true
+}
+
+// parseMAC converts a MAC address in any common notation to a 48-bit value
+func parseMAC(mac string) (uint64, bool) {
+	mac = strings.TrimSpace(mac)
+	key, ok := parsePrefix(mac)
+	if !ok || key.bits != 48 {
+		return 0, false
+	}
+	return key.value, true
+}
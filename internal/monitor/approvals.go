@@ -0,0 +1,162 @@
+// ===== internal/monitor/approvals.go =====
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"dhcpmon/internal/devices"
+	"dhcpmon/internal/static"
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// GetApprovals returns the devices in an approval state, or all of them
+// when state is empty
+func (m *Monitor) GetApprovals(state string) []models.Approval {
+	return m.approvals.List(state)
+}
+
+// GetApproval returns the approval of a MAC address
+func (m *Monitor) GetApproval(mac string) (models.Approval, bool) {
+	return m.approvals.Get(mac)
+}
+
+// ApprovalCounts returns the number of devices in each approval state
+func (m *Monitor) ApprovalCounts() map[string]int {
+	return m.approvals.Counts()
+}
+
+// ApproveDevice approves a device. With a reservation, the static entry
+// is added, saved and dnsmasq restarted first as one transaction, so a
+// failure leaves the static entries as they were; the device is only
+// approved once the reservation is in place.
+func (m *Monitor) ApproveDevice(mac, by, note string, reservation *models.StaticDHCPEntry) (models.Approval, error) {
+	if reservation != nil {
+		if err := m.applyStatic(func() error { return m.staticManager.Add(*reservation) }); err != nil {
+			return models.Approval{}, fmt.Errorf("failed to add reservation: %w", err)
+		}
+	}
+	return m.approvals.Decide(mac, models.ApprovalApproved, by, note)
+}
+
+// BlockDevice blocks a device in dnsmasq, making it ignore the device or
+// moving it to the quarantine tag, and marks the device as blocked. The
+// static entries are changed, saved and dnsmasq restarted as one
+// transaction; the reservations a quarantine disables are recorded with
+// the approval so UnblockDevice restores exactly those.
+func (m *Monitor) BlockDevice(mac, mode, by, note string) (models.Approval, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return models.Approval{}, fmt.Errorf("invalid MAC address: %w", err)
+	}
+	if mode == "" {
+		mode = static.BlockIgnore
+	}
+
+	comment := note
+	if comment == "" {
+		comment = "blocked " + time.Now().Format("2006-01-02")
+	}
+	var disabled []string
+	err = m.applyStatic(func() error {
+		var err error
+		disabled, err = m.staticManager.Block(hw, mode, m.cfg.QuarantineTag, comment)
+		return err
+	})
+	if err != nil {
+		return models.Approval{}, err
+	}
+
+	approval, err := m.approvals.Decide(mac, models.ApprovalBlocked, by, note)
+	if err != nil || len(disabled) == 0 {
+		return approval, err
+	}
+	if err := m.approvals.SetQuarantined(mac, disabled); err != nil {
+		return approval, err
+	}
+	approval.Quarantined = disabled
+	return approval, nil
+}
+
+// UnblockDevice removes the block on a device as one transaction, like
+// BlockDevice, and marks the device as approved
+func (m *Monitor) UnblockDevice(mac, by, note string) (models.Approval, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return models.Approval{}, fmt.Errorf("invalid MAC address: %w", err)
+	}
+
+	approval, _ := m.approvals.Get(mac)
+	err = m.applyStatic(func() error {
+		return m.staticManager.Unblock(hw, m.cfg.QuarantineTag, approval.Quarantined)
+	})
+	if err != nil {
+		return models.Approval{}, err
+	}
+	return m.approvals.Decide(mac, models.ApprovalApproved, by, note)
+}
+
+// GetBlockedDevices returns the devices blocked or quarantined in the
+// static configuration, with their current lease
+func (m *Monitor) GetBlockedDevices() []models.BlockedDevice {
+	leases := make(map[string]models.DHCPLease)
+	for _, lease := range m.GetDHCPLeases() {
+		if !lease.Static && lease.MAC != nil {
+			leases[lease.MAC.String()] = lease
+		}
+	}
+
+	entries := m.staticManager.Blocked(m.cfg.QuarantineTag)
+	blocked := make([]models.BlockedDevice, 0, len(entries))
+	for _, entry := range entries {
+		d := models.BlockedDevice{
+			MAC:      entry.GetFormattedMAC(),
+			Mode:     static.BlockQuarantine,
+			Hostname: entry.Hostname,
+			Comment:  entry.Comment,
+			EntryID:  entry.ID,
+		}
+		if entry.Ignore {
+			d.Mode = static.BlockIgnore
+		}
+		if approval, ok := m.approvals.Get(d.MAC); ok {
+			d.Vendor = approval.Vendor
+		}
+		if lease, ok := leases[entry.MAC.String()]; ok {
+			d.IP = lease.IP.String()
+			if d.Hostname == "" {
+				d.Hostname = lease.Name
+			}
+			if d.Vendor == "" && lease.Info != nil {
+				d.Vendor = lease.Info.Company
+			}
+		}
+		blocked = append(blocked, d)
+	}
+	return blocked
+}
+
+// ForgetDevice removes the approval of a device, so it is queued again
+// the next time it is seen as new
+func (m *Monitor) ForgetDevice(mac string) error {
+	return m.approvals.Delete(mac)
+}
+
+// handleNewDevice queues a MAC seen for the first time for approval and
+// reports its arrival
+func (m *Monitor) handleNewDevice(s devices.Sighting) {
+	approval, _, err := m.approvals.AddPending(models.Approval{
+		MAC:       s.MAC,
+		Hostname:  s.Hostname,
+		IP:        s.IP,
+		Vendor:    s.Vendor,
+		FirstSeen: s.FirstSeen,
+	})
+	if err != nil {
+		utils.Warnf("Warning: failed to queue %s for approval: %v", s.MAC, err)
+		approval.State = models.ApprovalPending
+	}
+	m.notifyNewDevice(s, approval)
+}
@@ -0,0 +1,62 @@
+// ===== internal/monitor/bulk.go =====
+package monitor
+
+import (
+	"fmt"
+
+	"dhcpmon/internal/static"
+	"dhcpmon/pkg/utils"
+)
+
+// ImportStatic imports static reservations read from a bulk file. It
+// plans the import first and returns the plan without changing anything
+// when dryRun is set, when a row has an error or when nothing changes.
+// Otherwise the import is saved and dnsmasq restarted as one transaction.
+func (m *Monitor) ImportStatic(rows []static.ImportRow, mode string, dryRun bool) (*static.ImportResult, error) {
+	plan, err := m.staticManager.Import(rows, mode, true)
+	if err != nil {
+		return nil, err
+	}
+	plan.DryRun = dryRun
+	if dryRun || len(plan.Errors) > 0 || !plan.HasChanges() {
+		return plan, nil
+	}
+
+	var result *static.ImportResult
+	err = m.applyStatic(func() error {
+		var err error
+		result, err = m.staticManager.Import(rows, mode, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Infof("Imported static reservations (%s): %s", mode, result.Summary())
+	return result, nil
+}
+
+// BatchStatic applies a batch of operations to the static entries. The
+// batch is checked first and returned without changing anything when
+// dryRun is set or an operation fails. Otherwise it is applied, saved and
+// dnsmasq restarted as one transaction.
+func (m *Monitor) BatchStatic(ops []static.BatchOp, dryRun bool) (*static.BatchResult, error) {
+	plan := m.staticManager.Batch(ops, true)
+	plan.DryRun = dryRun
+	if dryRun || len(plan.Errors) > 0 {
+		return plan, nil
+	}
+
+	var result *static.BatchResult
+	err := m.applyStatic(func() error {
+		result = m.staticManager.Batch(ops, false)
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("operation %d: %s", result.Errors[0].Op, result.Errors[0].Error)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	utils.Infof("Applied a batch of %d static entry operations", len(ops))
+	return result, nil
+}
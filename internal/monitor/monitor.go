@@ -3,8 +3,8 @@ package monitor
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
-	"log"
 	"os"
 	"net"
 	"sync"
@@ -12,40 +12,81 @@ import (
 	
 	"github.com/fsnotify/fsnotify"
 	
+	"dhcpmon/internal/alerts"
+	"dhcpmon/internal/approvals"
 	"dhcpmon/internal/config"
+	"dhcpmon/internal/consistency"
+	"dhcpmon/internal/devices"
 	"dhcpmon/internal/dhcp"
+	"dhcpmon/internal/dnsmasq"
 	"dhcpmon/internal/hosts"
+	"dhcpmon/internal/ipam"
+	"dhcpmon/internal/labels"
 	"dhcpmon/internal/logs"
+	"dhcpmon/internal/mac"
+	"dhcpmon/internal/notify"
 	"dhcpmon/internal/static"
 	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
 )
 
 // Monitor handles file monitoring and data management
 type Monitor struct {
 	cfg        *config.Config
 	dhcpParser *dhcp.Parser
-	hostsParser *hosts.Parser
+	hostsManager *hosts.Manager
 	logManager *logs.Manager
 	staticManager *static.Manager
+	dnsmasq    dnsmasq.Controller
+	releaser   *dnsmasq.Releaser
+	macDB      *mac.Database
+	devices    *devices.Tracker
+	labels     *labels.Store
+	approvals  *approvals.Store
+	alerts     *alerts.Manager
+	notify     *notify.Manager
 	
 	dhcpLeases []models.DHCPLease
-	hostEntries []models.HostEntry
 	
 	watcher *fsnotify.Watcher
 	mu      sync.RWMutex
-	staticMu sync.RWMutex
+	staticMu sync.RWMutex // Serializes changes to the static entries
 	stopCh  chan struct{}
+	
+	// Vendor database files watched for replacement, by absolute path
+	macFiles    map[string]bool
+	macTimer    *time.Timer
+	macTimerMu  sync.Mutex
 }
 
+// poolCheckInterval is how often pool utilization is checked besides on
+// lease and reservation changes, to catch configuration changes and expire
+// exhaustion alerts
+const poolCheckInterval = time.Minute
+
+// macReloadDelay lets a vendor file finish being written before reloading
+const macReloadDelay = 2 * time.Second
+
 // New creates a new monitor instance
-func New(cfg *config.Config, dhcpParser *dhcp.Parser) *Monitor {
+func New(cfg *config.Config, dhcpParser *dhcp.Parser, macDB *mac.Database) *Monitor {
+	logManager := logs.NewManager(cfg)
+	
 	return &Monitor{
 		cfg:         cfg,
 		dhcpParser:  dhcpParser,
-		hostsParser: hosts.NewParser(),
-		logManager:  logs.NewManager(cfg),
+		hostsManager: hosts.NewManager(cfg.HostsFile),
+		logManager:  logManager,
 		staticManager: static.NewManager(cfg.StaticFile),
+		dnsmasq:     dnsmasq.NewController(cfg, logManager.Ingest),
+		releaser:    dnsmasq.NewReleaser(cfg.DHCPRelease, cfg.DHCPRelease6),
+		macDB:       macDB,
+		devices:     devices.NewTracker(cfg.DevicesFile),
+		labels:      labels.NewStore(cfg.LabelsFile),
+		approvals:   approvals.NewStore(cfg.ApprovalsFile),
+		alerts:      alerts.NewManager(cfg.PoolWarn, cfg.PoolCrit),
+		notify:      notify.NewManager(cfg.Notify),
 		stopCh:      make(chan struct{}),
+		macFiles:    make(map[string]bool),
 	}
 }
 
@@ -60,20 +101,38 @@ func (m *Monitor) Start() error {
 		return fmt.Errorf("failed to create file watcher: %w", err)
 	}
 
+	// Send new devices, alerts and dnsmasq crashes to the notification
+	// channels
+	m.notify.Start()
+	m.devices.OnNewDevice(m.handleNewDevice)
+	m.alerts.AddNotifier(alerts.NotifierFunc(m.notifyAlert))
+
+	// Approvals first, so that devices in the initial leases are queued
+	if err := m.approvals.Load(); err != nil {
+		utils.Warnf("Warning: failed to load device approvals: %v", err)
+	}
+
 	// Initial load (with better error handling)
 	if err := m.loadDHCPLeases(); err != nil {
-		log.Printf("Warning: failed to load DHCP leases: %v", err)
+		utils.Warnf("Warning: failed to load DHCP leases: %v", err)
 	}
 
-	if err := m.loadHostEntries(); err != nil {
-		log.Printf("Warning: failed to load host entries: %v", err)
+	if err := m.hostsManager.Load(); err != nil {
+		utils.Warnf("Warning: failed to load host entries: %v", err)
 	}
 
 	// Load static entries
 	if err := m.staticManager.Load(); err != nil {
-		log.Printf("Warning: failed to load static entries: %v", err)
+		utils.Warnf("Warning: failed to load static entries: %v", err)
 	}
 
+	if err := m.labels.Load(); err != nil {
+		utils.Warnf("Warning: failed to load device labels: %v", err)
+	}
+
+	// The vendor files are noted before the watching goroutine reads them
+	m.watchMACDatabase()
+
 	// Start file watching goroutine BEFORE adding files
 	go m.watchFiles()
 
@@ -85,10 +144,28 @@ func (m *Monitor) Start() error {
 		m.addFileToWatcher(m.cfg.StaticFile, "static file")
 	}
 
+	// Collect DHCP fingerprints and device sightings from the logs,
+	// including those replayed from the journal at startup
+	m.devices.Start()
+	m.logManager.Subscribe(m.devices.ObserveLog)
+
+	// Alert on pools filling up, and as soon as dnsmasq runs out
+	m.alerts.Start()
+	m.logManager.Subscribe(m.alerts.ObserveLog)
+	go m.checkPoolsLoop()
+
 	// Start log manager
 	if err := m.logManager.Start(); err != nil {
-		log.Printf("Warning: failed to start log manager: %v", err)
+		utils.Warnf("Warning: failed to start log manager: %v", err)
+	}
+
+	// Supervise dnsmasq unless systemd manages it
+	if !m.cfg.SystemD {
+		if err := m.dnsmasq.Start(); err != nil {
+			utils.Warnf("Warning: failed to start dnsmasq: %v", err)
+		}
 	}
+	go m.watchDNSMasq()
 
 	return nil
 }
@@ -98,18 +175,18 @@ func (m *Monitor) addFileToWatcher(filePath, description string) {
 
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		log.Printf("Warning: %s does not exist: %s", description, filePath)
+		utils.Warnf("Warning: %s does not exist: %s", description, filePath)
 
 		// Try to create parent directory and empty file
 		if err := m.ensureFileExists(filePath); err != nil {
-			log.Printf("Warning: could not create %s: %v", description, err)
+			utils.Warnf("Warning: could not create %s: %v", description, err)
 			return
 		}
 	}
 
 	// Add to watcher
 	if err := m.watcher.Add(filePath); err != nil {
-		log.Printf("Warning: failed to watch %s (%s): %v", description, filePath, err)
+		utils.Warnf("Warning: failed to watch %s (%s): %v", description, filePath, err)
 	} else {
 	}
 }
@@ -129,7 +206,7 @@ func (m *Monitor) ensureFileExists(filePath string) error {
 			return fmt.Errorf("failed to create file %s: %w", filePath, err)
 		}
 		file.Close()
-		log.Printf("Created empty file: %s", filePath)
+		utils.Infof("Created empty file: %s", filePath)
 	}
 
 	return nil
@@ -145,8 +222,17 @@ func (m *Monitor) watchFiles() {
 				return
 			}
 
+			// Vendor files are often replaced by rename, so their
+			// directories are watched and any change is picked up
+			if absEventPath, _ := filepath.Abs(event.Name); m.macFiles[absEventPath] {
+				if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename) != 0 {
+					m.scheduleMACReload()
+				}
+				continue
+			}
+
 			if event.Op&fsnotify.Write == fsnotify.Write {
-				log.Printf("File modified: %s", event.Name)
+				utils.Infof("File modified: %s", event.Name)
 
 				// Use absolute paths for comparison
 				absEventPath, _ := filepath.Abs(event.Name)
@@ -157,16 +243,18 @@ func (m *Monitor) watchFiles() {
 				switch absEventPath {
 				case absLeasesPath:
 					if err := m.loadDHCPLeases(); err != nil {
-						log.Printf("Error reloading DHCP leases: %v", err)
+						utils.Errorf("Error reloading DHCP leases: %v", err)
 					}
+					m.checkPools()
 				case absHostsPath:
-					if err := m.loadHostEntries(); err != nil {
-						log.Printf("Error reloading host entries: %v", err)
+					if err := m.hostsManager.Load(); err != nil {
+						utils.Errorf("Error reloading host entries: %v", err)
 					}
 				case absStaticPath:
-					if err := m.staticManager.Load(); err != nil {
-						log.Printf("Error reloading static entries: %v", err)
+					if err := m.ReloadStaticEntries(); err != nil {
+						utils.Errorf("Error reloading static entries: %v", err)
 					}
+					m.checkPools()
 				}
 			}
 
@@ -174,7 +262,7 @@ func (m *Monitor) watchFiles() {
 			if !ok {
 				return
 			}
-			log.Printf("File watcher error: %v", err)
+			utils.Errorf("File watcher error: %v", err)
 
 		case <-m.stopCh:
 			return
@@ -182,15 +270,66 @@ func (m *Monitor) watchFiles() {
 	}
 }
 
+// watchMACDatabase watches the directories holding the vendor files so
+// that files replaced atomically (written elsewhere, then renamed) are
+// noticed as well as files rewritten in place. It fills macFiles, so it
+// must run before watchFiles starts.
+func (m *Monitor) watchMACDatabase() {
+	dirs := make(map[string]bool)
+	for _, file := range m.macDB.Files() {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		m.macFiles[abs] = true
+
+		dir := filepath.Dir(abs)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := m.watcher.Add(dir); err != nil {
+			utils.Warnf("Warning: failed to watch MAC database directory %s: %v", dir, err)
+		}
+	}
+}
+
+// scheduleMACReload reloads the vendor database once its files have
+// been quiet for macReloadDelay
+func (m *Monitor) scheduleMACReload() {
+	m.macTimerMu.Lock()
+	defer m.macTimerMu.Unlock()
+
+	if m.macTimer != nil {
+		m.macTimer.Stop()
+	}
+	m.macTimer = time.AfterFunc(macReloadDelay, func() {
+		if err := m.ReloadMACDatabase(); err != nil {
+			utils.Errorf("Error reloading MAC database: %v", err)
+		}
+	})
+}
+
 // Stop stops monitoring
 func (m *Monitor) Stop() {
 	close(m.stopCh)
+	m.macTimerMu.Lock()
+	if m.macTimer != nil {
+		m.macTimer.Stop()
+	}
+	m.macTimerMu.Unlock()
 	if m.watcher != nil {
 		m.watcher.Close()
 	}
+	if !m.cfg.SystemD && m.dnsmasq != nil {
+		m.dnsmasq.Stop()
+	}
 	if m.logManager != nil {
 		m.logManager.Stop()
 	}
+	m.devices.Stop()
+	m.alerts.Stop()
+	m.notify.Stop()
 }
 
 // GetDHCPLeases returns current DHCP leases
@@ -212,12 +351,51 @@ func (m *Monitor) GetDHCPLeases() []models.DHCPLease {
 
 // GetHostEntries returns current host entries
 func (m *Monitor) GetHostEntries() []models.HostEntry {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	
-	entries := make([]models.HostEntry, len(m.hostEntries))
-	copy(entries, m.hostEntries)
-	return entries
+	return m.hostsManager.GetAll()
+}
+
+// GetHostEntryByID returns a host entry by ID
+func (m *Monitor) GetHostEntryByID(id string) (*models.HostEntry, error) {
+	return m.hostsManager.GetByID(id)
+}
+
+// AddHostEntry adds a new host entry
+func (m *Monitor) AddHostEntry(entry models.HostEntry) error {
+	return m.hostsManager.Add(entry)
+}
+
+// UpdateHostEntry updates an existing host entry
+func (m *Monitor) UpdateHostEntry(id string, entry models.HostEntry) error {
+	return m.hostsManager.Update(id, entry)
+}
+
+// DeleteHostEntry deletes a host entry
+func (m *Monitor) DeleteHostEntry(id string) error {
+	return m.hostsManager.Delete(id)
+}
+
+// SaveHostEntries writes host entries to the hosts file. The file is
+// replaced by rename, which drops its watch, so it is watched again.
+func (m *Monitor) SaveHostEntries() error {
+	if err := m.hostsManager.Save(); err != nil {
+		return err
+	}
+	if m.watcher != nil {
+		if err := m.watcher.Add(m.cfg.HostsFile); err != nil {
+			utils.Warnf("Warning: failed to watch hosts file (%s): %v", m.cfg.HostsFile, err)
+		}
+	}
+	return nil
+}
+
+// ReloadHostEntries reloads host entries from the hosts file
+func (m *Monitor) ReloadHostEntries() error {
+	return m.hostsManager.Load()
+}
+
+// ValidateHostEntries validates all host entries
+func (m *Monitor) ValidateHostEntries() []error {
+	return m.hostsManager.Validate()
 }
 
 // GetLogs returns current logs
@@ -225,6 +403,38 @@ func (m *Monitor) GetLogs() []models.LogEntry {
 	return m.logManager.GetLogs()
 }
 
+// QueryLogs searches the persistent log store
+func (m *Monitor) QueryLogs(q logs.Query) (logs.Result, error) {
+	return m.logManager.QueryLogs(q)
+}
+
+// ===== dnsmasq Service Methods =====
+
+// DNSMasqStatus returns the state of the dnsmasq service
+func (m *Monitor) DNSMasqStatus() dnsmasq.Status {
+	return m.dnsmasq.Status()
+}
+
+// StartDNSMasq starts the dnsmasq service
+func (m *Monitor) StartDNSMasq() error {
+	return m.dnsmasq.Start()
+}
+
+// StopDNSMasq stops the dnsmasq service
+func (m *Monitor) StopDNSMasq() error {
+	return m.dnsmasq.Stop()
+}
+
+// RestartDNSMasq restarts the dnsmasq service
+func (m *Monitor) RestartDNSMasq() error {
+	return m.dnsmasq.Restart()
+}
+
+// ReloadDNSMasq asks dnsmasq to re-read its hosts and option files
+func (m *Monitor) ReloadDNSMasq() error {
+	return m.dnsmasq.Reload()
+}
+
 // ===== Static DHCP Management Methods =====
 
 // GetStaticEntries returns all static DHCP entries
@@ -239,36 +449,50 @@ func (m *Monitor) GetStaticEntryByID(id string) (*models.StaticDHCPEntry, error)
 
 // AddStaticEntry adds a new static DHCP entry
 func (m *Monitor) AddStaticEntry(entry models.StaticDHCPEntry) error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
 	return m.staticManager.Add(entry)
 }
 
 // UpdateStaticEntry updates an existing static DHCP entry
 func (m *Monitor) UpdateStaticEntry(id string, entry models.StaticDHCPEntry) error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
 	return m.staticManager.Update(id, entry)
 }
 
 // DeleteStaticEntry deletes a static DHCP entry
 func (m *Monitor) DeleteStaticEntry(id string) error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
 	return m.staticManager.Delete(id)
 }
 
 // EnableStaticEntry enables a static DHCP entry
 func (m *Monitor) EnableStaticEntry(id string) error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
 	return m.staticManager.Enable(id)
 }
 
 // DisableStaticEntry disables a static DHCP entry
 func (m *Monitor) DisableStaticEntry(id string) error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
 	return m.staticManager.Disable(id)
 }
 
 // SaveStaticEntries saves static DHCP entries to file
 func (m *Monitor) SaveStaticEntries() error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
 	return m.staticManager.Save()
 }
 
 // ReloadStaticEntries reloads static DHCP entries from file
 func (m *Monitor) ReloadStaticEntries() error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
 	return m.staticManager.Load()
 }
 
@@ -297,6 +521,191 @@ func (m *Monitor) GetStaticEntriesByIP(ip string) ([]models.StaticDHCPEntry, err
 	return m.staticManager.GetByIP(parsedIP), nil
 }
 
+// CheckConsistency cross-validates the static reservations, the hosts
+// file and the live leases against each other and the dhcp-range options
+// of the dnsmasq configuration
+func (m *Monitor) CheckConsistency() consistency.Report {
+	conf, err := m.DHCPConfig()
+	
+	return consistency.Check(consistency.Input{
+		Static:     m.GetStaticEntries(),
+		Hosts:      m.GetHostEntries(),
+		Leases:     m.GetDHCPLeases(),
+		Ranges:     conf.Ranges,
+		RangeError: err,
+	})
+}
+
+// DHCPConfig reads the ranges, options, domains and interfaces of the
+// dnsmasq configuration. The configuration is read on every call, so
+// edits show up without a restart.
+func (m *Monitor) DHCPConfig() (*dnsmasq.DHCPConfig, error) {
+	return dnsmasq.ParseConfig(m.cfg.DNSMasqConf)
+}
+
+// GetSubnets returns the subnets dnsmasq serves, with their pools and
+// options
+func (m *Monitor) GetSubnets() ([]dnsmasq.Subnet, *dnsmasq.DHCPConfig, error) {
+	conf, err := m.DHCPConfig()
+	if err != nil {
+		return nil, conf, err
+	}
+	return conf.Subnets(dnsmasq.LocalNetworks()), conf, nil
+}
+
+// GetIPUsage returns the pool utilization of every subnet, with the
+// address grid of each small IPv4 subnet when grid is set
+func (m *Monitor) GetIPUsage(grid bool) ([]ipam.SubnetUsage, error) {
+	in, err := m.ipamInput()
+	if err != nil {
+		return nil, err
+	}
+	return ipam.Analyze(in, grid), nil
+}
+
+// SuggestStaticIP returns a free address outside the dynamic pools for a
+// new static reservation, and the subnet it belongs to
+func (m *Monitor) SuggestStaticIP(hint ipam.Hint) (net.IP, string, error) {
+	in, err := m.ipamInput()
+	if err != nil {
+		return nil, "", err
+	}
+	ip, subnet, err := ipam.Suggest(in, hint)
+	if err != nil {
+		return nil, "", err
+	}
+	return ip, subnet.Network.String(), nil
+}
+
+// GetAlerts returns the active alerts and the recent alert changes
+func (m *Monitor) GetAlerts() (active, history []alerts.Alert) {
+	return m.alerts.Active(), m.alerts.History()
+}
+
+// AlertThresholds returns the pool utilization percentages raising
+// warning and critical alerts
+func (m *Monitor) AlertThresholds() (warn, crit float64) {
+	return m.alerts.Thresholds()
+}
+
+// AddNotifier registers a channel receiving alert notifications
+func (m *Monitor) AddNotifier(n alerts.Notifier) {
+	m.alerts.AddNotifier(n)
+}
+
+// checkPools evaluates pool utilization alerts against the current usage
+func (m *Monitor) checkPools() {
+	usage, err := m.GetIPUsage(false)
+	if err != nil {
+		// The IPAM page and consistency check report configuration errors
+		return
+	}
+	m.alerts.EvaluatePools(usage)
+}
+
+// checkPoolsLoop checks pool utilization at startup and every
+// poolCheckInterval until the monitor stops
+func (m *Monitor) checkPoolsLoop() {
+	m.checkPools()
+
+	ticker := time.NewTicker(poolCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.checkPools()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+// ipamInput collects the subnets and address assignments
+func (m *Monitor) ipamInput() (ipam.Input, error) {
+	subnets, conf, err := m.GetSubnets()
+	if err != nil {
+		return ipam.Input{}, err
+	}
+	
+	// Servers named in any dhcp-option, and the addresses of this host,
+	// are in use even when no lease or reservation names them
+	var exclude []net.IP
+	for _, opt := range conf.Options {
+		for _, v := range opt.Values {
+			if ip := net.ParseIP(v); ip != nil {
+				exclude = append(exclude, ip)
+			}
+		}
+	}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				exclude = append(exclude, ipNet.IP)
+			}
+		}
+	}
+	
+	return ipam.Input{
+		Subnets: subnets,
+		Static:  m.GetStaticEntries(),
+		Leases:  m.GetDHCPLeases(),
+		Hosts:   m.GetHostEntries(),
+		Exclude: exclude,
+	}, nil
+}
+
+// GetDevices returns the known devices, with the private MACs of each
+// device grouped together
+func (m *Monitor) GetDevices() []devices.Device {
+	return m.devices.Devices()
+}
+
+// GetLabels returns all device labels
+func (m *Monitor) GetLabels() []models.Label {
+	return m.labels.List()
+}
+
+// SetLabel adds or replaces a device label
+func (m *Monitor) SetLabel(label models.Label) (models.Label, error) {
+	return m.labels.Set(label)
+}
+
+// DeleteLabel removes the label with the given MAC or prefix key
+func (m *Monitor) DeleteLabel(key string) error {
+	return m.labels.Delete(key)
+}
+
+// ApplyLabel returns the vendor information for mac with any label
+// override applied, and the matching label if there is one
+func (m *Monitor) ApplyLabel(mac string, info *models.OUIEntry) (*models.OUIEntry, *models.Label) {
+	return m.labels.Apply(mac, info)
+}
+
+// MACDatabaseStats reports the loaded vendor sources
+func (m *Monitor) MACDatabaseStats() mac.Stats {
+	return m.macDB.Stats()
+}
+
+// ReloadMACDatabase reloads the vendor files and re-resolves the vendor
+// of every current lease
+func (m *Monitor) ReloadMACDatabase() error {
+	if err := m.macDB.Reload(); err != nil {
+		return err
+	}
+	return m.loadDHCPLeases()
+}
+
+// ImportMACDatabase installs an uploaded vendor file as dest and
+// re-resolves the vendor of every current lease
+func (m *Monitor) ImportMACDatabase(r io.Reader, dest string) (mac.SourceStatus, error) {
+	status, err := m.macDB.Import(r, dest)
+	if err != nil {
+		return status, err
+	}
+	return status, m.loadDHCPLeases()
+}
+
 // loadDHCPLeases loads DHCP leases from file
 func (m *Monitor) loadDHCPLeases() error {
 	content, err := os.ReadFile(m.cfg.LeasesFile)
@@ -314,32 +723,9 @@ func (m *Monitor) loadDHCPLeases() error {
 	m.dhcpLeases = leases
 	m.mu.Unlock()
 	
-	log.Printf("Loaded %d DHCP leases", len(leases))
-	return nil
-}
-
-// loadHostEntries loads host entries from file
-func (m *Monitor) loadHostEntries() error {
-	content, err := os.ReadFile(m.cfg.HostsFile)
-	if err != nil {
-		return fmt.Errorf("failed to read hosts file: %w", err)
-	}
-	
-	entries, err := m.hostsParser.ParseHosts(string(content))
-	if err != nil {
-		return fmt.Errorf("failed to parse hosts: %w", err)
-	}
+	m.devices.ObserveLeases(leases)
 	
-	m.mu.Lock()
-	m.hostEntries = entries
-	m.mu.Unlock()
-	
-	log.Printf("Loaded %d host entries", len(entries))
+	utils.Infof("Loaded %d DHCP leases", len(leases))
 	return nil
 }
 
-
-// GetSystemdLogs returns logs from systemd journal
-func (m *Monitor) GetSystemdLogs() ([]models.LogEntry, error) {
-	return m.logManager.GetSystemdLogs()
-}
@@ -0,0 +1,203 @@
+// ===== internal/monitor/notify.go =====
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dhcpmon/internal/alerts"
+	"dhcpmon/internal/devices"
+	"dhcpmon/internal/dnsmasq"
+	"dhcpmon/internal/notify"
+	"dhcpmon/pkg/models"
+)
+
+// dnsmasqCheckInterval is how often the dnsmasq service is checked for
+// crashes
+const dnsmasqCheckInterval = 15 * time.Second
+
+// NotifyStatus reports on the configured notification channels
+func (m *Monitor) NotifyStatus() []notify.ChannelStatus {
+	return m.notify.Status()
+}
+
+// TestNotify sends a test message to the named notification channel
+func (m *Monitor) TestNotify(name string) error {
+	return m.notify.Test(name)
+}
+
+// notifyAlert passes pool alerts on to the notification channels
+func (m *Monitor) notifyAlert(a alerts.Alert) error {
+	e := notify.Event{
+		Type:    notify.EventAlert,
+		Level:   a.Level,
+		Message: a.Message,
+		Time:    a.UpdatedAt,
+		Fields: map[string]string{
+			"key":    a.Key,
+			"source": a.Source,
+			"state":  "active",
+		},
+	}
+	subject := a.Pool
+	if subject == "" {
+		subject = a.Interface
+	}
+	e.Title = fmt.Sprintf("%s%s alert: %s", strings.ToUpper(a.Level[:1]), a.Level[1:], subject)
+	if a.Resolved() {
+		e.Level = notify.LevelInfo
+		e.Title = "Resolved: " + subject
+		e.Fields["state"] = "resolved"
+	}
+	for name, value := range map[string]string{"subnet": a.Subnet, "pool": a.Pool, "interface": a.Interface} {
+		if value != "" {
+			e.Fields[name] = value
+		}
+	}
+	if a.Source == alerts.SourcePool {
+		e.Fields["utilization"] = fmt.Sprintf("%.1f", a.Utilization)
+	}
+
+	m.notify.Notify(e)
+	return nil
+}
+
+// notifyNewDevice reports a MAC address seen for the first time, with
+// its approval state. A blocked device coming back is a warning.
+func (m *Monitor) notifyNewDevice(s devices.Sighting, approval models.Approval) {
+	name := s.Hostname
+	if label, ok := m.labels.Lookup(s.MAC); ok && label.Name != "" {
+		name = label.Name
+	}
+	if name == "" {
+		name = "unknown device"
+	}
+
+	details := []string{s.MAC}
+	if s.Vendor != "" {
+		details = append(details, s.Vendor)
+	}
+	if s.Private {
+		details = append(details, "private MAC")
+	}
+	message := fmt.Sprintf("New device %s (%s)", name, strings.Join(details, ", "))
+	if s.IP != "" {
+		message += " got " + s.IP
+	}
+
+	level, title := notify.LevelInfo, "New device: "+name
+	switch approval.State {
+	case models.ApprovalPending:
+		message += "; it is awaiting approval"
+	case models.ApprovalBlocked:
+		level, title = notify.LevelWarning, "Blocked device is back: "+name
+		message = fmt.Sprintf("Blocked device %s (%s) got a lease", name, strings.Join(details, ", "))
+		if s.IP != "" {
+			message += " for " + s.IP
+		}
+	}
+
+	m.notify.Notify(notify.Event{
+		Type:    notify.EventNewDevice,
+		Level:   level,
+		Title:   title,
+		Message: message,
+		Fields: map[string]string{
+			"mac":      s.MAC,
+			"ip":       s.IP,
+			"hostname": s.Hostname,
+			"vendor":   s.Vendor,
+			"approval": approval.State,
+		},
+	})
+}
+
+// watchDNSMasq reports dnsmasq crashes: unexpected exits of the
+// supervised process, or restarts and failures of the systemd unit
+func (m *Monitor) watchDNSMasq() {
+	status := m.dnsmasq.Status()
+	lastExit := time.Now()
+	restarts := status.Restarts
+	failed := strings.HasPrefix(status.State, "failed")
+
+	ticker := time.NewTicker(dnsmasqCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.stopCh:
+			return
+		}
+
+		status := m.dnsmasq.Status()
+		for _, record := range status.History {
+			if !record.ExitedAt.After(lastExit) {
+				continue
+			}
+			lastExit = record.ExitedAt
+			if record.Crash {
+				m.notify.Notify(dnsmasqExitEvent(record, status))
+			}
+		}
+
+		if status.Mode == "systemd" {
+			if status.Restarts > restarts {
+				m.notify.Notify(notify.Event{
+					Type:    notify.EventDNSMasq,
+					Level:   notify.LevelWarning,
+					Title:   "dnsmasq restarted",
+					Message: fmt.Sprintf("systemd restarted %s after a failure (%d restarts)", status.Service, status.Restarts),
+					Fields:  map[string]string{"state": status.State},
+				})
+			}
+			isFailed := strings.HasPrefix(status.State, "failed")
+			if isFailed && !failed {
+				m.notify.Notify(notify.Event{
+					Type:    notify.EventDNSMasq,
+					Level:   notify.LevelCritical,
+					Title:   "dnsmasq failed",
+					Message: fmt.Sprintf("%s is %s", status.Service, status.State),
+					Fields:  map[string]string{"state": status.State},
+				})
+			}
+			failed = isFailed
+		}
+		restarts = status.Restarts
+	}
+}
+
+// dnsmasqExitEvent describes an unexpected exit of supervised dnsmasq
+func dnsmasqExitEvent(record dnsmasq.ExitRecord, status dnsmasq.Status) notify.Event {
+	reason := fmt.Sprintf("exit code %d", record.ExitCode)
+	switch {
+	case record.Error != "":
+		reason = record.Error
+	case record.Signal != "":
+		reason = "signal " + record.Signal
+	}
+
+	message := fmt.Sprintf("dnsmasq (pid %d) exited unexpectedly: %s", record.PID, reason)
+	level := notify.LevelWarning
+	if status.NextRestart != nil {
+		message += fmt.Sprintf("; restarting in %s", time.Until(*status.NextRestart).Round(time.Second))
+	} else if !status.Running {
+		message += "; it will not be restarted"
+		level = notify.LevelCritical
+	}
+
+	return notify.Event{
+		Type:    notify.EventDNSMasq,
+		Level:   level,
+		Title:   "dnsmasq crashed",
+		Message: message,
+		Time:    record.ExitedAt,
+		Fields: map[string]string{
+			"pid":      fmt.Sprint(record.PID),
+			"exitCode": fmt.Sprint(record.ExitCode),
+			"signal":   record.Signal,
+			"restarts": fmt.Sprint(status.Restarts),
+		},
+	}
+}
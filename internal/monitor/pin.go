@@ -0,0 +1,142 @@
+// ===== internal/monitor/pin.go =====
+package monitor
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"dhcpmon/internal/ipam"
+	"dhcpmon/internal/static"
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// PinRequest describes a lease to turn into a static reservation
+type PinRequest struct {
+	MAC      string
+	Hostname string // Overrides the lease's hostname
+	Comment  string
+	Move     bool // Reserve a free address outside the dynamic pools instead of the current one
+}
+
+// PinLease turns the current dynamic lease of a MAC address into a
+// static reservation with its IP and hostname. With Move, the
+// reservation gets the suggested address in the same subnet instead; the
+// device moves there when it renews. The reservation is added, validated,
+// saved and dnsmasq restarted as one transaction.
+func (m *Monitor) PinLease(req PinRequest) (models.StaticDHCPEntry, error) {
+	hw, err := net.ParseMAC(req.MAC)
+	if err != nil {
+		return models.StaticDHCPEntry{}, fmt.Errorf("invalid MAC address: %w", err)
+	}
+
+	for _, e := range m.staticManager.GetByMAC(hw) {
+		if e.Enabled {
+			return models.StaticDHCPEntry{}, fmt.Errorf("%s already has a static reservation", e.GetFormattedMAC())
+		}
+	}
+
+	var lease *models.DHCPLease
+	for _, l := range m.GetDHCPLeases() {
+		if !l.Static && l.MAC != nil && l.MAC.String() == hw.String() && l.IP != nil {
+			current := l
+			lease = &current
+		}
+	}
+	if lease == nil {
+		return models.StaticDHCPEntry{}, fmt.Errorf("no dynamic lease for %s", strings.ToUpper(hw.String()))
+	}
+
+	entry := models.StaticDHCPEntry{
+		MAC:      hw,
+		IP:       lease.IP,
+		Hostname: req.Hostname,
+		Comment:  req.Comment,
+		Enabled:  true,
+	}
+	if entry.Hostname == "" && lease.Name != "*" {
+		entry.Hostname = lease.Name
+	}
+	if req.Move {
+		ip, _, err := m.SuggestStaticIP(ipam.Hint{Near: lease.IP})
+		if err != nil {
+			return models.StaticDHCPEntry{}, fmt.Errorf("no address to move %s to: %w", entry.GetFormattedMAC(), err)
+		}
+		entry.IP = ip
+	}
+
+	if err := m.applyStatic(func() error { return m.staticManager.Add(entry) }); err != nil {
+		return models.StaticDHCPEntry{}, err
+	}
+
+	for _, e := range m.staticManager.GetByMAC(hw) {
+		if e.Enabled && e.IP.Equal(entry.IP) {
+			entry = e
+		}
+	}
+	utils.Infof("Pinned %s (%s) to %s", entry.GetFormattedMAC(), entry.Hostname, entry.IP)
+	return entry, nil
+}
+
+// applyStatic makes a change to the static entries as one transaction:
+// the change is validated, saved and a running dnsmasq restarted, and the
+// previous entries are restored and saved again if any step fails. Only
+// problems the change introduces fail validation. dnsmasq reads dhcp-host
+// lines from its configuration files only when it starts (SIGHUP re-reads
+// hosts and option files, not those), so a reload would not apply the
+// change. When dnsmasq is not running the change is only saved, which is
+// logged; it applies when dnsmasq starts. staticMu is held throughout, so
+// change must not call back into a locked method.
+func (m *Monitor) applyStatic(change func() error) error {
+	m.staticMu.Lock()
+	defer m.staticMu.Unlock()
+
+	snapshot := m.staticManager.Snapshot()
+	before := make(map[string]bool)
+	for _, p := range m.staticManager.Problems() {
+		before[problemKey(p, snapshot)] = true
+	}
+
+	if err := change(); err != nil {
+		m.staticManager.Restore(snapshot)
+		return err
+	}
+	entries := m.staticManager.GetAll()
+	for _, p := range m.staticManager.Problems() {
+		if !before[problemKey(p, entries)] {
+			m.staticManager.Restore(snapshot)
+			return fmt.Errorf("validation failed: %w", p)
+		}
+	}
+
+	if err := m.staticManager.Save(); err != nil {
+		m.staticManager.Restore(snapshot)
+		return err
+	}
+	if !m.dnsmasq.Status().Running {
+		utils.Warnf("Warning: saved the static entries, but dnsmasq is not running; they apply when it starts")
+		return nil
+	}
+	if err := m.dnsmasq.Restart(); err != nil {
+		m.staticManager.Restore(snapshot)
+		if saveErr := m.staticManager.Save(); saveErr != nil {
+			utils.Warnf("Warning: failed to roll back static entries: %v", saveErr)
+		} else if restartErr := m.dnsmasq.Restart(); restartErr != nil {
+			utils.Errorf("Failed to restart dnsmasq with the previous static entries: %v", restartErr)
+		}
+		return fmt.Errorf("dnsmasq restart failed, change rolled back: %w", err)
+	}
+	utils.Infof("Restarted dnsmasq to apply the static entries")
+	return nil
+}
+
+// problemKey identifies a validation problem by its kind and the
+// duplicated address or the invalid entry, without the entry positions
+// that change when entries are added or removed
+func problemKey(p static.Problem, entries []models.StaticDHCPEntry) string {
+	if p.Kind != static.ProblemInvalid {
+		return p.Kind + " " + p.Value
+	}
+	return p.Kind + " " + static.ReservationKey(entries[p.Index])
+}
@@ -0,0 +1,109 @@
+// ===== internal/monitor/renumber.go =====
+package monitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"dhcpmon/internal/hosts"
+	"dhcpmon/internal/static"
+	"dhcpmon/pkg/utils"
+)
+
+// RenumberResult describes a renumbering of the static reservations and
+// the hosts file. When Errors is not empty nothing was changed.
+type RenumberResult struct {
+	DryRun  bool                    `json:"dryRun"`
+	Applied bool                    `json:"applied"`
+	Static  []static.RenumberChange `json:"static"`
+	Hosts   []hosts.RenumberChange  `json:"hosts"`
+	Diff    string                  `json:"diff"`
+	Errors  []string                `json:"errors"`
+}
+
+// RenumberStatic moves the static reservations, and the hosts entries
+// when updateHosts is set, as plan maps them. Both files are planned
+// first and the plan returned without changing anything when dryRun is
+// set, when there are errors or when nothing moves. Otherwise the change
+// is saved and dnsmasq restarted as one transaction; the hosts file is
+// restored with the static entries if any step fails.
+func (m *Monitor) RenumberStatic(plan static.RenumberPlan, updateHosts, dryRun bool) (*RenumberResult, error) {
+	staticPlan := m.staticManager.Renumber(plan, true)
+	result := &RenumberResult{
+		DryRun: dryRun,
+		Static: staticPlan.Changes,
+		Hosts:  []hosts.RenumberChange{},
+		Errors: staticPlan.Errors,
+	}
+	if updateHosts {
+		changes, errs := m.hostsManager.Renumber(plan.Map, true)
+		result.Hosts = changes
+		for _, e := range errs {
+			result.Errors = append(result.Errors, "hosts: "+e)
+		}
+	}
+	result.Diff = m.renumberDiff(result)
+
+	if dryRun || len(result.Errors) > 0 || (len(result.Static) == 0 && len(result.Hosts) == 0) {
+		return result, nil
+	}
+
+	var hostsSnapshot []byte
+	if len(result.Hosts) > 0 {
+		var err error
+		if hostsSnapshot, err = m.hostsManager.Snapshot(); err != nil {
+			return nil, err
+		}
+	}
+
+	err := m.applyStatic(func() error {
+		if r := m.staticManager.Renumber(plan, false); len(r.Errors) > 0 {
+			return fmt.Errorf("%s", r.Errors[0])
+		}
+		if hostsSnapshot == nil {
+			return nil
+		}
+		if _, errs := m.hostsManager.Renumber(plan.Map, false); len(errs) > 0 {
+			return fmt.Errorf("hosts: %s", errs[0])
+		}
+		return m.SaveHostEntries()
+	})
+	if err != nil {
+		if hostsSnapshot != nil {
+			if restoreErr := m.hostsManager.Restore(hostsSnapshot); restoreErr != nil {
+				utils.Warnf("Warning: failed to roll back hosts file: %v", restoreErr)
+			} else if m.watcher != nil {
+				if err := m.watcher.Add(m.cfg.HostsFile); err != nil {
+					utils.Warnf("Warning: failed to watch hosts file (%s): %v", m.cfg.HostsFile, err)
+				}
+			}
+		}
+		return nil, err
+	}
+
+	result.Applied = true
+	utils.Infof("Renumbered %d static reservations and %d host entries", len(result.Static), len(result.Hosts))
+	return result, nil
+}
+
+// renumberDiff renders the changed lines of both files as a unified diff
+// without context
+func (m *Monitor) renumberDiff(result *RenumberResult) string {
+	var b strings.Builder
+	if len(result.Static) > 0 {
+		name := filepath.Base(m.cfg.StaticFile)
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+		for _, c := range result.Static {
+			fmt.Fprintf(&b, "-%s\n+%s\n", c.Before, c.After)
+		}
+	}
+	if len(result.Hosts) > 0 {
+		name := filepath.Base(m.cfg.HostsFile)
+		fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+		for _, c := range result.Hosts {
+			fmt.Fprintf(&b, "-%s\n+%s\n", c.Before, c.After)
+		}
+	}
+	return b.String()
+}
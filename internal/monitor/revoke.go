@@ -0,0 +1,118 @@
+// ===== internal/monitor/revoke.go =====
+package monitor
+
+import (
+	"fmt"
+	"net"
+
+	"dhcpmon/internal/dhcp"
+	"dhcpmon/internal/dnsmasq"
+	"dhcpmon/pkg/utils"
+)
+
+// Ways a lease is revoked
+const (
+	RevokeRelease   = "dhcp_release" // dnsmasq released it on the client's behalf
+	RevokeLeaseFile = "leases file"  // Removed from the leases file while dnsmasq was stopped
+)
+
+// RevokeLease ends the dynamic lease of ip, so the device has to ask for
+// an address again. dhcp_release or dhcp_release6 is used when
+// installed. Otherwise, when dnsmasq runs under the supervisor, it is
+// stopped while the lease is removed from the leases file. The lease list
+// refreshes when dnsmasq rewrites the file. It returns how the lease was
+// revoked.
+func (m *Monitor) RevokeLease(ipStr string) (string, error) {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return "", fmt.Errorf("invalid IP address: %s", ipStr)
+	}
+
+	found := false
+	for _, lease := range m.GetDHCPLeases() {
+		if !lease.Static && lease.IP != nil && lease.IP.Equal(ip) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("no dynamic lease for %s", ip)
+	}
+
+	record, err := dhcp.FindLease(m.cfg.LeasesFile, ip)
+	if err != nil {
+		return "", err
+	}
+
+	ipv6 := ip.To4() == nil
+	if m.releaser.Available(ipv6) {
+		iface := m.leaseInterface(ip)
+		if iface == "" {
+			return "", fmt.Errorf("no local interface serves %s", ip)
+		}
+		if ipv6 {
+			if record.ServerDUID == "" {
+				return "", fmt.Errorf("no server DUID in %s", m.cfg.LeasesFile)
+			}
+			err = m.releaser.Release6(iface, ip, record.HWAddr, record.ClientID, record.ServerDUID)
+		} else {
+			var hw net.HardwareAddr
+			hw, err = net.ParseMAC(record.HWAddr)
+			if err == nil {
+				err = m.releaser.Release(iface, ip, hw, record.ClientID)
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		utils.Infof("Released lease of %s (%s) on %s", ip, record.HWAddr, iface)
+		return RevokeRelease, nil
+	}
+
+	status := m.dnsmasq.Status()
+	if status.Mode != "supervised" {
+		helper := m.cfg.DHCPRelease
+		if ipv6 {
+			helper = m.cfg.DHCPRelease6
+		}
+		return "", fmt.Errorf("%s is not installed; it is needed to revoke leases while dnsmasq runs under systemd", helper)
+	}
+
+	if status.Running {
+		if err := m.dnsmasq.Stop(); err != nil {
+			return "", err
+		}
+	}
+	err = dhcp.RemoveLease(m.cfg.LeasesFile, ip)
+	if status.Running {
+		if startErr := m.dnsmasq.Start(); startErr != nil {
+			utils.Warnf("Warning: failed to start dnsmasq after revoking the lease of %s: %v", ip, startErr)
+			if err == nil {
+				err = fmt.Errorf("lease removed, but dnsmasq failed to start: %w", startErr)
+			}
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	utils.Infof("Removed lease of %s (%s) from %s", ip, record.HWAddr, m.cfg.LeasesFile)
+	return RevokeLeaseFile, nil
+}
+
+// leaseInterface returns the local interface on the network of ip, or
+// the interface dnsmasq is configured to serve it on
+func (m *Monitor) leaseInterface(ip net.IP) string {
+	for _, network := range dnsmasq.LocalNetworks() {
+		if network.Network.Contains(ip) {
+			return network.Name
+		}
+	}
+	if subnets, _, err := m.GetSubnets(); err == nil {
+		for _, subnet := range subnets {
+			if subnet.Network != nil && subnet.Network.Contains(ip) && subnet.Interface != "" {
+				return subnet.Interface
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,214 @@
+// ===== internal/notify/http.go =====
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"dhcpmon/internal/config"
+)
+
+// maxErrorBody is how much of an error response is quoted in the error
+const maxErrorBody = 512
+
+// Webhook posts each event as JSON to a URL
+type Webhook struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+// NewWebhook creates a generic JSON webhook
+func NewWebhook(cfg config.NotifyChannel) (*Webhook, error) {
+	if err := checkURL(cfg.URL, "http", "https"); err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(cfg.Headers)
+	if err != nil {
+		return nil, err
+	}
+	return &Webhook{url: cfg.URL, headers: headers, client: &http.Client{}}, nil
+}
+
+// Send posts the event with its rendered title and message
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg.Payload())
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	headers := w.headers.Clone()
+	headers.Set("Content-Type", "application/json")
+	return post(ctx, w.client, w.url, headers, body)
+}
+
+// Ntfy publishes to an ntfy topic URL, such as https://ntfy.sh/mytopic
+type Ntfy struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+// NewNtfy creates an ntfy publisher
+func NewNtfy(cfg config.NotifyChannel) (*Ntfy, error) {
+	if err := checkURL(cfg.URL, "http", "https"); err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(cfg.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token != "" {
+		headers.Set("Authorization", "Bearer "+cfg.Token)
+	}
+	return &Ntfy{url: cfg.URL, headers: headers, client: &http.Client{}}, nil
+}
+
+// Send publishes the message body, with the title, priority and tags in
+// headers
+func (n *Ntfy) Send(ctx context.Context, msg Message) error {
+	headers := n.headers.Clone()
+	headers.Set("Content-Type", "text/plain; charset=utf-8")
+	if msg.Subject != "" {
+		headers.Set("Title", msg.Subject)
+	}
+	switch msg.Level {
+	case LevelCritical:
+		headers.Set("Priority", "urgent")
+		headers.Set("Tags", "rotating_light,"+msg.Type)
+	case LevelWarning:
+		headers.Set("Priority", "high")
+		headers.Set("Tags", "warning,"+msg.Type)
+	default:
+		headers.Set("Tags", msg.Type)
+	}
+	return post(ctx, n.client, n.url, headers, []byte(msg.Body))
+}
+
+// Gotify posts to a Gotify server's message API
+type Gotify struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+}
+
+// NewGotify creates a Gotify client. The URL is the server address; the
+// /message path is added when missing.
+func NewGotify(cfg config.NotifyChannel) (*Gotify, error) {
+	if err := checkURL(cfg.URL, "http", "https"); err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(cfg.Headers)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token != "" {
+		headers.Set("X-Gotify-Key", cfg.Token)
+	}
+
+	u, _ := url.Parse(cfg.URL)
+	if !strings.HasSuffix(u.Path, "/message") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/message"
+	}
+	return &Gotify{url: u.String(), headers: headers, client: &http.Client{}}, nil
+}
+
+// Send posts the message with a priority derived from the level
+func (g *Gotify) Send(ctx context.Context, msg Message) error {
+	priority := 2
+	switch msg.Level {
+	case LevelCritical:
+		priority = 8
+	case LevelWarning:
+		priority = 5
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"title":    msg.Subject,
+		"message":  msg.Body,
+		"priority": priority,
+		"extras": map[string]interface{}{
+			"dhcpmon::event": msg.Payload(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	headers := g.headers.Clone()
+	headers.Set("Content-Type", "application/json")
+	return post(ctx, g.client, g.url, headers, body)
+}
+
+// post sends body to url and fails unless the response is a 2xx
+func post(ctx context.Context, client *http.Client, url string, headers http.Header, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = headers
+	req.Header.Set("User-Agent", "dhcpmon")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("server returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// parseHeaders parses "Name: value" header settings
+func parseHeaders(lines []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", line)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// checkURL parses a channel URL and checks its scheme
+func checkURL(raw string, schemes ...string) error {
+	if raw == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			if u.Host == "" {
+				return fmt.Errorf("url %s has no host", redactURL(raw))
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("url %s must use %s", redactURL(raw), strings.Join(schemes, " or "))
+}
+
+// redactURL hides the password and query string of a URL, which often
+// carry credentials
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "..."
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,242 @@
+// ===== internal/notify/mqtt.go =====
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"dhcpmon/internal/config"
+)
+
+// MQTT 3.1.1 control packet types, in the high nibble of the first byte
+const (
+	mqttConnect    = 0x10
+	mqttConnack    = 0x20
+	mqttPublish    = 0x30
+	mqttPuback     = 0x40
+	mqttDisconnect = 0xe0
+)
+
+// mqttKeepAlive is the keep-alive announced in CONNECT; each message uses
+// its own short connection
+const mqttKeepAlive = 30
+
+// mqttConnackErrors explains the CONNACK return codes
+var mqttConnackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// MQTT publishes each event as JSON to a topic with QoS 1. It opens a
+// connection per message, which suits the low rate of notifications.
+type MQTT struct {
+	addr     string
+	host     string
+	tls      bool
+	topic    string
+	clientID string
+	username string
+	password string
+	retain   bool
+}
+
+// NewMQTT creates an MQTT publisher for mqtt:// (port 1883) or mqtts://
+// (port 8883) brokers
+func NewMQTT(cfg config.NotifyChannel) (*MQTT, error) {
+	if err := checkURL(cfg.URL, "mqtt", "mqtts"); err != nil {
+		return nil, err
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+	u, _ := url.Parse(cfg.URL)
+
+	m := &MQTT{
+		host:     u.Hostname(),
+		tls:      u.Scheme == "mqtts",
+		topic:    cfg.Topic,
+		clientID: cfg.ClientID,
+		username: cfg.Username,
+		password: cfg.Password,
+		retain:   cfg.Retain,
+	}
+	if m.username == "" && u.User != nil {
+		m.username = u.User.Username()
+		m.password, _ = u.User.Password()
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "1883"
+		if m.tls {
+			port = "8883"
+		}
+	}
+	m.addr = net.JoinHostPort(m.host, port)
+	return m, nil
+}
+
+// Send connects, publishes the event and waits for the broker's PUBACK
+func (m *MQTT) Send(ctx context.Context, msg Message) error {
+	payload, err := json.Marshal(msg.Payload())
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	if m.tls {
+		conn, err = tls.DialWithDialer(dialer, "tcp", m.addr, &tls.Config{ServerName: m.host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", m.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", m.addr, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+	r := bufio.NewReader(conn)
+
+	if _, err := conn.Write(m.connectPacket()); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+	kind, body, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if kind != mqttConnack || len(body) != 2 {
+		return fmt.Errorf("expected CONNACK, got packet type %#x", kind)
+	}
+	if code := body[1]; code != 0 {
+		reason := mqttConnackErrors[code]
+		if reason == "" {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+		return fmt.Errorf("connection refused: %s", reason)
+	}
+
+	const packetID = 1
+	if _, err := conn.Write(m.publishPacket(packetID, payload)); err != nil {
+		return fmt.Errorf("failed to send PUBLISH: %w", err)
+	}
+	kind, body, err = readPacket(r)
+	if err != nil {
+		return fmt.Errorf("failed to read PUBACK: %w", err)
+	}
+	if kind != mqttPuback || len(body) != 2 || binary.BigEndian.Uint16(body) != packetID {
+		return fmt.Errorf("expected PUBACK, got packet type %#x", kind)
+	}
+
+	conn.Write([]byte{mqttDisconnect, 0})
+	return nil
+}
+
+// connectPacket builds a CONNECT packet with a clean session
+func (m *MQTT) connectPacket() []byte {
+	var body bytes.Buffer
+	writeString(&body, "MQTT")
+	body.WriteByte(4) // Protocol level 3.1.1
+
+	flags := byte(0x02) // Clean session
+	if m.username != "" {
+		flags |= 0x80
+		if m.password != "" {
+			flags |= 0x40
+		}
+	}
+	body.WriteByte(flags)
+	binary.Write(&body, binary.BigEndian, uint16(mqttKeepAlive))
+
+	writeString(&body, m.clientID)
+	if m.username != "" {
+		writeString(&body, m.username)
+		if m.password != "" {
+			writeString(&body, m.password)
+		}
+	}
+	return packet(mqttConnect, body.Bytes())
+}
+
+// publishPacket builds a QoS 1 PUBLISH packet
+func (m *MQTT) publishPacket(id uint16, payload []byte) []byte {
+	var body bytes.Buffer
+	writeString(&body, m.topic)
+	binary.Write(&body, binary.BigEndian, id)
+	body.Write(payload)
+
+	header := byte(mqttPublish | 0x02) // QoS 1
+	if m.retain {
+		header |= 0x01
+	}
+	return packet(header, body.Bytes())
+}
+
+// packet prefixes a packet body with its fixed header
+func packet(header byte, body []byte) []byte {
+	buf := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(buf, body...)
+}
+
+// readPacket reads one packet, returning its type and body
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, fmt.Errorf("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header & 0xf0, body, nil
+}
+
+// writeString writes a length-prefixed UTF-8 string
+func writeString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
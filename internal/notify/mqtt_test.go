@@ -0,0 +1,195 @@
+// ===== internal/notify/mqtt_test.go =====
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// brokerSession is what the stand-in broker saw of one connection
+type brokerSession struct {
+	connect []byte
+	publish []byte
+	retain  bool
+	err     error
+}
+
+// standInBroker accepts one connection and answers CONNECT with
+// connackCode and, when it is 0, PUBLISH with a PUBACK
+func standInBroker(t *testing.T, connackCode byte) (string, chan brokerSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan brokerSession, 1)
+	go func() {
+		var s brokerSession
+		defer func() { sessions <- s }()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			s.err = err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+
+		kind, body, err := readPacket(r)
+		if err != nil || kind != mqttConnect {
+			s.err = err
+			return
+		}
+		s.connect = body
+		conn.Write([]byte{mqttConnack, 2, 0, connackCode})
+		if connackCode != 0 {
+			return
+		}
+
+		header, _ := r.Peek(1)
+		s.retain = len(header) == 1 && header[0]&0x01 != 0
+		kind, body, err = readPacket(r)
+		if err != nil || kind != mqttPublish {
+			s.err = err
+			return
+		}
+		s.publish = body
+		topicLen := int(binary.BigEndian.Uint16(body))
+		id := body[2+topicLen : 4+topicLen]
+		conn.Write([]byte{mqttPuback, 2, id[0], id[1]})
+
+		// DISCONNECT
+		readPacket(r)
+	}()
+	return listener.Addr().String(), sessions
+}
+
+func TestMQTTSend(t *testing.T) {
+	addr, sessions := standInBroker(t, 0)
+	cfg := testChannelConfig("mqtt://alice:secret@" + addr)
+	cfg.Type = "mqtt"
+	cfg.Topic = "home/dhcp"
+	cfg.Retain = true
+	m, err := NewMQTT(cfg)
+	if err != nil {
+		t.Fatalf("NewMQTT: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Send(ctx, testMessage(LevelWarning)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	s := <-sessions
+	if s.err != nil {
+		t.Fatalf("broker: %v", s.err)
+	}
+
+	// CONNECT: protocol name and level, flags, keep-alive, client ID and
+	// credentials
+	var want bytes.Buffer
+	writeString(&want, "MQTT")
+	want.Write([]byte{4, 0x80 | 0x40 | 0x02})
+	binary.Write(&want, binary.BigEndian, uint16(mqttKeepAlive))
+	writeString(&want, "dhcpmon")
+	writeString(&want, "alice")
+	writeString(&want, "secret")
+	if !bytes.Equal(s.connect, want.Bytes()) {
+		t.Errorf("CONNECT body = %q, want %q", s.connect, want.Bytes())
+	}
+
+	// PUBLISH: topic, packet ID and JSON payload
+	topicLen := int(binary.BigEndian.Uint16(s.publish))
+	if topic := string(s.publish[2 : 2+topicLen]); topic != "home/dhcp" {
+		t.Errorf("topic = %q", topic)
+	}
+	var payload Event
+	if err := json.Unmarshal(s.publish[4+topicLen:], &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload.Title != "Pool lan at 90%" || payload.Level != LevelWarning {
+		t.Errorf("payload = %+v", payload)
+	}
+	if !s.retain {
+		t.Errorf("retain flag not set")
+	}
+}
+
+func TestMQTTConnectionRefused(t *testing.T) {
+	addr, sessions := standInBroker(t, 5)
+	cfg := testChannelConfig("mqtt://" + addr)
+	cfg.Type = "mqtt"
+	m, err := NewMQTT(cfg)
+	if err != nil {
+		t.Fatalf("NewMQTT: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = m.Send(ctx, testMessage(LevelInfo))
+	if err == nil || !strings.Contains(err.Error(), "not authorized") {
+		t.Errorf("Send error = %v, want not authorized", err)
+	}
+	<-sessions
+}
+
+func TestMQTTDefaults(t *testing.T) {
+	cfg := testChannelConfig("mqtts://broker.example.com")
+	cfg.Type = "mqtt"
+	m, err := NewMQTT(cfg)
+	if err != nil {
+		t.Fatalf("NewMQTT: %v", err)
+	}
+	if m.addr != "broker.example.com:8883" || !m.tls {
+		t.Errorf("mqtts defaults: addr %s, tls %v", m.addr, m.tls)
+	}
+
+	// Without credentials the CONNECT flags only ask for a clean session
+	if flags := m.connectPacket()[2+6+1]; flags != 0x02 {
+		t.Errorf("CONNECT flags = %#x, want 0x02", flags)
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	// Bodies around the boundaries of the variable-length encoding
+	for _, size := range []int{0, 1, 127, 128, 16383, 16384, 200000} {
+		body := bytes.Repeat([]byte{0xab}, size)
+		encoded := packet(mqttPublish|0x02, body)
+
+		kind, decoded, err := readPacket(bufio.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if kind != mqttPublish || !bytes.Equal(decoded, body) {
+			t.Errorf("size %d: got type %#x and %d bytes", size, kind, len(decoded))
+		}
+	}
+
+	if got := packet(mqttPuback, make([]byte, 321))[1:3]; !bytes.Equal(got, []byte{0xc1, 0x02}) {
+		t.Errorf("remaining length of 321 encoded as % x, want c1 02", got)
+	}
+}
+
+func TestReadPacketMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"length too long": {mqttConnack, 0xff, 0xff, 0xff, 0xff, 0x01},
+		"short body":      {mqttConnack, 2, 0},
+		"empty":           {},
+	}
+	for name, data := range tests {
+		if _, _, err := readPacket(bufio.NewReader(bytes.NewReader(data))); err == nil {
+			t.Errorf("%s: readPacket succeeded", name)
+		}
+	}
+}
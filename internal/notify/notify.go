@@ -0,0 +1,422 @@
+// ===== internal/notify/notify.go =====
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"dhcpmon/internal/config"
+	"dhcpmon/pkg/utils"
+)
+
+// Event types
+const (
+	EventAlert     = "alert"      // Pool utilization or exhaustion alert
+	EventNewDevice = "new-device" // A MAC address seen for the first time
+	EventDNSMasq   = "dnsmasq"    // dnsmasq crashed or was restarted
+	EventTest      = "test"       // Sent on request to check a channel
+)
+
+// Event levels, in increasing order of severity
+const (
+	LevelInfo     = "info"
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+)
+
+// queueSize is the number of events waiting per channel before new ones
+// are dropped
+const queueSize = 100
+
+// Event is something worth telling someone about
+type Event struct {
+	Type    string            `json:"type"`
+	Level   string            `json:"level"`
+	Title   string            `json:"title"`
+	Message string            `json:"message"`
+	Time    time.Time         `json:"time"`
+	Host    string            `json:"host,omitempty"` // Host dhcpmon runs on
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Message is an event with its title and body rendered by the channel's
+// templates
+type Message struct {
+	Event
+	Subject string
+	Body    string
+}
+
+// Payload returns the event as sent to JSON endpoints, with the rendered
+// title and body
+func (m Message) Payload() Event {
+	e := m.Event
+	e.Title = m.Subject
+	e.Message = m.Body
+	return e
+}
+
+// Notifier delivers messages to one kind of endpoint
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// levelRank orders the levels for filtering
+func levelRank(level string) int {
+	switch level {
+	case LevelCritical:
+		return 2
+	case LevelWarning:
+		return 1
+	}
+	return 0
+}
+
+// ChannelStatus reports the configuration and delivery counters of a
+// channel
+type ChannelStatus struct {
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	Target    string     `json:"target"`
+	Events    []string   `json:"events,omitempty"`
+	MinLevel  string     `json:"level"`
+	RateLimit string     `json:"rateLimit,omitempty"`
+	Sent      int        `json:"sent"`
+	Failed    int        `json:"failed"`
+	Dropped   int        `json:"dropped"` // Rate limited or queue full
+	LastSent  *time.Time `json:"lastSent,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+}
+
+// Channel is a configured notifier with its event filter, rate limit and
+// message templates
+type Channel struct {
+	name     string
+	kind     string
+	target   string
+	notifier Notifier
+	events   map[string]bool
+	minLevel string
+	limit    int
+	period   time.Duration
+	timeout  time.Duration
+	subject  *template.Template
+	body     *template.Template
+	queue    chan Event
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+	sent        int
+	failed      int
+	dropped     int
+	lastSent    time.Time
+	lastError   string
+}
+
+// NewChannel creates a channel from its configuration
+func NewChannel(cfg config.NotifyChannel) (*Channel, error) {
+	var notifier Notifier
+	var err error
+	switch cfg.Type {
+	case "webhook":
+		notifier, err = NewWebhook(cfg)
+	case "ntfy":
+		notifier, err = NewNtfy(cfg)
+	case "gotify":
+		notifier, err = NewGotify(cfg)
+	case "smtp", "email":
+		notifier, err = NewSMTP(cfg)
+	case "mqtt":
+		notifier, err = NewMQTT(cfg)
+	default:
+		return nil, fmt.Errorf("unknown type %q", cfg.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := template.New("title").Option("missingkey=zero").Parse(cfg.Title)
+	if err != nil {
+		return nil, fmt.Errorf("invalid title template: %w", err)
+	}
+	body, err := template.New("message").Option("missingkey=zero").Parse(cfg.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+
+	c := &Channel{
+		name:     cfg.Name,
+		kind:     cfg.Type,
+		target:   redactURL(cfg.URL),
+		notifier: notifier,
+		events:   make(map[string]bool),
+		minLevel: cfg.MinLevel,
+		limit:    cfg.RateLimit,
+		period:   cfg.RatePeriod,
+		timeout:  cfg.Timeout,
+		subject:  subject,
+		body:     body,
+		queue:    make(chan Event, queueSize),
+	}
+	for _, event := range cfg.Events {
+		c.events[event] = true
+	}
+	return c, nil
+}
+
+// Name returns the channel name
+func (c *Channel) Name() string {
+	return c.name
+}
+
+// Accepts reports whether the channel's filters let the event through
+func (c *Channel) Accepts(e Event) bool {
+	if len(c.events) > 0 && !c.events[e.Type] {
+		return false
+	}
+	return levelRank(e.Level) >= levelRank(c.minLevel)
+}
+
+// Render applies the channel's templates to an event
+func (c *Channel) Render(e Event) (Message, error) {
+	var subject, body bytes.Buffer
+	if err := c.subject.Execute(&subject, e); err != nil {
+		return Message{}, fmt.Errorf("failed to render title: %w", err)
+	}
+	if err := c.body.Execute(&body, e); err != nil {
+		return Message{}, fmt.Errorf("failed to render message: %w", err)
+	}
+	return Message{
+		Event:   e,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()),
+	}, nil
+}
+
+// Send renders and delivers an event right away, bypassing the filters
+// and rate limit
+func (c *Channel) Send(e Event) error {
+	msg, err := c.Render(e)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		err = c.notifier.Send(ctx, msg)
+		cancel()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.failed++
+		c.lastError = err.Error()
+		return err
+	}
+	c.sent++
+	c.lastSent = time.Now()
+	c.lastError = ""
+	return nil
+}
+
+// allow applies the rate limit, a fixed window of period allowing limit
+// messages. The first message dropped in a window is logged.
+func (c *Channel) allow(now time.Time) bool {
+	if c.limit <= 0 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.windowStart) >= c.period {
+		c.windowStart = now
+		c.windowCount = 0
+	}
+	if c.windowCount >= c.limit {
+		if c.windowCount == c.limit {
+			utils.Warnf("Warning: notification channel %s reached its limit of %d per %s, dropping messages",
+				c.name, c.limit, c.period)
+		}
+		c.windowCount++
+		c.dropped++
+		return false
+	}
+	c.windowCount++
+	return true
+}
+
+// enqueue queues an event for delivery without blocking
+func (c *Channel) enqueue(e Event) {
+	select {
+	case c.queue <- e:
+	default:
+		c.mu.Lock()
+		c.dropped++
+		c.mu.Unlock()
+		utils.Warnf("Warning: notification channel %s queue full, dropping %s event", c.name, e.Type)
+	}
+}
+
+// run delivers queued events until the queue is closed
+func (c *Channel) run(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for e := range c.queue {
+		if !c.allow(time.Now()) {
+			continue
+		}
+		if err := c.Send(e); err != nil {
+			utils.Warnf("Warning: failed to send %s notification to %s: %v", e.Type, c.name, err)
+		}
+	}
+}
+
+// Status returns the channel's configuration and counters
+func (c *Channel) Status() ChannelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := ChannelStatus{
+		Name:      c.name,
+		Type:      c.kind,
+		Target:    c.target,
+		MinLevel:  c.minLevel,
+		Sent:      c.sent,
+		Failed:    c.failed,
+		Dropped:   c.dropped,
+		LastError: c.lastError,
+	}
+	for event := range c.events {
+		status.Events = append(status.Events, event)
+	}
+	sort.Strings(status.Events)
+	if c.limit > 0 {
+		status.RateLimit = fmt.Sprintf("%d per %s", c.limit, c.period)
+	}
+	if !c.lastSent.IsZero() {
+		lastSent := c.lastSent
+		status.LastSent = &lastSent
+	}
+	return status
+}
+
+// Manager fans events out to the configured channels
+type Manager struct {
+	channels []*Channel
+	host     string
+	started  bool
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+}
+
+// NewManager creates the configured channels. A channel with an invalid
+// configuration is logged and left out.
+func NewManager(channels []config.NotifyChannel) *Manager {
+	m := &Manager{}
+	m.host, _ = os.Hostname()
+
+	for _, cfg := range channels {
+		c, err := NewChannel(cfg)
+		if err != nil {
+			utils.Warnf("Warning: notification channel %s disabled: %v", cfg.Name, err)
+			continue
+		}
+		m.channels = append(m.channels, c)
+	}
+	if len(m.channels) > 0 {
+		utils.Infof("Configured %d notification channels", len(m.channels))
+	}
+	return m
+}
+
+// Start begins delivering events
+func (m *Manager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.started = true
+	m.wg.Add(len(m.channels))
+	for _, c := range m.channels {
+		go c.run(&m.wg)
+	}
+}
+
+// Stop delivers the queued events and stops
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	started := m.started
+	m.started = false
+	m.mu.Unlock()
+
+	if !started {
+		return
+	}
+	for _, c := range m.channels {
+		close(c.queue)
+	}
+	m.wg.Wait()
+}
+
+// Notify queues an event for every channel whose filters accept it
+func (m *Manager) Notify(e Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.started {
+		return
+	}
+
+	m.prepare(&e)
+	for _, c := range m.channels {
+		if c.Accepts(e) {
+			c.enqueue(e)
+		}
+	}
+}
+
+// Test sends a test event to the named channel and waits for the result
+func (m *Manager) Test(name string) error {
+	for _, c := range m.channels {
+		if c.name == name {
+			e := Event{
+				Type:    EventTest,
+				Level:   LevelInfo,
+				Title:   "dhcpmon test notification",
+				Message: fmt.Sprintf("Notification channel %s is working.", name),
+			}
+			m.prepare(&e)
+			return c.Send(e)
+		}
+	}
+	return fmt.Errorf("no notification channel named %q", name)
+}
+
+// Status reports on every channel
+func (m *Manager) Status() []ChannelStatus {
+	statuses := make([]ChannelStatus, 0, len(m.channels))
+	for _, c := range m.channels {
+		statuses = append(statuses, c.Status())
+	}
+	return statuses
+}
+
+// prepare fills in the time and host of an event and drops empty fields
+func (m *Manager) prepare(e *Event) {
+	for name, value := range e.Fields {
+		if value == "" {
+			delete(e.Fields, name)
+		}
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Host == "" {
+		e.Host = m.host
+	}
+	if e.Level == "" {
+		e.Level = LevelInfo
+	}
+}
@@ -0,0 +1,235 @@
+// ===== internal/notify/notify_test.go =====
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dhcpmon/internal/config"
+)
+
+// testChannelConfig returns a valid channel configuration with the
+// defaults of config
+func testChannelConfig(url string) config.NotifyChannel {
+	return config.NotifyChannel{
+		Name:       "test",
+		Type:       "webhook",
+		URL:        url,
+		MinLevel:   LevelInfo,
+		RateLimit:  20,
+		RatePeriod: time.Hour,
+		Timeout:    5 * time.Second,
+		Title:      "{{.Title}}",
+		Message:    "{{.Message}}",
+		Topic:      "dhcpmon/events",
+		ClientID:   "dhcpmon",
+	}
+}
+
+// stubNotifier records the messages sent, failing with err when set
+type stubNotifier struct {
+	sent []Message
+	err  error
+}
+
+func (s *stubNotifier) Send(ctx context.Context, msg Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func newTestChannel(t *testing.T, cfg config.NotifyChannel) *Channel {
+	t.Helper()
+	c, err := NewChannel(cfg)
+	if err != nil {
+		t.Fatalf("NewChannel: %v", err)
+	}
+	return c
+}
+
+func TestChannelAccepts(t *testing.T) {
+	cfg := testChannelConfig("http://127.0.0.1/hook")
+	cfg.Events = []string{EventAlert, EventDNSMasq}
+	cfg.MinLevel = LevelWarning
+	c := newTestChannel(t, cfg)
+
+	tests := []struct {
+		event Event
+		want  bool
+	}{
+		{Event{Type: EventAlert, Level: LevelWarning}, true},
+		{Event{Type: EventAlert, Level: LevelCritical}, true},
+		{Event{Type: EventAlert, Level: LevelInfo}, false},
+		{Event{Type: EventDNSMasq, Level: LevelCritical}, true},
+		{Event{Type: EventNewDevice, Level: LevelCritical}, false},
+		{Event{Type: EventAlert}, false}, // No level is info
+	}
+	for _, tt := range tests {
+		if got := c.Accepts(tt.event); got != tt.want {
+			t.Errorf("Accepts(%s/%s) = %v, want %v", tt.event.Type, tt.event.Level, got, tt.want)
+		}
+	}
+
+	// Without an event list, every type passes the level filter
+	cfg.Events = nil
+	cfg.MinLevel = LevelInfo
+	c = newTestChannel(t, cfg)
+	if !c.Accepts(Event{Type: EventNewDevice, Level: LevelInfo}) {
+		t.Errorf("channel without events rejected a new-device event")
+	}
+}
+
+func TestChannelAllow(t *testing.T) {
+	cfg := testChannelConfig("http://127.0.0.1/hook")
+	cfg.RateLimit = 2
+	cfg.RatePeriod = time.Minute
+	c := newTestChannel(t, cfg)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true},
+		{10 * time.Second, true},
+		{20 * time.Second, false}, // Third in the window
+		{59 * time.Second, false},
+		{60 * time.Second, true}, // New window
+		{70 * time.Second, true},
+		{80 * time.Second, false},
+	}
+	for _, step := range steps {
+		if got := c.allow(start.Add(step.at)); got != step.want {
+			t.Errorf("allow at +%s = %v, want %v", step.at, got, step.want)
+		}
+	}
+	if dropped := c.Status().Dropped; dropped != 3 {
+		t.Errorf("dropped = %d, want 3", dropped)
+	}
+
+	cfg.RateLimit = 0
+	c = newTestChannel(t, cfg)
+	for i := 0; i < 100; i++ {
+		if !c.allow(start) {
+			t.Fatalf("unlimited channel dropped message %d", i)
+		}
+	}
+}
+
+func TestChannelRender(t *testing.T) {
+	cfg := testChannelConfig("http://127.0.0.1/hook")
+	cfg.Title = "[{{.Level}}] {{.Title}}"
+	cfg.Message = "{{.Message}} ({{.Fields.mac}}{{.Fields.missing}})\n"
+	c := newTestChannel(t, cfg)
+
+	msg, err := c.Render(Event{
+		Type:    EventNewDevice,
+		Level:   LevelInfo,
+		Title:   "New device",
+		Message: "A device appeared",
+		Fields:  map[string]string{"mac": "AA:BB:CC:00:00:01"},
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if msg.Subject != "[info] New device" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+	if msg.Body != "A device appeared (AA:BB:CC:00:00:01)" {
+		t.Errorf("Body = %q", msg.Body)
+	}
+	if p := msg.Payload(); p.Title != msg.Subject || p.Message != msg.Body {
+		t.Errorf("Payload = %+v, want the rendered title and message", p)
+	}
+}
+
+func TestNewChannelErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*config.NotifyChannel)
+	}{
+		{"unknown type", func(c *config.NotifyChannel) { c.Type = "pager" }},
+		{"no url", func(c *config.NotifyChannel) { c.URL = "" }},
+		{"wrong scheme", func(c *config.NotifyChannel) { c.URL = "ftp://127.0.0.1/" }},
+		{"bad header", func(c *config.NotifyChannel) { c.Headers = []string{"no colon"} }},
+		{"bad template", func(c *config.NotifyChannel) { c.Title = "{{.Title" }},
+		{"smtp without recipients", func(c *config.NotifyChannel) {
+			c.Type, c.URL, c.From = "smtp", "smtp://127.0.0.1", "dhcpmon@example.com"
+		}},
+		{"mqtt without topic", func(c *config.NotifyChannel) {
+			c.Type, c.URL, c.Topic = "mqtt", "mqtt://127.0.0.1", ""
+		}},
+	}
+	for _, tt := range tests {
+		cfg := testChannelConfig("http://127.0.0.1/hook")
+		tt.modify(&cfg)
+		if _, err := NewChannel(cfg); err == nil {
+			t.Errorf("%s: NewChannel succeeded", tt.name)
+		}
+	}
+}
+
+func TestChannelSendCounters(t *testing.T) {
+	c := newTestChannel(t, testChannelConfig("http://127.0.0.1/hook"))
+	stub := &stubNotifier{}
+	c.notifier = stub
+
+	if err := c.Send(Event{Type: EventTest, Title: "hello"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	stub.err = errors.New("unreachable")
+	if err := c.Send(Event{Type: EventTest}); err == nil {
+		t.Fatalf("Send succeeded with a failing notifier")
+	}
+
+	status := c.Status()
+	if status.Sent != 1 || status.Failed != 1 || status.LastError != "unreachable" || status.LastSent == nil {
+		t.Errorf("Status = %+v", status)
+	}
+	if len(stub.sent) != 1 || stub.sent[0].Subject != "hello" {
+		t.Errorf("sent = %+v", stub.sent)
+	}
+}
+
+func TestManagerNotify(t *testing.T) {
+	alerts := testChannelConfig("http://127.0.0.1/alerts")
+	alerts.Name = "alerts"
+	alerts.Events = []string{EventAlert}
+	devices := testChannelConfig("http://127.0.0.1/devices")
+	devices.Name = "devices"
+	devices.Events = []string{EventNewDevice}
+
+	m := NewManager([]config.NotifyChannel{alerts, devices})
+	stubs := make(map[string]*stubNotifier)
+	for _, c := range m.channels {
+		stubs[c.name] = &stubNotifier{}
+		c.notifier = stubs[c.name]
+	}
+
+	m.Notify(Event{Type: EventAlert}) // Dropped before Start
+	m.Start()
+	m.Notify(Event{Type: EventAlert, Level: LevelWarning, Fields: map[string]string{"pool": "lan", "empty": ""}})
+	m.Stop()
+
+	if n := len(stubs["devices"].sent); n != 0 {
+		t.Errorf("devices channel got %d messages", n)
+	}
+	sent := stubs["alerts"].sent
+	if len(sent) != 1 {
+		t.Fatalf("alerts channel got %d messages, want 1", len(sent))
+	}
+	if sent[0].Time.IsZero() || sent[0].Host != m.host {
+		t.Errorf("event not prepared: %+v", sent[0].Event)
+	}
+	if _, ok := sent[0].Fields["empty"]; ok {
+		t.Errorf("empty field kept: %v", sent[0].Fields)
+	}
+
+	if err := m.Test("nope"); err == nil {
+		t.Errorf("Test of an unknown channel succeeded")
+	}
+}
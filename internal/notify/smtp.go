@@ -0,0 +1,167 @@
+// ===== internal/notify/smtp.go =====
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+
+	"dhcpmon/internal/config"
+)
+
+// SMTP sends each message as a plain text email
+type SMTP struct {
+	addr     string // host:port
+	host     string
+	implicit bool // TLS from the start (smtps), rather than STARTTLS
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewSMTP creates an email sender. smtp:// URLs use STARTTLS when the
+// server offers it (port 25 by default); smtps:// URLs use TLS from the
+// start (port 465 by default).
+func NewSMTP(cfg config.NotifyChannel) (*SMTP, error) {
+	if err := checkURL(cfg.URL, "smtp", "smtps"); err != nil {
+		return nil, err
+	}
+	u, _ := url.Parse(cfg.URL)
+
+	s := &SMTP{
+		host:     u.Hostname(),
+		implicit: u.Scheme == "smtps",
+		username: cfg.Username,
+		password: cfg.Password,
+	}
+	if s.username == "" && u.User != nil {
+		s.username = u.User.Username()
+		s.password, _ = u.User.Password()
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "25"
+		if s.implicit {
+			port = "465"
+		}
+	}
+	s.addr = net.JoinHostPort(s.host, port)
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", cfg.From, err)
+	}
+	s.from = from.Address
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("no recipients (to) set")
+	}
+	for _, to := range cfg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		s.to = append(s.to, addr.Address)
+	}
+	return s, nil
+}
+
+// Send delivers the message to every recipient
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if s.implicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.addr, &tls.Config{ServerName: s.host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", s.addr, err)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if !s.implicit {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+				return fmt.Errorf("STARTTLS failed: %w", err)
+			}
+		}
+	}
+	if s.username != "" {
+		// PlainAuth refuses to send credentials without TLS, except to
+		// localhost
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.from); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	for _, to := range s.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := w.Write(s.compose(msg)); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// compose builds the email with a quoted-printable UTF-8 body
+func (s *SMTP) compose(msg Message) []byte {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	header("From", s.from)
+	header("To", strings.Join(s.to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", msg.Time.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	header("X-Dhcpmon-Event", msg.Type)
+	header("X-Dhcpmon-Level", msg.Level)
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	qp.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n")))
+	qp.Close()
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
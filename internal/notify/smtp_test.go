@@ -0,0 +1,213 @@
+// ===== internal/notify/smtp_test.go =====
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"dhcpmon/internal/config"
+)
+
+// smtpSession is what the stand-in mail server saw of one connection
+type smtpSession struct {
+	commands []string
+	auth     string
+	from     string
+	to       []string
+	data     string
+}
+
+// standInSMTP accepts one connection and speaks enough SMTP to take a
+// message. With starttls, it offers STARTTLS and hangs up when the
+// client takes it.
+func standInSMTP(t *testing.T, starttls bool) (string, chan smtpSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		var s smtpSession
+		defer func() { sessions <- s }()
+
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		reply("220 stand-in ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			verb := strings.ToUpper(strings.Fields(line + " ")[0])
+			s.commands = append(s.commands, verb)
+
+			switch verb {
+			case "EHLO", "HELO":
+				reply("250-stand-in")
+				if starttls {
+					reply("250-STARTTLS")
+				}
+				reply("250 AUTH PLAIN")
+			case "STARTTLS":
+				reply("220 go ahead")
+				return
+			case "AUTH":
+				s.auth = line
+				reply("235 accepted")
+			case "MAIL":
+				s.from = line
+				reply("250 ok")
+			case "RCPT":
+				s.to = append(s.to, line)
+				reply("250 ok")
+			case "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				s.data = data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return listener.Addr().String(), sessions
+}
+
+// smtpConfig returns an smtp channel configuration for url
+func smtpConfig(url string) config.NotifyChannel {
+	cfg := testChannelConfig(url)
+	cfg.Type = "smtp"
+	cfg.From = "dhcpmon <dhcpmon@example.com>"
+	cfg.To = []string{"admin@example.com", "Ops <ops@example.com>"}
+	return cfg
+}
+
+func TestSMTPSend(t *testing.T) {
+	addr, sessions := standInSMTP(t, false)
+	cfg := smtpConfig("smtp://" + addr)
+	cfg.Username, cfg.Password = "alice", "secret"
+	s, err := NewSMTP(cfg)
+	if err != nil {
+		t.Fatalf("NewSMTP: %v", err)
+	}
+
+	msg := testMessage(LevelCritical)
+	msg.Subject = "Pool lan exhausted – ünïcode"
+	msg.Body = "No free addresses\nin pool lan"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Send(ctx, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	session := <-sessions
+	if session.from != "MAIL FROM:<dhcpmon@example.com>" {
+		t.Errorf("MAIL = %q", session.from)
+	}
+	if len(session.to) != 2 || session.to[1] != "RCPT TO:<ops@example.com>" {
+		t.Errorf("RCPT = %q", session.to)
+	}
+
+	// PlainAuth sends credentials in the clear only to localhost
+	creds, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(session.auth, "AUTH PLAIN "))
+	if string(creds) != "\x00alice\x00secret" {
+		t.Errorf("AUTH = %q", session.auth)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(session.data))
+	if err != nil {
+		t.Fatalf("message does not parse: %v\n%s", err, session.data)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != msg.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, msg.Subject)
+	}
+	for name, want := range map[string]string{
+		"From":            "dhcpmon@example.com",
+		"To":              "admin@example.com, ops@example.com",
+		"X-Dhcpmon-Event": EventAlert,
+		"X-Dhcpmon-Level": LevelCritical,
+		"Date":            msg.Time.Format(time.RFC1123Z),
+	} {
+		if got := parsed.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	body, _ := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if got := strings.TrimSpace(string(body)); got != "No free addresses\r\nin pool lan" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestSMTPStartTLS(t *testing.T) {
+	// The stand-in offers STARTTLS but cannot complete the handshake, so
+	// the client must fail rather than fall back to plain text
+	addr, sessions := standInSMTP(t, true)
+	s, err := NewSMTP(smtpConfig("smtp://" + addr))
+	if err != nil {
+		t.Fatalf("NewSMTP: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = s.Send(ctx, testMessage(LevelInfo))
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS failed") {
+		t.Errorf("Send error = %v, want STARTTLS failed", err)
+	}
+
+	session := <-sessions
+	for _, verb := range session.commands {
+		if verb == "MAIL" {
+			t.Errorf("message sent without TLS: %v", session.commands)
+		}
+	}
+}
+
+func TestSMTPDefaults(t *testing.T) {
+	s, err := NewSMTP(smtpConfig("smtps://bob:pw@mail.example.com"))
+	if err != nil {
+		t.Fatalf("NewSMTP: %v", err)
+	}
+	if s.addr != "mail.example.com:465" || !s.implicit || s.username != "bob" || s.password != "pw" {
+		t.Errorf("smtps defaults: %+v", s)
+	}
+
+	cfg := smtpConfig("smtp://mail.example.com")
+	cfg.To = []string{"not an address"}
+	if _, err := NewSMTP(cfg); err == nil {
+		t.Errorf("NewSMTP accepted an invalid recipient")
+	}
+}
@@ -0,0 +1,204 @@
+// ===== internal/static/batch.go =====
+package static
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"dhcpmon/pkg/models"
+)
+
+// Batch operation actions
+const (
+	BatchAdd     = "add"
+	BatchUpdate  = "update"
+	BatchDelete  = "delete"
+	BatchEnable  = "enable"
+	BatchDisable = "disable"
+)
+
+// BatchOp is one operation of a batch. The entry it acts on is selected
+// by ID, or by MAC address when there is no ID; an update without either
+// selects by the MAC of the new entry.
+type BatchOp struct {
+	Action string
+	ID     string
+	MAC    net.HardwareAddr
+	Entry  models.StaticDHCPEntry // For add and update
+}
+
+// BatchOpResult is the entry an operation left, or removed for a delete
+type BatchOpResult struct {
+	Op     int                    `json:"op"` // Position in the batch, from 1
+	Action string                 `json:"action"`
+	Entry  models.StaticDHCPEntry `json:"entry"`
+}
+
+// BatchError explains why an operation cannot be applied
+type BatchError struct {
+	Op     int    `json:"op"`
+	Action string `json:"action"`
+	Error  string `json:"error"`
+}
+
+// BatchResult describes a batch. When Errors is not empty nothing was
+// changed.
+type BatchResult struct {
+	DryRun  bool            `json:"dryRun"`
+	Applied bool            `json:"applied"`
+	Results []BatchOpResult `json:"results"`
+	Errors  []BatchError    `json:"errors"`
+}
+
+// Batch applies operations in order to a copy of the entries, so
+// intermediate states may conflict, as when two devices swap addresses.
+// The end state is then checked like Add checks new entries: entries an
+// operation added, updated or enabled must not share a MAC or IP with
+// another enabled entry. The copy replaces the entries only when every
+// operation succeeds and the check passes, and dryRun is not set.
+func (m *Manager) Batch(ops []BatchOp, dryRun bool) *BatchResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.batch(ops, dryRun)
+}
+
+// batch implements Batch; the caller holds m.mu
+func (m *Manager) batch(ops []BatchOp, dryRun bool) *BatchResult {
+	result := &BatchResult{DryRun: dryRun, Results: []BatchOpResult{}, Errors: []BatchError{}}
+	work := make([]models.StaticDHCPEntry, len(m.entries))
+	copy(work, m.entries)
+	touched := make(map[string]int) // Entry ID to the last operation changing it
+
+	for n, op := range ops {
+		num := n + 1
+		fail := func(err error) {
+			result.Errors = append(result.Errors, BatchError{Op: num, Action: op.Action, Error: err.Error()})
+		}
+
+		switch op.Action {
+		case BatchAdd, BatchUpdate, BatchDelete, BatchEnable, BatchDisable:
+		default:
+			fail(fmt.Errorf("unknown action %q", op.Action))
+			continue
+		}
+
+		if op.Action == BatchAdd {
+			if err := op.Entry.Validate(); err != nil {
+				fail(fmt.Errorf("invalid entry: %w", err))
+				continue
+			}
+			entry := op.Entry
+			entry.ID = newID()
+			entry.LineNumber = len(work) + 1
+			work = append(work, entry)
+			touched[entry.ID] = num
+			result.Results = append(result.Results, BatchOpResult{Op: num, Action: op.Action, Entry: entry})
+			continue
+		}
+
+		mac := op.MAC
+		if op.ID == "" && mac == nil && op.Action == BatchUpdate {
+			mac = op.Entry.MAC
+		}
+		i, err := findEntry(work, op.ID, mac)
+		if err != nil {
+			fail(err)
+			continue
+		}
+
+		switch op.Action {
+		case BatchUpdate:
+			if err := op.Entry.Validate(); err != nil {
+				fail(fmt.Errorf("invalid entry: %w", err))
+				continue
+			}
+			entry := op.Entry
+			entry.ID = work[i].ID
+			entry.LineNumber = work[i].LineNumber
+			work[i] = entry
+			touched[entry.ID] = num
+		case BatchDelete:
+			result.Results = append(result.Results, BatchOpResult{Op: num, Action: op.Action, Entry: work[i]})
+			delete(touched, work[i].ID)
+			work = append(work[:i], work[i+1:]...)
+			continue
+		case BatchEnable:
+			work[i].Enabled = true
+			touched[work[i].ID] = num
+		case BatchDisable:
+			work[i].Enabled = false
+		}
+		result.Results = append(result.Results, BatchOpResult{Op: num, Action: op.Action, Entry: work[i]})
+	}
+
+	// Check the end state of the entries the batch changed
+	for i, entry := range work {
+		num, changed := touched[entry.ID]
+		if !changed || !entry.Enabled {
+			continue
+		}
+		for j, other := range work {
+			if j == i || !other.Enabled {
+				continue
+			}
+			if _, otherChanged := touched[other.ID]; otherChanged && j < i {
+				continue // Reported for the earlier entry
+			}
+			if other.MAC.String() == entry.MAC.String() {
+				result.Errors = append(result.Errors, BatchError{Op: num, Action: ops[num-1].Action,
+					Error: fmt.Sprintf("MAC address %s already exists", entry.GetFormattedMAC())})
+			}
+			if entry.IP != nil && other.IP != nil && other.IP.Equal(entry.IP) {
+				result.Errors = append(result.Errors, BatchError{Op: num, Action: ops[num-1].Action,
+					Error: fmt.Sprintf("IP address %s already exists (%s)", entry.IP, other.GetDisplayName())})
+			}
+		}
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Op < result.Errors[j].Op })
+
+	if len(result.Errors) > 0 || dryRun {
+		return result
+	}
+	m.entries = work
+	result.Applied = true
+	return result
+}
+
+// findEntry returns the index of the entry with an ID, or the entry with
+// a MAC address; of several entries with the MAC, the enabled one
+func findEntry(entries []models.StaticDHCPEntry, id string, mac net.HardwareAddr) (int, error) {
+	if id != "" {
+		for i, entry := range entries {
+			if entry.ID == id {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("entry with ID %s not found", id)
+	}
+	if mac == nil {
+		return -1, fmt.Errorf("ID or MAC address is required")
+	}
+
+	found, enabled := -1, 0
+	for i, entry := range entries {
+		if entry.MAC.String() != mac.String() {
+			continue
+		}
+		if entry.Enabled {
+			if enabled > 0 {
+				return -1, fmt.Errorf("%s has several enabled entries; select one by ID", strings.ToUpper(mac.String()))
+			}
+			enabled++
+			found = i
+		} else if found < 0 {
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("no entry for %s", strings.ToUpper(mac.String()))
+	}
+	return found, nil
+}
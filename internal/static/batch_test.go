@@ -0,0 +1,204 @@
+// ===== internal/static/batch_test.go =====
+package static
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dhcpmon/pkg/models"
+)
+
+// testEntries is the static file the tests start from: alpha and beta
+// are enabled, gamma is disabled
+const testEntries = `dhcp-host=00:11:22:33:44:01,192.168.1.10,alpha
+dhcp-host=00:11:22:33:44:02,192.168.1.11,beta
+#dhcp-host=00:11:22:33:44:03,192.168.1.12,gamma
+`
+
+// newTestManager returns a manager loaded from a static file with content
+func newTestManager(t *testing.T, content string) *Manager {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "static.conf")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	m := NewManager(path)
+	if err := m.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return m
+}
+
+// testEntry returns an enabled entry for mac and ip
+func testEntry(t *testing.T, mac, ip, hostname string) models.StaticDHCPEntry {
+	t.Helper()
+	entry := models.StaticDHCPEntry{Hostname: hostname, Enabled: true}
+	if err := entry.SetMAC(mac); err != nil {
+		t.Fatalf("SetMAC(%s): %v", mac, err)
+	}
+	if err := entry.SetIP(ip); err != nil {
+		t.Fatalf("SetIP(%s): %v", ip, err)
+	}
+	return entry
+}
+
+// addresses returns the IP of each enabled entry by hostname
+func addresses(m *Manager) map[string]string {
+	ips := make(map[string]string)
+	for _, entry := range m.GetAll() {
+		if entry.Enabled {
+			ips[entry.Hostname] = entry.GetFormattedIP()
+		}
+	}
+	return ips
+}
+
+func TestBatch(t *testing.T) {
+	mac := func(s string) net.HardwareAddr {
+		hw, _ := net.ParseMAC(s)
+		return hw
+	}
+
+	tests := []struct {
+		name    string
+		ops     func(t *testing.T) []BatchOp
+		wantErr []string // Substrings of the errors, in order
+		wantIPs map[string]string
+	}{
+		{
+			name: "swap addresses",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchUpdate, Entry: testEntry(t, "00:11:22:33:44:01", "192.168.1.11", "alpha")},
+					{Action: BatchUpdate, Entry: testEntry(t, "00:11:22:33:44:02", "192.168.1.10", "beta")},
+				}
+			},
+			wantIPs: map[string]string{"alpha": "192.168.1.11", "beta": "192.168.1.10"},
+		},
+		{
+			name: "half a swap conflicts",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchUpdate, Entry: testEntry(t, "00:11:22:33:44:01", "192.168.1.11", "alpha")},
+				}
+			},
+			wantErr: []string{"IP address 192.168.1.11 already exists (beta)"},
+		},
+		{
+			name: "add with a taken address",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchAdd, Entry: testEntry(t, "00:11:22:33:44:04", "192.168.1.10", "delta")},
+				}
+			},
+			wantErr: []string{"IP address 192.168.1.10 already exists (alpha)"},
+		},
+		{
+			name: "add with a taken MAC",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchAdd, Entry: testEntry(t, "00:11:22:33:44:02", "192.168.1.20", "delta")},
+				}
+			},
+			wantErr: []string{"MAC address 00:11:22:33:44:02 already exists"},
+		},
+		{
+			name: "add with the address of a disabled entry",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchAdd, Entry: testEntry(t, "00:11:22:33:44:04", "192.168.1.12", "delta")},
+				}
+			},
+			wantIPs: map[string]string{"alpha": "192.168.1.10", "beta": "192.168.1.11", "delta": "192.168.1.12"},
+		},
+		{
+			name: "enabling into a conflict",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchUpdate, Entry: testEntry(t, "00:11:22:33:44:01", "192.168.1.12", "alpha")},
+					{Action: BatchEnable, MAC: mac("00:11:22:33:44:03")},
+				}
+			},
+			wantErr: []string{"IP address 192.168.1.12 already exists (gamma)"},
+		},
+		{
+			name: "delete frees the address",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchDelete, MAC: mac("00:11:22:33:44:01")},
+					{Action: BatchUpdate, Entry: testEntry(t, "00:11:22:33:44:02", "192.168.1.10", "beta")},
+				}
+			},
+			wantIPs: map[string]string{"beta": "192.168.1.10"},
+		},
+		{
+			name: "unknown entry and action",
+			ops: func(t *testing.T) []BatchOp {
+				return []BatchOp{
+					{Action: BatchDisable, MAC: mac("00:11:22:33:44:09")},
+					{Action: "rename", MAC: mac("00:11:22:33:44:01")},
+				}
+			},
+			wantErr: []string{"no entry for 00:11:22:33:44:09", `unknown action "rename"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, testEntries)
+			before := addresses(m)
+			result := m.Batch(tt.ops(t), false)
+
+			if len(result.Errors) != len(tt.wantErr) {
+				t.Fatalf("errors = %+v, want %q", result.Errors, tt.wantErr)
+			}
+			for i, want := range tt.wantErr {
+				if !strings.Contains(result.Errors[i].Error, want) {
+					t.Errorf("error %d = %q, want %q", i, result.Errors[i].Error, want)
+				}
+			}
+			if result.Applied != (len(tt.wantErr) == 0) {
+				t.Errorf("Applied = %v with %d errors", result.Applied, len(result.Errors))
+			}
+
+			want := tt.wantIPs
+			if len(tt.wantErr) > 0 {
+				want = before // A failed batch changes nothing
+			}
+			if got := addresses(m); !sameAddresses(got, want) {
+				t.Errorf("addresses = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBatchDryRun(t *testing.T) {
+	m := newTestManager(t, testEntries)
+	result := m.Batch([]BatchOp{
+		{Action: BatchAdd, Entry: testEntry(t, "00:11:22:33:44:04", "192.168.1.20", "delta")},
+	}, true)
+	if len(result.Errors) > 0 || result.Applied {
+		t.Fatalf("dry run: errors %+v, applied %v", result.Errors, result.Applied)
+	}
+	if len(result.Results) != 1 || result.Results[0].Entry.Hostname != "delta" {
+		t.Errorf("results = %+v", result.Results)
+	}
+	if _, ok := addresses(m)["delta"]; ok {
+		t.Errorf("dry run added the entry")
+	}
+}
+
+func sameAddresses(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
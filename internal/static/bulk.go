@@ -0,0 +1,308 @@
+// ===== internal/static/bulk.go =====
+package static
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"dhcpmon/pkg/models"
+)
+
+// Bulk file formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// csvColumns are the columns written by an export, in order
+var csvColumns = []string{"mac", "ip", "hostname", "tag", "leaseTime", "comment", "enabled", "ignore"}
+
+// csvAliases maps header names used in spreadsheets to columns; headers
+// are compared in lower case without spaces, dashes or underscores
+var csvAliases = map[string]string{
+	"mac":        "mac",
+	"macaddress": "mac",
+	"hwaddr":     "mac",
+	"ip":         "ip",
+	"ipaddress":  "ip",
+	"address":    "ip",
+	"hostname":   "hostname",
+	"host":       "hostname",
+	"name":       "hostname",
+	"tag":        "tag",
+	"leasetime":  "leaseTime",
+	"lease":      "leaseTime",
+	"comment":    "comment",
+	"notes":      "comment",
+	"enabled":    "enabled",
+	"ignore":     "ignore",
+	"blocked":    "ignore",
+}
+
+// Record is a static reservation as exported and imported
+type Record struct {
+	MAC       string `json:"mac"`
+	IP        string `json:"ip,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	LeaseTime string `json:"leaseTime,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+	Enabled   *bool  `json:"enabled,omitempty"` // Missing means enabled
+	Ignore    bool   `json:"ignore,omitempty"`
+}
+
+// ImportRow is a record read from a bulk file, converted to an entry. Row
+// is the line of a CSV or YAML file, or the position in a JSON array.
+type ImportRow struct {
+	Row   int
+	Entry models.StaticDHCPEntry
+	Err   string // Why the row cannot be imported
+}
+
+// NewRecord returns the record of an entry
+func NewRecord(entry models.StaticDHCPEntry) Record {
+	enabled := entry.Enabled
+	return Record{
+		MAC:       entry.GetFormattedMAC(),
+		IP:        entry.GetFormattedIP(),
+		Hostname:  entry.Hostname,
+		Tag:       entry.Tag,
+		LeaseTime: entry.LeaseTime,
+		Comment:   entry.Comment,
+		Enabled:   &enabled,
+		Ignore:    entry.Ignore,
+	}
+}
+
+// Entry converts a record to a validated entry
+func (r Record) Entry() (models.StaticDHCPEntry, error) {
+	entry := models.StaticDHCPEntry{
+		Hostname:  strings.TrimSpace(r.Hostname),
+		Tag:       strings.TrimSpace(r.Tag),
+		LeaseTime: strings.TrimSpace(r.LeaseTime),
+		Comment:   strings.TrimSpace(r.Comment),
+		Enabled:   r.Enabled == nil || *r.Enabled,
+		Ignore:    r.Ignore,
+	}
+	if err := entry.SetMAC(strings.TrimSpace(r.MAC)); err != nil {
+		return entry, err
+	}
+	if err := entry.SetIP(strings.TrimSpace(r.IP)); err != nil {
+		return entry, err
+	}
+	if err := entry.Validate(); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// FormatOf returns the format of a file from its extension, or "" when
+// it is not a bulk format
+func FormatOf(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return ""
+}
+
+// ParseFormat checks a format name, accepting "yml" for YAML
+func ParseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unknown format %q; use csv, json or yaml", format)
+}
+
+// Export writes entries as CSV, JSON or YAML
+func Export(w io.Writer, format string, entries []models.StaticDHCPEntry) error {
+	records := make([]Record, len(entries))
+	for i, entry := range entries {
+		records[i] = NewRecord(entry)
+	}
+
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(csvColumns)
+		for _, r := range records {
+			cw.Write([]string{r.MAC, r.IP, r.Hostname, r.Tag, r.LeaseTime, r.Comment,
+				strconv.FormatBool(*r.Enabled), strconv.FormatBool(r.Ignore)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case FormatYAML:
+		return writeYAML(w, records)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// Import reads records from a CSV, JSON or YAML file. A file that cannot
+// be read is an error; records that do not make a valid entry are
+// returned with their error.
+func Import(r io.Reader, format string) ([]ImportRow, error) {
+	switch format {
+	case FormatCSV:
+		return importCSV(r)
+	case FormatJSON:
+		var records []Record
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		rows := make([]ImportRow, len(records))
+		for i, record := range records {
+			rows[i] = newImportRow(i+1, record)
+		}
+		return rows, nil
+	case FormatYAML:
+		return importYAML(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// newImportRow converts a record read from row
+func newImportRow(row int, record Record) ImportRow {
+	entry, err := record.Entry()
+	if err != nil {
+		return ImportRow{Row: row, Entry: entry, Err: err.Error()}
+	}
+	return ImportRow{Row: row, Entry: entry}
+}
+
+// importCSV reads a CSV file whose first line names the columns. Unknown
+// columns are skipped, so spreadsheets can carry extra ones.
+func importCSV(r io.Reader) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	hasMAC := false
+	for i, name := range header {
+		columns[i] = fieldName(strings.TrimPrefix(name, "\ufeff"))
+		hasMAC = hasMAC || columns[i] == "mac"
+	}
+	if !hasMAC {
+		return nil, fmt.Errorf("the CSV header has no mac column")
+	}
+
+	var rows []ImportRow
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+			}
+			rows = append(rows, ImportRow{Row: line, Err: err.Error()})
+			continue
+		}
+		if strings.TrimSpace(strings.Join(fields, "")) == "" {
+			continue
+		}
+
+		var record Record
+		var fieldErr error
+		for i, value := range fields {
+			if i >= len(columns) || columns[i] == "" {
+				continue
+			}
+			if err := setRecordField(&record, columns[i], strings.TrimSpace(value)); err != nil && fieldErr == nil {
+				fieldErr = err
+			}
+		}
+		if fieldErr != nil {
+			rows = append(rows, ImportRow{Row: line, Err: fieldErr.Error()})
+			continue
+		}
+		rows = append(rows, newImportRow(line, record))
+	}
+	return rows, nil
+}
+
+// setRecordField sets the field of a record named by key, which may be
+// any of the CSV column names and aliases. Unknown keys are ignored.
+func setRecordField(record *Record, key, value string) error {
+	switch fieldName(key) {
+	case "mac":
+		record.MAC = value
+	case "ip":
+		record.IP = value
+	case "hostname":
+		record.Hostname = value
+	case "tag":
+		record.Tag = value
+	case "leaseTime":
+		record.LeaseTime = value
+	case "comment":
+		record.Comment = value
+	case "enabled":
+		if value != "" {
+			enabled, err := parseBool(value)
+			if err != nil {
+				return fmt.Errorf("enabled: %w", err)
+			}
+			record.Enabled = &enabled
+		}
+	case "ignore":
+		if value != "" {
+			ignore, err := parseBool(value)
+			if err != nil {
+				return fmt.Errorf("ignore: %w", err)
+			}
+			record.Ignore = ignore
+		}
+	}
+	return nil
+}
+
+// fieldName returns the record field a column or key names, or "" when
+// it names none
+func fieldName(key string) string {
+	key = strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(key)))
+	return csvAliases[key]
+}
+
+// parseBool accepts the usual spreadsheet spellings of true and false
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "y", "on", "x":
+		return true, nil
+	case "0", "false", "no", "n", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("not a boolean: %q", value)
+}
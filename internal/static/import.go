@@ -0,0 +1,234 @@
+// ===== internal/static/import.go =====
+package static
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"dhcpmon/pkg/models"
+)
+
+// Import modes
+const (
+	ImportMerge   = "merge"   // Add new reservations and update existing ones
+	ImportReplace = "replace" // The file becomes the complete list
+)
+
+// Actions in an import plan
+const (
+	ImportAdd       = "add"
+	ImportUpdate    = "update"
+	ImportRemove    = "remove"
+	ImportUnchanged = "unchanged"
+)
+
+// ImportChange is one step of an import: a row of the file and the
+// reservation it adds or updates, or a reservation a replace removes
+type ImportChange struct {
+	Action string  `json:"action"`
+	Row    int     `json:"row,omitempty"` // 0 for removals
+	MAC    string  `json:"mac"`
+	Before *Record `json:"before,omitempty"`
+	After  *Record `json:"after,omitempty"`
+}
+
+// RowError explains why a row of the file cannot be imported
+type RowError struct {
+	Row   int    `json:"row"`
+	MAC   string `json:"mac,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportResult describes an import. When Errors is not empty nothing was
+// changed.
+type ImportResult struct {
+	Mode      string         `json:"mode"`
+	Rows      int            `json:"rows"` // Rows read from the file
+	DryRun    bool           `json:"dryRun"`
+	Applied   bool           `json:"applied"`
+	Added     int            `json:"added"`
+	Updated   int            `json:"updated"`
+	Removed   int            `json:"removed"`
+	Unchanged int            `json:"unchanged"`
+	Changes   []ImportChange `json:"changes"`
+	Errors    []RowError     `json:"errors"`
+}
+
+// HasChanges reports whether the import adds, updates or removes anything
+func (r *ImportResult) HasChanges() bool {
+	return r.Added+r.Updated+r.Removed > 0
+}
+
+// FailedRows returns the number of rows with errors
+func (r *ImportResult) FailedRows() int {
+	rows := make(map[int]bool)
+	for _, e := range r.Errors {
+		rows[e.Row] = true
+	}
+	return len(rows)
+}
+
+// Import applies rows read from a bulk file. Rows are matched to existing
+// reservations by MAC address; a matched reservation keeps its ID and
+// position. In merge mode other reservations are kept, in replace mode
+// they are removed. Rows are checked like Add checks new entries, against
+// the reservations as they would be after the import. Nothing is changed
+// when any row has an error, or when dryRun is set.
+func (m *Manager) Import(rows []ImportRow, mode string, dryRun bool) (*ImportResult, error) {
+	if mode != ImportMerge && mode != ImportReplace {
+		return nil, fmt.Errorf("unknown import mode %q; use merge or replace", mode)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &ImportResult{Mode: mode, Rows: len(rows), DryRun: dryRun, Changes: []ImportChange{}, Errors: []RowError{}}
+
+	// Index the existing reservations by MAC, preferring enabled ones
+	existing := make(map[string]int)
+	for i, entry := range m.entries {
+		if entry.MAC == nil {
+			continue
+		}
+		mac := entry.MAC.String()
+		if j, ok := existing[mac]; !ok || (!m.entries[j].Enabled && entry.Enabled) {
+			existing[mac] = i
+		}
+	}
+
+	final := make([]models.StaticDHCPEntry, len(m.entries))
+	copy(final, m.entries)
+	matched := make(map[int]bool)
+	finalRow := make(map[int]int) // Index in final to file row
+	seen := make(map[string]int)
+	changed := make(map[int]bool) // File rows that add or update
+
+	for _, row := range rows {
+		if row.Err != "" {
+			if row.Entry.MAC != nil {
+				if _, dup := seen[row.Entry.MAC.String()]; !dup {
+					seen[row.Entry.MAC.String()] = row.Row
+				}
+			}
+			result.Errors = append(result.Errors, RowError{Row: row.Row, MAC: row.Entry.GetFormattedMAC(), Error: row.Err})
+			continue
+		}
+		entry := row.Entry
+		mac := entry.MAC.String()
+		if first, dup := seen[mac]; dup {
+			result.Errors = append(result.Errors, RowError{Row: row.Row, MAC: entry.GetFormattedMAC(),
+				Error: fmt.Sprintf("MAC address %s is also in row %d", entry.GetFormattedMAC(), first)})
+			continue
+		}
+		seen[mac] = row.Row
+
+		if i, ok := existing[mac]; ok {
+			matched[i] = true
+			entry.ID = m.entries[i].ID
+			entry.LineNumber = m.entries[i].LineNumber
+			before := NewRecord(m.entries[i])
+			after := NewRecord(entry)
+			action := ImportUpdate
+			if entry.Equal(&m.entries[i]) {
+				action = ImportUnchanged
+				result.Unchanged++
+			} else {
+				result.Updated++
+				final[i] = entry
+				changed[row.Row] = true
+			}
+			finalRow[i] = row.Row
+			result.Changes = append(result.Changes, ImportChange{Action: action, Row: row.Row, MAC: after.MAC, Before: &before, After: &after})
+			continue
+		}
+
+		entry.LineNumber = len(final) + 1
+		finalRow[len(final)] = row.Row
+		changed[row.Row] = true
+		final = append(final, entry)
+		after := NewRecord(entry)
+		result.Added++
+		result.Changes = append(result.Changes, ImportChange{Action: ImportAdd, Row: row.Row, MAC: after.MAC, After: &after})
+	}
+
+	if mode == ImportReplace {
+		kept := final[:0]
+		keptRow := make(map[int]int)
+		for i, entry := range final {
+			if i < len(m.entries) && !matched[i] {
+				before := NewRecord(entry)
+				result.Removed++
+				result.Changes = append(result.Changes, ImportChange{Action: ImportRemove, MAC: before.MAC, Before: &before})
+				continue
+			}
+			if row, ok := finalRow[i]; ok {
+				keptRow[len(kept)] = row
+			}
+			kept = append(kept, entry)
+		}
+		final = kept
+		finalRow = keptRow
+	}
+
+	// Check the rows that change something against the reservations they
+	// would sit beside
+	for i, entry := range final {
+		row, fromFile := finalRow[i]
+		if !fromFile || !changed[row] || !entry.Enabled {
+			continue
+		}
+		for j, other := range final {
+			if j == i || !other.Enabled {
+				continue
+			}
+			if otherRow, ok := finalRow[j]; ok && changed[otherRow] && j < i {
+				continue // Reported on the earlier row
+			}
+			if other.MAC.String() == entry.MAC.String() {
+				result.Errors = append(result.Errors, RowError{Row: row, MAC: entry.GetFormattedMAC(),
+					Error: fmt.Sprintf("MAC address %s already exists", entry.GetFormattedMAC())})
+			}
+			if entry.IP != nil && other.IP != nil && other.IP.Equal(entry.IP) {
+				msg := fmt.Sprintf("IP address %s already exists", entry.IP.String())
+				if otherRow, ok := finalRow[j]; ok {
+					msg = fmt.Sprintf("IP address %s is also in row %d", entry.IP.String(), otherRow)
+				} else if other.Hostname != "" {
+					msg += " (" + other.Hostname + ")"
+				}
+				result.Errors = append(result.Errors, RowError{Row: row, MAC: entry.GetFormattedMAC(), Error: msg})
+			}
+		}
+	}
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Row < result.Errors[j].Row })
+
+	if len(result.Errors) > 0 || dryRun || !result.HasChanges() {
+		return result, nil
+	}
+
+	for i := range final {
+		if final[i].ID == "" {
+			final[i].ID = newID()
+		}
+	}
+	m.entries = final
+	result.Applied = true
+	return result, nil
+}
+
+// Summary describes the result in one line, such as "2 added, 1 updated"
+func (r *ImportResult) Summary() string {
+	var parts []string
+	for _, count := range []struct {
+		n    int
+		verb string
+	}{{r.Added, "added"}, {r.Updated, "updated"}, {r.Removed, "removed"}, {r.Unchanged, "unchanged"}} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.verb))
+		}
+	}
+	if len(parts) == 0 {
+		return "no reservations"
+	}
+	return strings.Join(parts, ", ")
+}
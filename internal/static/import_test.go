@@ -0,0 +1,127 @@
+// ===== internal/static/import_test.go =====
+package static
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		csv     string
+		wantErr []string // "row: substring" of each error, in order
+		wantIPs map[string]string
+		summary string
+	}{
+		{
+			name:    "merge adds and updates",
+			mode:    ImportMerge,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:01,192.168.1.20,alpha\n00:11:22:33:44:04,192.168.1.21,delta\n",
+			wantIPs: map[string]string{"alpha": "192.168.1.20", "beta": "192.168.1.11", "delta": "192.168.1.21"},
+			summary: "1 added, 1 updated",
+		},
+		{
+			name:    "swap addresses",
+			mode:    ImportMerge,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:01,192.168.1.11,alpha\n00:11:22:33:44:02,192.168.1.10,beta\n",
+			wantIPs: map[string]string{"alpha": "192.168.1.11", "beta": "192.168.1.10"},
+			summary: "2 updated",
+		},
+		{
+			name:    "address of a kept reservation",
+			mode:    ImportMerge,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:04,192.168.1.11,delta\n",
+			wantErr: []string{"2: IP address 192.168.1.11 already exists (beta)"},
+		},
+		{
+			name:    "address of a removed reservation",
+			mode:    ImportReplace,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:04,192.168.1.11,delta\n",
+			wantIPs: map[string]string{"delta": "192.168.1.11"},
+			summary: "1 added, 3 removed",
+		},
+		{
+			name:    "address of a disabled reservation",
+			mode:    ImportMerge,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:04,192.168.1.12,delta\n",
+			wantIPs: map[string]string{"alpha": "192.168.1.10", "beta": "192.168.1.11", "delta": "192.168.1.12"},
+			summary: "1 added",
+		},
+		{
+			name: "rows sharing an address",
+			mode: ImportMerge,
+			csv:  "mac,ip,hostname\n00:11:22:33:44:04,192.168.1.20,delta\n00:11:22:33:44:05,192.168.1.20,epsilon\n",
+			wantErr: []string{
+				"2: IP address 192.168.1.20 is also in row 3",
+			},
+		},
+		{
+			name:    "rows sharing a MAC",
+			mode:    ImportMerge,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:04,192.168.1.20,delta\n00:11:22:33:44:04,192.168.1.21,delta\n",
+			wantErr: []string{"3: MAC address 00:11:22:33:44:04 is also in row 2"},
+		},
+		{
+			name:    "invalid row",
+			mode:    ImportMerge,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:04,192.168.1.300,delta\n00:11:22:33:44:01,192.168.1.10,alpha\n",
+			wantErr: []string{"2: "},
+		},
+		{
+			name:    "unchanged",
+			mode:    ImportMerge,
+			csv:     "mac,ip,hostname\n00:11:22:33:44:02,192.168.1.11,beta\n",
+			wantIPs: map[string]string{"alpha": "192.168.1.10", "beta": "192.168.1.11"},
+			summary: "1 unchanged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, testEntries)
+			before := addresses(m)
+			rows, err := Import(strings.NewReader(tt.csv), FormatCSV)
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			result, err := m.Import(rows, tt.mode, false)
+			if err != nil {
+				t.Fatalf("Manager.Import: %v", err)
+			}
+
+			if len(result.Errors) != len(tt.wantErr) {
+				t.Fatalf("errors = %+v, want %q", result.Errors, tt.wantErr)
+			}
+			for i, want := range tt.wantErr {
+				row, msg, _ := strings.Cut(want, ": ")
+				got := result.Errors[i]
+				if row != strconv.Itoa(got.Row) || !strings.Contains(got.Error, msg) {
+					t.Errorf("error %d = row %d %q, want %q", i, got.Row, got.Error, want)
+				}
+			}
+
+			want := tt.wantIPs
+			if len(tt.wantErr) > 0 {
+				want = before
+				if result.Applied {
+					t.Errorf("import with errors was applied")
+				}
+			} else if got := result.Summary(); got != tt.summary {
+				t.Errorf("Summary = %q, want %q", got, tt.summary)
+			}
+			if got := addresses(m); !sameAddresses(got, want) {
+				t.Errorf("addresses = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestImportMode(t *testing.T) {
+	m := newTestManager(t, testEntries)
+	if _, err := m.Import(nil, "append", false); err == nil {
+		t.Errorf("Import with mode append succeeded")
+	}
+}
@@ -3,14 +3,24 @@ package static
 
 import (
 	"fmt"
-	"log"
 	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	
 	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
 )
 
+// entrySeq makes IDs of entries added within the same clock tick unique
+var entrySeq uint64
+
+// newID returns an ID for a new entry
+func newID() string {
+	return fmt.Sprintf("entry_%d_%d", time.Now().Unix(), atomic.AddUint64(&entrySeq, 1))
+}
+
 // Manager handles static DHCP configuration management
 type Manager struct {
 	parser     *Parser
@@ -42,7 +52,7 @@ func (m *Manager) Load() error {
 	m.entries = entries
 	m.lastModify = time.Now()
 	
-	log.Printf("Loaded %d static DHCP entries from %s", len(entries), m.filename)
+	utils.Infof("Loaded %d static DHCP entries from %s", len(entries), m.filename)
 	return nil
 }
 
@@ -61,7 +71,7 @@ func (m *Manager) Save() error {
 	m.lastModify = time.Now()
 	m.mu.Unlock()
 	
-	log.Printf("Saved %d static DHCP entries to %s", len(entries), m.filename)
+	utils.Infof("Saved %d static DHCP entries to %s", len(entries), m.filename)
 	return nil
 }
 
@@ -116,7 +126,7 @@ func (m *Manager) Add(entry models.StaticDHCPEntry) error {
 	}
 	
 	// Generate new ID
-	entry.ID = fmt.Sprintf("entry_%d", time.Now().Unix())
+	entry.ID = newID()
 	entry.LineNumber = len(m.entries) + 1
 	
 	m.entries = append(m.entries, entry)
@@ -203,6 +213,20 @@ func (m *Manager) setEnabled(id string, enabled bool) error {
 	return fmt.Errorf("entry with ID %s not found", id)
 }
 
+// Snapshot returns a copy of the entries, to Restore if a change fails
+func (m *Manager) Snapshot() []models.StaticDHCPEntry {
+	return m.GetAll()
+}
+
+// Restore replaces the entries with a Snapshot
+func (m *Manager) Restore(entries []models.StaticDHCPEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = make([]models.StaticDHCPEntry, len(entries))
+	copy(m.entries, entries)
+}
+
 // GetByMAC returns entries with a specific MAC address
 func (m *Manager) GetByMAC(mac net.HardwareAddr) []models.StaticDHCPEntry {
 	m.mu.RLock()
@@ -233,26 +257,70 @@ func (m *Manager) GetByIP(ip net.IP) []models.StaticDHCPEntry {
 	return results
 }
 
+// Kinds of validation problems
+const (
+	ProblemInvalid      = "invalid"       // The entry itself is invalid
+	ProblemDuplicateMAC = "duplicate-mac" // Two enabled entries share a MAC
+	ProblemDuplicateIP  = "duplicate-ip"  // Two enabled entries share an IP
+)
+
+// Problem is a validation problem of the entry at Index, or a duplicate
+// of the entry at Other
+type Problem struct {
+	Kind  string
+	Value string // The duplicated MAC or IP
+	Index int
+	Other int // -1 unless the problem is a duplicate
+	Err   error
+}
+
+// Error describes the problem with the positions of the entries
+func (p Problem) Error() string {
+	if p.Kind == ProblemInvalid {
+		return fmt.Sprintf("entry %d: %v", p.Index+1, p.Err)
+	}
+	return fmt.Sprintf("%v in entries %d and %d", p.Err, p.Other+1, p.Index+1)
+}
+
+// Unwrap returns the underlying error
+func (p Problem) Unwrap() error {
+	return p.Err
+}
+
 // Validate validates all entries and returns any errors
 func (m *Manager) Validate() []error {
+	var errors []error
+	for _, p := range m.Problems() {
+		errors = append(errors, p)
+	}
+	
+	return errors
+}
+
+// Problems validates all entries and returns the problems with the
+// positions of the entries involved
+func (m *Manager) Problems() []Problem {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	var errors []error
+	var problems []Problem
 	macMap := make(map[string]int)
 	ipMap := make(map[string]int)
 	
 	for i, entry := range m.entries {
 		// Validate individual entry
 		if err := entry.Validate(); err != nil {
-			errors = append(errors, fmt.Errorf("entry %d: %w", i+1, err))
+			problems = append(problems, Problem{Kind: ProblemInvalid, Index: i, Other: -1, Err: err})
 		}
 		
 		// Check for duplicate MACs among enabled entries
 		if entry.Enabled && entry.MAC != nil {
 			macStr := entry.MAC.String()
 			if existing, exists := macMap[macStr]; exists {
-				errors = append(errors, fmt.Errorf("duplicate MAC %s in entries %d and %d", macStr, existing+1, i+1))
+				problems = append(problems, Problem{
+					Kind: ProblemDuplicateMAC, Value: macStr, Index: i, Other: existing,
+					Err: fmt.Errorf("duplicate MAC %s", macStr),
+				})
 			} else {
 				macMap[macStr] = i
 			}
@@ -262,13 +330,163 @@ func (m *Manager) Validate() []error {
 		if entry.Enabled && entry.IP != nil {
 			ipStr := entry.IP.String()
 			if existing, exists := ipMap[ipStr]; exists {
-				errors = append(errors, fmt.Errorf("duplicate IP %s in entries %d and %d", ipStr, existing+1, i+1))
+				problems = append(problems, Problem{
+					Kind: ProblemDuplicateIP, Value: ipStr, Index: i, Other: existing,
+					Err: fmt.Errorf("duplicate IP %s", ipStr),
+				})
 			} else {
 				ipMap[ipStr] = i
 			}
 		}
 	}
 	
-	return errors
+	return problems
 }
 
+
+// Ways of blocking a device
+const (
+	BlockIgnore     = "ignore"     // dnsmasq ignores the device
+	BlockQuarantine = "quarantine" // The device gets the quarantine tag
+)
+
+// Block stops dnsmasq from serving a device normally. With BlockIgnore
+// the device's reservation gets the ignore flag, or a
+// dhcp-host=<mac>,ignore entry is added. With BlockQuarantine its
+// reservations are disabled and a dhcp-host=<mac>,set:<tag> entry is
+// added, so it gets an address from the ranges for that tag. It returns
+// the keys of the reservations the quarantine disabled, for Unblock.
+func (m *Manager) Block(mac net.HardwareAddr, mode, quarantineTag, comment string) ([]string, error) {
+	if mode != BlockIgnore && mode != BlockQuarantine {
+		return nil, fmt.Errorf("unknown block mode %q", mode)
+	}
+	if mode == BlockQuarantine && quarantineTag == "" {
+		return nil, fmt.Errorf("no quarantine tag configured")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, entry := range m.entries {
+		if entry.Enabled && entry.MAC.String() == mac.String() && isBlocking(entry, quarantineTag) {
+			return nil, fmt.Errorf("%s is already blocked", strings.ToUpper(mac.String()))
+		}
+	}
+
+	hostname := ""
+	var disabled []string
+	for i, entry := range m.entries {
+		if !entry.Enabled || entry.MAC.String() != mac.String() {
+			continue
+		}
+		if mode == BlockIgnore {
+			m.entries[i].Ignore = true
+			return nil, nil
+		}
+		if hostname == "" {
+			hostname = entry.Hostname
+		}
+		m.entries[i].Enabled = false
+		disabled = append(disabled, ReservationKey(entry))
+	}
+
+	entry := models.StaticDHCPEntry{
+		ID:         newID(),
+		MAC:        mac,
+		Hostname:   hostname,
+		Comment:    comment,
+		Enabled:    true,
+		LineNumber: len(m.entries) + 1,
+	}
+	if mode == BlockIgnore {
+		entry.Ignore = true
+	} else {
+		entry.Tag = quarantineTag
+	}
+	m.entries = append(m.entries, entry)
+	return disabled, nil
+}
+
+// Unblock undoes Block: the ignore flag is cleared, entries that only
+// blocked the device are removed, and when a quarantine is lifted the
+// disabled reservations whose keys are in restore are enabled again.
+// Reservations disabled by other means stay disabled.
+func (m *Manager) Unblock(mac net.HardwareAddr, quarantineTag string, restore []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unblocked, quarantined := false, false
+	kept := m.entries[:0]
+	for _, entry := range m.entries {
+		if entry.Enabled && entry.MAC.String() == mac.String() {
+			switch {
+			case isQuarantine(entry, quarantineTag):
+				unblocked, quarantined = true, true
+				continue
+			case entry.Ignore:
+				unblocked = true
+				if entry.IP == nil && entry.Hostname == "" && entry.Tag == "" {
+					continue
+				}
+				entry.Ignore = false
+			}
+		}
+		kept = append(kept, entry)
+	}
+	m.entries = kept
+
+	if !unblocked {
+		return fmt.Errorf("%s is not blocked", strings.ToUpper(mac.String()))
+	}
+	if !quarantined {
+		return nil
+	}
+
+	// Enable the reservations the quarantine replaced, unless the device
+	// has an active one
+	for _, entry := range m.entries {
+		if entry.Enabled && entry.MAC.String() == mac.String() {
+			return nil
+		}
+	}
+	for i, entry := range m.entries {
+		if entry.MAC.String() == mac.String() && utils.ContainsString(restore, ReservationKey(entry)) {
+			m.entries[i].Enabled = true
+		}
+	}
+	return nil
+}
+
+// ReservationKey identifies a reservation by its dhcp-host parameters,
+// whether it is enabled or not and whatever its comment says
+func ReservationKey(entry models.StaticDHCPEntry) string {
+	entry.Enabled, entry.Comment = true, ""
+	return entry.ToDnsmasqLine()
+}
+
+// Blocked returns the enabled entries that block a device: those with
+// the ignore flag, and those setting the quarantine tag without a fixed
+// address
+func (m *Manager) Blocked(quarantineTag string) []models.StaticDHCPEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []models.StaticDHCPEntry
+	for _, entry := range m.entries {
+		if entry.Enabled && isBlocking(entry, quarantineTag) {
+			results = append(results, entry)
+		}
+	}
+	return results
+}
+
+// isQuarantine reports whether an entry quarantines its device: it sets
+// the quarantine tag without a fixed address
+func isQuarantine(entry models.StaticDHCPEntry, quarantineTag string) bool {
+	return quarantineTag != "" && entry.Tag == quarantineTag && entry.IP == nil
+}
+
+// isBlocking reports whether an entry blocks or quarantines its device
+func isBlocking(entry models.StaticDHCPEntry, quarantineTag string) bool {
+	return entry.Ignore || isQuarantine(entry, quarantineTag)
+}
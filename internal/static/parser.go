@@ -126,6 +126,12 @@ func (p *Parser) parseLine(line string, lineNumber int) (*models.StaticDHCPEntry
 			continue
 		}
 		
+		// "ignore" makes dnsmasq refuse the device
+		if strings.EqualFold(param, "ignore") {
+			entry.Ignore = true
+			continue
+		}
+		
 		// Try to parse as MAC address
 		if mac, err := net.ParseMAC(param); err == nil {
 			entry.MAC = mac
@@ -153,18 +159,25 @@ func (p *Parser) parseLine(line string, lineNumber int) (*models.StaticDHCPEntry
 	return entry, nil
 }
 
-// isLeaseTime checks if a parameter looks like a lease time specification
+// isLeaseTime checks if a parameter is a lease time specification:
+// "infinite", or a number with an optional s, m, h, d or w unit
 func (p *Parser) isLeaseTime(param string) bool {
 	param = strings.ToLower(param)
-	suffixes := []string{"s", "m", "h", "d", "w", "infinite"}
+	if param == "infinite" {
+		return true
+	}
 	
-	for _, suffix := range suffixes {
-		if strings.HasSuffix(param, suffix) {
-			return true
+	digits := strings.TrimRight(param, "smhdw")
+	if len(param)-len(digits) > 1 || digits == "" {
+		return false
+	}
+	for _, char := range digits {
+		if char < '0' || char > '9' {
+			return false
 		}
 	}
 	
-	return false
+	return true
 }
 
 // WriteFile writes static DHCP entries back to a configuration file
@@ -184,7 +197,7 @@ func (p *Parser) WriteFile(filename string, entries []models.StaticDHCPEntry) er
 	// Write header comment
 	fmt.Fprintln(file, "# Static DHCP reservations")
 	fmt.Fprintln(file, "# Generated by DHCP Monitor")
-	fmt.Fprintln(file, "# Format: dhcp-host=MAC,IP,hostname[,lease-time]")
+	fmt.Fprintln(file, "# Format: dhcp-host=MAC,IP,hostname[,lease-time][,ignore]")
 	fmt.Fprintln(file, "")
 	
 	// Write entries
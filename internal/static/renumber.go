@@ -0,0 +1,153 @@
+// ===== internal/static/renumber.go =====
+package static
+
+import (
+	"fmt"
+	"net"
+)
+
+// RenumberPlan maps addresses from one network to another. Addresses in
+// From move to the same host offset in To, and Mapping gives the new
+// address of individual addresses, overriding the offset.
+type RenumberPlan struct {
+	From    *net.IPNet
+	To      *net.IPNet
+	Mapping map[string]net.IP // Old address, as IP.String(), to new address
+}
+
+// ParseRenumberPlan builds a plan from two CIDRs and an explicit mapping
+// of old to new addresses. Either the CIDRs or the mapping may be empty.
+func ParseRenumberPlan(from, to string, mapping map[string]string) (RenumberPlan, error) {
+	var plan RenumberPlan
+	if from != "" || to != "" {
+		if from == "" || to == "" {
+			return plan, fmt.Errorf("both the old and the new network are required")
+		}
+		var err error
+		if _, plan.From, err = net.ParseCIDR(from); err != nil {
+			return plan, fmt.Errorf("invalid network %q: %w", from, err)
+		}
+		if _, plan.To, err = net.ParseCIDR(to); err != nil {
+			return plan, fmt.Errorf("invalid network %q: %w", to, err)
+		}
+		if len(plan.From.IP) != len(plan.To.IP) {
+			return plan, fmt.Errorf("%s and %s are not the same address family", from, to)
+		}
+	}
+
+	plan.Mapping = make(map[string]net.IP, len(mapping))
+	for oldStr, newStr := range mapping {
+		oldIP, newIP := net.ParseIP(oldStr), net.ParseIP(newStr)
+		if oldIP == nil {
+			return plan, fmt.Errorf("invalid IP address %q in mapping", oldStr)
+		}
+		if newIP == nil {
+			return plan, fmt.Errorf("invalid IP address %q in mapping", newStr)
+		}
+		if (oldIP.To4() == nil) != (newIP.To4() == nil) {
+			return plan, fmt.Errorf("%s and %s are not the same address family", oldIP, newIP)
+		}
+		plan.Mapping[oldIP.String()] = newIP
+	}
+
+	if plan.From == nil && len(plan.Mapping) == 0 {
+		return plan, fmt.Errorf("nothing to renumber: give the networks or a mapping")
+	}
+	return plan, nil
+}
+
+// Map returns the new address of ip, or nil when the plan leaves it
+// alone. An address whose offset does not fit in the new network is an
+// error.
+func (p RenumberPlan) Map(ip net.IP) (net.IP, error) {
+	if ip == nil {
+		return nil, nil
+	}
+	if newIP, ok := p.Mapping[ip.String()]; ok {
+		return newIP, nil
+	}
+	if p.From == nil || !p.From.Contains(ip) {
+		return nil, nil
+	}
+
+	if v4 := ip.To4(); v4 != nil && len(p.From.IP) == net.IPv4len {
+		ip = v4
+	}
+	newIP := make(net.IP, len(ip))
+	for i := range ip {
+		host := ip[i] &^ p.From.Mask[i]
+		if host&p.To.Mask[i] != 0 {
+			return nil, fmt.Errorf("%s does not fit in %s at the same offset", ip, p.To)
+		}
+		newIP[i] = p.To.IP[i] | host
+	}
+	return newIP, nil
+}
+
+// RenumberChange is an entry the plan moves, with its configuration line
+// before and after
+type RenumberChange struct {
+	ID       string `json:"id"`
+	MAC      string `json:"mac"`
+	Hostname string `json:"hostname,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+}
+
+// RenumberResult describes a renumbering. When Errors is not empty nothing
+// was changed.
+type RenumberResult struct {
+	DryRun  bool             `json:"dryRun"`
+	Applied bool             `json:"applied"`
+	Changes []RenumberChange `json:"changes"`
+	Errors  []string         `json:"errors"`
+}
+
+// Renumber moves every reservation, enabled or not, whose address the
+// plan maps. The moves are applied as one batch, so reservations may
+// take each other's addresses, and the end state is checked for
+// duplicate addresses. Nothing is changed when there are errors or
+// dryRun is set.
+func (m *Manager) Renumber(plan RenumberPlan, dryRun bool) *RenumberResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := &RenumberResult{DryRun: dryRun, Changes: []RenumberChange{}, Errors: []string{}}
+	var ops []BatchOp
+	for _, entry := range m.entries {
+		newIP, err := plan.Map(entry.IP)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s (%s): %v", entry.GetFormattedMAC(), entry.GetDisplayName(), err))
+			continue
+		}
+		if newIP == nil || newIP.Equal(entry.IP) {
+			continue
+		}
+
+		moved := *entry.Clone()
+		moved.IP = newIP
+		ops = append(ops, BatchOp{Action: BatchUpdate, ID: entry.ID, Entry: moved})
+		result.Changes = append(result.Changes, RenumberChange{
+			ID:       entry.ID,
+			MAC:      entry.GetFormattedMAC(),
+			Hostname: entry.Hostname,
+			From:     entry.GetFormattedIP(),
+			To:       newIP.String(),
+			Before:   entry.ToDnsmasqLine(),
+			After:    moved.ToDnsmasqLine(),
+		})
+	}
+	if len(result.Errors) > 0 || len(ops) == 0 {
+		return result
+	}
+
+	batch := m.batch(ops, dryRun)
+	for _, e := range batch.Errors {
+		change := result.Changes[e.Op-1]
+		result.Errors = append(result.Errors, fmt.Sprintf("%s (%s) to %s: %s", change.MAC, change.From, change.To, e.Error))
+	}
+	result.Applied = batch.Applied
+	return result
+}
@@ -0,0 +1,152 @@
+// ===== internal/static/renumber_test.go =====
+package static
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestRenumberPlanMap(t *testing.T) {
+	tests := []struct {
+		from, to string
+		mapping  map[string]string
+		ip       string
+		want     string // "" when the plan leaves the address alone
+		wantErr  bool
+	}{
+		{from: "192.168.1.0/24", to: "10.0.0.0/24", ip: "192.168.1.10", want: "10.0.0.10"},
+		{from: "192.168.1.0/24", to: "10.0.0.0/24", ip: "192.168.1.255", want: "10.0.0.255"},
+		{from: "192.168.1.0/24", to: "10.0.0.0/24", ip: "192.168.2.10"},
+		{from: "192.168.1.0/24", to: "10.1.0.0/16", ip: "192.168.1.10", want: "10.1.0.10"},
+		{from: "192.168.0.0/16", to: "10.0.0.0/24", ip: "192.168.0.10", want: "10.0.0.10"},
+		{from: "192.168.0.0/16", to: "10.0.0.0/24", ip: "192.168.1.10", wantErr: true}, // Offset 1.10 overflows a /24
+		{from: "192.168.1.0/25", to: "192.168.1.128/25", ip: "192.168.1.10", want: "192.168.1.138"},
+		{from: "192.168.1.0/24", to: "192.168.1.128/25", ip: "192.168.1.200", wantErr: true},
+		{from: "2001:db8:1::/64", to: "2001:db8:2::/64", ip: "2001:db8:1::10", want: "2001:db8:2::10"},
+		{from: "2001:db8:1::/64", to: "2001:db8:2::/64", ip: "192.168.1.10"},
+		{
+			from: "192.168.1.0/24", to: "10.0.0.0/24",
+			mapping: map[string]string{"192.168.1.10": "10.0.0.99"},
+			ip:      "192.168.1.10", want: "10.0.0.99", // The mapping overrides the offset
+		},
+		{mapping: map[string]string{"192.168.1.10": "192.168.1.11"}, ip: "192.168.1.10", want: "192.168.1.11"},
+		{mapping: map[string]string{"192.168.1.10": "192.168.1.11"}, ip: "192.168.1.12"},
+	}
+	for _, tt := range tests {
+		plan, err := ParseRenumberPlan(tt.from, tt.to, tt.mapping)
+		if err != nil {
+			t.Fatalf("ParseRenumberPlan(%s, %s, %v): %v", tt.from, tt.to, tt.mapping, err)
+		}
+		got, err := plan.Map(net.ParseIP(tt.ip))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s -> %s: Map(%s) error = %v, want error %v", tt.from, tt.to, tt.ip, err, tt.wantErr)
+			continue
+		}
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("%s -> %s: Map(%s) = %s, want nil", tt.from, tt.to, tt.ip, got)
+			}
+			continue
+		}
+		if !got.Equal(net.ParseIP(tt.want)) {
+			t.Errorf("%s -> %s: Map(%s) = %s, want %s", tt.from, tt.to, tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestParseRenumberPlan(t *testing.T) {
+	tests := []struct {
+		from, to string
+		mapping  map[string]string
+	}{
+		{from: "192.168.1.0/24"},
+		{from: "192.168.1.0/24", to: "2001:db8::/64"},
+		{from: "192.168.1.0/33", to: "10.0.0.0/24"},
+		{mapping: map[string]string{"192.168.1.10": "2001:db8::10"}},
+		{mapping: map[string]string{"192.168.1.10": "host"}},
+		{},
+	}
+	for _, tt := range tests {
+		if _, err := ParseRenumberPlan(tt.from, tt.to, tt.mapping); err == nil {
+			t.Errorf("ParseRenumberPlan(%q, %q, %v) succeeded", tt.from, tt.to, tt.mapping)
+		}
+	}
+}
+
+func TestRenumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries string
+		from    string
+		to      string
+		mapping map[string]string
+		wantErr string
+		wantIPs map[string]string
+	}{
+		{
+			name:    "move network",
+			entries: testEntries,
+			from:    "192.168.1.0/24", to: "10.0.0.0/24",
+			wantIPs: map[string]string{"alpha": "10.0.0.10", "beta": "10.0.0.11"},
+		},
+		{
+			name:    "swap by mapping",
+			entries: testEntries,
+			mapping: map[string]string{"192.168.1.10": "192.168.1.11", "192.168.1.11": "192.168.1.10"},
+			wantIPs: map[string]string{"alpha": "192.168.1.11", "beta": "192.168.1.10"},
+		},
+		{
+			name:    "half a swap",
+			entries: testEntries,
+			mapping: map[string]string{"192.168.1.10": "192.168.1.11"},
+			wantErr: "IP address 192.168.1.11 already exists (beta)",
+		},
+		{
+			name:    "onto a reservation left in place",
+			entries: testEntries + "dhcp-host=00:11:22:33:44:04,10.0.0.11,delta\n",
+			from:    "192.168.1.0/24", to: "10.0.0.0/24",
+			wantErr: "IP address 10.0.0.11 already exists (delta)",
+		},
+		{
+			name:    "offset overflow",
+			entries: testEntries + "dhcp-host=00:11:22:33:44:04,192.168.2.10,delta\n",
+			from:    "192.168.0.0/16", to: "10.0.0.0/24",
+			wantErr: "does not fit in 10.0.0.0/24",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, tt.entries)
+			before := addresses(m)
+			plan, err := ParseRenumberPlan(tt.from, tt.to, tt.mapping)
+			if err != nil {
+				t.Fatalf("ParseRenumberPlan: %v", err)
+			}
+			result := m.Renumber(plan, false)
+
+			if tt.wantErr != "" {
+				if len(result.Errors) == 0 || !strings.Contains(strings.Join(result.Errors, "\n"), tt.wantErr) {
+					t.Errorf("errors = %q, want %q", result.Errors, tt.wantErr)
+				}
+				if result.Applied {
+					t.Errorf("renumbering with errors was applied")
+				}
+				if got := addresses(m); !sameAddresses(got, before) {
+					t.Errorf("addresses = %v, want %v", got, before)
+				}
+				return
+			}
+
+			if len(result.Errors) > 0 || !result.Applied {
+				t.Fatalf("errors = %q, applied %v", result.Errors, result.Applied)
+			}
+			for name, ip := range tt.wantIPs {
+				if got := addresses(m)[name]; got != ip {
+					t.Errorf("%s = %s, want %s", name, got, ip)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,434 @@
+// ===== internal/static/yaml.go =====
+package static
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The YAML support covers what exports and hand-written reservation lists
+// need: a list of flat mappings, optionally under one top-level key, with
+// plain, single-quoted or double-quoted scalars and # comments. Items are
+// either block mappings or flow mappings on one line. Double-quoted
+// scalars take all YAML escapes and may continue over several lines.
+
+// yamlSubset describes the supported YAML for error messages
+const yamlSubset = `dhcpmon reads a list of flat mappings, written as "- key: value" lines or as "- {key: value, ...}" on one line, with plain, 'single-quoted' or "double-quoted" values`
+
+// writeYAML writes records as a YAML list
+func writeYAML(w io.Writer, records []Record) error {
+	bw := bufio.NewWriter(w)
+	if len(records) == 0 {
+		bw.WriteString("[]\n")
+		return bw.Flush()
+	}
+
+	for _, r := range records {
+		fields := []struct{ key, value string }{
+			{"mac", r.MAC},
+			{"ip", r.IP},
+			{"hostname", r.Hostname},
+			{"tag", r.Tag},
+			{"leaseTime", r.LeaseTime},
+			{"comment", r.Comment},
+		}
+		prefix := "- "
+		for _, f := range fields {
+			if f.value == "" && f.key != "mac" {
+				continue
+			}
+			fmt.Fprintf(bw, "%s%s: %s\n", prefix, f.key, yamlQuote(f.value))
+			prefix = "  "
+		}
+		if r.Enabled != nil && !*r.Enabled {
+			bw.WriteString("  enabled: false\n")
+		}
+		if r.Ignore {
+			bw.WriteString("  ignore: true\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// yamlQuote returns s as a double-quoted scalar. JSON string escapes are
+// valid in YAML double-quoted scalars.
+func yamlQuote(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// importYAML reads a YAML list of reservations
+func importYAML(r io.Reader) ([]ImportRow, error) {
+	var rows []ImportRow
+	var record *Record
+	var recordLine, itemIndent int
+	var fieldErr error
+	seenList := false
+
+	// A double-quoted value continuing on the next lines
+	var pendingKey, pending string
+	var pendingLine int
+
+	finish := func() {
+		if record == nil {
+			return
+		}
+		if fieldErr != nil {
+			rows = append(rows, ImportRow{Row: recordLine, Err: fieldErr.Error()})
+		} else {
+			rows = append(rows, newImportRow(recordLine, *record))
+		}
+		record = nil
+		fieldErr = nil
+	}
+
+	setField := func(key, value string) {
+		if err := setRecordField(record, key, value); err != nil && fieldErr == nil {
+			fieldErr = err
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		raw := strings.TrimRight(line, " \t")
+		// Trailing whitespace is kept in a double-quoted value, where an
+		// escaped space may end a line
+		trailing := line[len(raw):]
+		if lineNum == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+		if pending != "" {
+			pending += "\n" + raw + trailing
+			if closingQuote(pending) < 0 {
+				continue
+			}
+			scalar, err := yamlScalar(pending)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			setField(pendingKey, scalar)
+			pending = ""
+			continue
+		}
+
+		text := strings.TrimSpace(raw)
+		if text == "" || strings.HasPrefix(text, "#") || text == "---" {
+			continue
+		}
+		leading := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		if strings.Contains(leading, "\t") {
+			return nil, fmt.Errorf("line %d: tabs cannot be used for indentation", lineNum)
+		}
+		indent := len(leading)
+
+		if text == "[]" && !seenList {
+			seenList = true
+			continue
+		}
+
+		if text == "-" || strings.HasPrefix(text, "- ") {
+			if record != nil && indent != itemIndent {
+				return nil, yamlError(lineNum, "nested lists are not supported")
+			}
+			finish()
+			seenList = true
+			record = &Record{}
+			recordLine = lineNum
+			itemIndent = indent
+			text = strings.TrimSpace(strings.TrimPrefix(text, "-"))
+			if text == "" {
+				continue
+			}
+			if text[0] == '{' {
+				fields, err := yamlFlowMapping(text)
+				if err != nil {
+					return nil, yamlError(lineNum, err.Error())
+				}
+				for _, f := range fields {
+					setField(f[0], f[1])
+				}
+				finish()
+				continue
+			}
+		} else if record == nil {
+			// A top-level key holding the list, such as "reservations:"
+			if !seenList && indent == 0 && strings.HasSuffix(text, ":") {
+				continue
+			}
+			return nil, yamlError(lineNum, `expected a list item starting with "- "`)
+		} else if indent <= itemIndent {
+			return nil, yamlError(lineNum, "unexpected indentation")
+		}
+
+		key, value, ok := strings.Cut(text, ":")
+		if !ok || (value != "" && value[0] != ' ') {
+			return nil, yamlError(lineNum, `expected "key: value"`)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) && closingQuote(value) < 0 {
+			pendingKey, pending, pendingLine = key, value+trailing, lineNum
+			continue
+		}
+		scalar, err := yamlScalar(value)
+		if err != nil {
+			return nil, yamlError(lineNum, err.Error())
+		}
+		setField(key, scalar)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read YAML: %w", err)
+	}
+	if pending != "" {
+		return nil, fmt.Errorf("line %d: unterminated string", pendingLine)
+	}
+	finish()
+	return rows, nil
+}
+
+// yamlError returns a syntax error at line, naming the supported YAML
+func yamlError(line int, msg string) error {
+	return fmt.Errorf("line %d: %s (%s)", line, msg, yamlSubset)
+}
+
+// yamlScalar returns the string value of a plain or quoted scalar
+func yamlScalar(value string) (string, error) {
+	switch {
+	case value == "":
+		return "", nil
+	case value[0] == '"':
+		end := closingQuote(value)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		s, err := yamlUnquote(value[1:end])
+		if err != nil {
+			return "", err
+		}
+		return s, trailingComment(value[end+1:])
+	case value[0] == '\'':
+		s, end, err := singleQuoted(value)
+		if err != nil {
+			return "", err
+		}
+		return s, trailingComment(value[end:])
+	case value[0] == '[' || value[0] == '{' || value[0] == '|' || value[0] == '>':
+		return "", fmt.Errorf("nested lists and mappings and block scalars are not supported")
+	case value[0] == '&' || value[0] == '*' || value[0] == '!':
+		return "", fmt.Errorf("anchors, aliases and tags are not supported")
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return plainValue(value), nil
+}
+
+// plainValue returns a plain scalar, reading ~ and null as empty
+func plainValue(value string) string {
+	if value == "~" || value == "null" {
+		return ""
+	}
+	return value
+}
+
+// yamlFlowMapping reads a flow mapping on one line, such as
+// {mac: "00:11:22:33:44:55", ip: 192.168.1.10}, into its keys and values
+func yamlFlowMapping(text string) ([][2]string, error) {
+	var fields [][2]string
+	rest := strings.TrimSpace(text[1:])
+	for {
+		if strings.HasPrefix(rest, "}") {
+			return fields, trailingComment(rest[1:])
+		}
+		if rest == "" {
+			return nil, fmt.Errorf("a {...} mapping must be closed on the same line")
+		}
+		key, after, err := yamlFlowScalar(rest, true)
+		if err != nil {
+			return nil, err
+		}
+		after = strings.TrimLeft(after, " \t")
+		if !strings.HasPrefix(after, ":") {
+			return nil, fmt.Errorf(`expected "key: value" in {...} mapping`)
+		}
+		value, after, err := yamlFlowScalar(strings.TrimLeft(after[1:], " \t"), false)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, [2]string{key, value})
+
+		after = strings.TrimLeft(after, " \t")
+		switch {
+		case strings.HasPrefix(after, ","):
+			rest = strings.TrimLeft(after[1:], " \t")
+		case strings.HasPrefix(after, "}"):
+			rest = after
+		default:
+			return nil, fmt.Errorf(`expected "," or "}" in {...} mapping`)
+		}
+	}
+}
+
+// yamlFlowScalar reads a key or value in a flow mapping and returns it
+// with the text after it. A plain key ends at ": ", a plain value at ","
+// or "}".
+func yamlFlowScalar(s string, key bool) (string, string, error) {
+	if s == "" {
+		return "", "", nil
+	}
+	switch s[0] {
+	case '"':
+		end := closingQuote(s)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		value, err := yamlUnquote(s[1:end])
+		return value, s[end+1:], err
+	case '\'':
+		value, end, err := singleQuoted(s)
+		return value, s[end:], err
+	case '[', '{':
+		return "", "", fmt.Errorf("nested lists and mappings are not supported")
+	case '&', '*', '!':
+		return "", "", fmt.Errorf("anchors, aliases and tags are not supported")
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case key && s[i] == ':' && (i+1 == len(s) || strings.IndexByte(" \t,}", s[i+1]) >= 0):
+			return strings.TrimSpace(s[:i]), s[i:], nil
+		case !key && (s[i] == ',' || s[i] == '}'):
+			return plainValue(strings.TrimSpace(s[:i])), s[i:], nil
+		}
+	}
+	return plainValue(strings.TrimSpace(s)), "", nil
+}
+
+// singleQuoted returns the value of the single-quoted scalar starting s
+// and the index after its closing quote
+func singleQuoted(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// yamlEscapes maps the single-character escapes of double-quoted scalars
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n",
+	'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"",
+	'/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028",
+	'P': "\u2029",
+}
+
+// yamlHexEscapes maps the hex escapes to their number of digits
+var yamlHexEscapes = map[byte]int{'x': 2, 'u': 4, 'U': 8}
+
+// yamlUnquote decodes the text between the quotes of a double-quoted
+// scalar. A line break folds into a space, or into one newline per empty
+// line after it, and an escaped line break joins the lines.
+func yamlUnquote(body string) (string, error) {
+	var b strings.Builder
+	// Whitespace written by an escape is kept when a line is folded
+	kept := 0
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\n':
+			folded := b.String()
+			cut := len(strings.TrimRight(folded, " \t"))
+			if cut < kept {
+				cut = kept
+			}
+			b.Reset()
+			b.WriteString(folded[:cut])
+			breaks := 0
+			for i+1 < len(body) && strings.IndexByte(" \t\n", body[i+1]) >= 0 {
+				i++
+				if body[i] == '\n' {
+					breaks++
+				}
+			}
+			if breaks == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteString(strings.Repeat("\n", breaks))
+			}
+		case c != '\\':
+			b.WriteByte(c)
+		case i+1 == len(body):
+			return "", fmt.Errorf("invalid escape at end of string")
+		default:
+			i++
+			e := body[i]
+			if e == '\n' {
+				for i+1 < len(body) && (body[i+1] == ' ' || body[i+1] == '\t') {
+					i++
+				}
+				continue
+			}
+			if s, ok := yamlEscapes[e]; ok {
+				b.WriteString(s)
+				kept = b.Len()
+				continue
+			}
+			n, ok := yamlHexEscapes[e]
+			if !ok {
+				return "", fmt.Errorf("invalid escape \\%c", e)
+			}
+			if i+n >= len(body) {
+				return "", fmt.Errorf("invalid escape \\%c%s", e, body[i+1:])
+			}
+			code, err := strconv.ParseUint(body[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape \\%c%s", e, body[i+1:i+1+n])
+			}
+			b.WriteRune(rune(code))
+			kept = b.Len()
+			i += n
+		}
+	}
+	return b.String(), nil
+}
+
+// closingQuote returns the index of the quote ending a double-quoted
+// scalar, or -1
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// trailingComment checks that only a comment follows a quoted scalar
+func trailingComment(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected text after string: %s", rest)
+	}
+	return nil
+}
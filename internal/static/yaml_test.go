@@ -0,0 +1,133 @@
+// ===== internal/static/yaml_test.go =====
+package static
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    []Record // IP, hostname and comment of each row
+		wantErr string
+	}{
+		{
+			name: "block mappings",
+			yaml: "# inventory\n- mac: 00:11:22:33:44:01\n  hostname: alpha # lab\n  comment: 'it''s here'\n-\n  mac: 00:11:22:33:44:02\n  ip: \"192.168.1.11\"\n  hostname: ~\n",
+			want: []Record{{Hostname: "alpha", Comment: "it's here"}, {IP: "192.168.1.11"}},
+		},
+		{
+			name: "under a top-level key",
+			yaml: "---\nreservations:\n  - mac: 00:11:22:33:44:01\n    hostname: alpha\n",
+			want: []Record{{Hostname: "alpha"}},
+		},
+		{
+			name: "flow mappings",
+			yaml: "- {mac: 00:11:22:33:44:01, hostname: alpha, comment: \"a, b}\"}\n- {mac: '00:11:22:33:44:02', ip: 192.168.1.11, comment: null,}  # trailing comma\n",
+			want: []Record{{Hostname: "alpha", Comment: "a, b}"}, {IP: "192.168.1.11"}},
+		},
+		{
+			name: "double-quoted escapes",
+			yaml: "- mac: 00:11:22:33:44:01\n  hostname: alpha\n  comment: \"\\e\\N\\_\\/\\\\\\\"\\x41\\u00e9\\U0001F600\ttab\"\n",
+			want: []Record{{Hostname: "alpha", Comment: "\x1b\u0085\u00a0/\\\"A\u00e9\U0001F600\ttab"}},
+		},
+		{
+			name: "folded double-quoted value",
+			yaml: "- mac: 00:11:22:33:44:01\n  hostname: alpha\n  comment: \"one\n    two\n\n    three \\\n    four\\ \n    five\"\n",
+			want: []Record{{Hostname: "alpha", Comment: "one two\nthree four  five"}},
+		},
+		{
+			name: "empty list",
+			yaml: "[]\n",
+		},
+		{
+			name:    "unknown escape",
+			yaml:    "- mac: \"\\q\"\n",
+			wantErr: `line 1: invalid escape \q`,
+		},
+		{
+			name:    "unterminated value",
+			yaml:    "- mac: 00:11:22:33:44:01\n  comment: \"open\n\n",
+			wantErr: "line 2: unterminated string",
+		},
+		{
+			name:    "flow mapping over several lines",
+			yaml:    "- {mac: 00:11:22:33:44:01,\n   hostname: alpha}\n",
+			wantErr: "line 1: a {...} mapping must be closed on the same line",
+		},
+		{
+			name:    "nested list",
+			yaml:    "- mac: 00:11:22:33:44:01\n  tags:\n    - a\n",
+			wantErr: "line 3: nested lists are not supported",
+		},
+		{
+			name:    "block scalar",
+			yaml:    "- comment: |\n    text\n",
+			wantErr: "line 1: nested lists and mappings and block scalars are not supported",
+		},
+		{
+			name:    "alias",
+			yaml:    "- mac: *mac\n",
+			wantErr: "line 1: anchors, aliases and tags are not supported",
+		},
+		{
+			name:    "flow sequence",
+			yaml:    "[{mac: 00:11:22:33:44:01}]\n",
+			wantErr: `line 1: expected a list item starting with "- "`,
+		},
+		{
+			name:    "tab indentation",
+			yaml:    "- mac: 00:11:22:33:44:01\n\thostname: alpha\n",
+			wantErr: "line 2: tabs cannot be used for indentation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Import(strings.NewReader(tt.yaml), FormatYAML)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(tt.want))
+			}
+			for i, row := range rows {
+				if row.Err != "" {
+					t.Errorf("row %d: %s", row.Row, row.Err)
+				}
+				got := Record{IP: row.Entry.GetFormattedIP(), Hostname: row.Entry.Hostname, Comment: row.Entry.Comment}
+				if got != tt.want[i] {
+					t.Errorf("row %d = %+v, want %+v", row.Row, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	m := newTestManager(t, testEntries)
+	var buf bytes.Buffer
+	if err := Export(&buf, FormatYAML, m.GetAll()); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	rows, err := Import(&buf, FormatYAML)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	result, err := m.Import(rows, ImportReplace, true)
+	if err != nil {
+		t.Fatalf("Manager.Import: %v", err)
+	}
+	if len(result.Errors) > 0 || result.HasChanges() {
+		t.Errorf("round trip: errors %+v, summary %s", result.Errors, result.Summary())
+	}
+}
@@ -0,0 +1,148 @@
+// ===== internal/web/approvals_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"dhcpmon/internal/ipam"
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// ApprovalRequest represents a device approval request
+type ApprovalRequest struct {
+	Action      string               `json:"action"` // approve, block or forget
+	MAC         string               `json:"mac"`
+	Note        string               `json:"note,omitempty"`
+	Mode        string               `json:"mode,omitempty"`        // Block mode: ignore (default) or quarantine
+	Reservation *StaticDHCPEntryJSON `json:"reservation,omitempty"` // Static entry to create on approve
+	Subnet      string               `json:"subnet,omitempty"`      // CIDR for an "auto" reservation IP
+}
+
+// handleApprovalsAPI lists devices by approval state (GET, ?state=) or
+// approves, blocks and forgets them (POST)
+func (s *Server) handleApprovalsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    s.monitor.GetApprovals(r.URL.Query().Get("state")),
+			"counts":  s.monitor.ApprovalCounts(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.requireEdit(w, r) {
+		return
+	}
+
+	var req ApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Action {
+	case "approve":
+		var reservation *models.StaticDHCPEntry
+		if req.Reservation != nil {
+			entry, err := s.approvalReservation(req)
+			if err != nil {
+				s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			reservation = &entry
+		}
+		approval, err := s.monitor.ApproveDevice(req.MAC, r.RemoteAddr, req.Note, reservation)
+		if err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		message := "Device " + approval.MAC + " approved"
+		if reservation != nil {
+			message += fmt.Sprintf(" with a reservation for %s", reservation.IP)
+			message += s.staticPendingNote()
+		}
+		utils.Infof("%s by %s", message, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: message,
+			Data:    approval,
+		})
+	case "block":
+		approval, err := s.monitor.BlockDevice(req.MAC, req.Mode, r.RemoteAddr, req.Note)
+		if err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		message := "Device " + approval.MAC + " blocked" + s.staticPendingNote()
+		utils.Infof("%s by %s", message, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: message,
+			Data:    approval,
+		})
+	case "forget":
+		if err := s.monitor.ForgetDevice(req.MAC); err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		utils.Infof("Approval of %s removed by %s", req.MAC, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: "Approval removed",
+		})
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+	}
+}
+
+// approvalReservation builds the static entry to create when approving a
+// device. The MAC defaults to the device's and the hostname to the one it
+// reported. An empty IP keeps the device's current address; "auto" takes
+// the next free address outside the dynamic pools.
+func (s *Server) approvalReservation(req ApprovalRequest) (models.StaticDHCPEntry, error) {
+	j := *req.Reservation
+	j.MAC = req.MAC
+	j.Enabled = true
+
+	approval, _ := s.monitor.GetApproval(req.MAC)
+	if j.Hostname == "" {
+		j.Hostname = approval.Hostname
+	}
+
+	switch {
+	case strings.EqualFold(j.IP, "auto"):
+		ip, _, err := s.monitor.SuggestStaticIP(ipam.Hint{Network: req.Subnet, Tag: j.Tag})
+		if err != nil {
+			return models.StaticDHCPEntry{}, err
+		}
+		j.IP = ip.String()
+	case j.IP == "":
+		j.IP = approval.IP
+		for _, lease := range s.monitor.GetDHCPLeases() {
+			if !lease.Static && lease.MAC != nil && strings.EqualFold(lease.MAC.String(), req.MAC) && lease.IP != nil {
+				j.IP = lease.IP.String()
+				break
+			}
+		}
+		if j.IP == "" {
+			return models.StaticDHCPEntry{}, fmt.Errorf("no current address for %s; give an IP or \"auto\"", req.MAC)
+		}
+	}
+
+	entry, err := j.ToStaticDHCPEntry()
+	if err != nil {
+		return entry, fmt.Errorf("invalid reservation: %w", err)
+	}
+	return entry, nil
+}
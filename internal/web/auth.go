@@ -0,0 +1,48 @@
+// ===== internal/web/auth.go =====
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"dhcpmon/pkg/utils"
+)
+
+// tokenKey marks a request that presented the configured API token
+type tokenKey struct{}
+
+// authenticate checks the bearer token of requests that send one. A
+// wrong token is rejected; the right one lets the request make changes
+// even when editing is disabled. Requests without a token are served as
+// before, so the web interface needs no token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if s.cfg.APIToken == "" || header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.cfg.APIToken)) != 1 {
+			utils.Warnf("Warning: invalid API token from %s", r.RemoteAddr)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dhcpmon"`)
+			s.writeErrorResponse(w, "Invalid API token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, true)))
+	})
+}
+
+// canEdit reports whether a request may make changes: editing is enabled,
+// or the request presented the API token
+func (s *Server) canEdit(r *http.Request) bool {
+	if s.cfg.Edit {
+		return true
+	}
+	authorized, _ := r.Context().Value(tokenKey{}).(bool)
+	return authorized
+}
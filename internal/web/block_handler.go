@@ -0,0 +1,81 @@
+// ===== internal/web/block_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dhcpmon/pkg/utils"
+)
+
+// BlockRequest represents a block or unblock request
+type BlockRequest struct {
+	Action string `json:"action"` // block or unblock
+	MAC    string `json:"mac"`
+	Mode   string `json:"mode,omitempty"` // ignore (default) or quarantine
+	Note   string `json:"note,omitempty"`
+}
+
+// handleBlockAPI lists blocked devices (GET) or blocks and unblocks a
+// device by MAC (POST)
+func (s *Server) handleBlockAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Data:    s.monitor.GetBlockedDevices(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.requireEdit(w, r) {
+		return
+	}
+
+	var req BlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Action {
+	case "block":
+		approval, err := s.monitor.BlockDevice(req.MAC, req.Mode, r.RemoteAddr, req.Note)
+		if err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		message := "Device " + approval.MAC + " blocked"
+		if req.Mode == "quarantine" {
+			message = "Device " + approval.MAC + " quarantined"
+		}
+		message += s.staticPendingNote()
+		utils.Infof("%s by %s", message, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: message,
+			Data:    approval,
+		})
+	case "unblock":
+		approval, err := s.monitor.UnblockDevice(req.MAC, r.RemoteAddr, req.Note)
+		if err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		message := "Device " + approval.MAC + " unblocked" + s.staticPendingNote()
+		utils.Infof("%s by %s", message, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: message,
+			Data:    approval,
+		})
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,338 @@
+// ===== internal/web/bulk_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"dhcpmon/internal/static"
+	"dhcpmon/pkg/utils"
+)
+
+// maxStaticUpload bounds the size of an imported reservation file
+const maxStaticUpload = 8 << 20
+
+// handleStaticExportAPI downloads the static reservations
+// (GET ?format=csv|json|yaml, JSON by default)
+func (s *Server) handleStaticExportAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := static.FormatJSON
+	if f := r.URL.Query().Get("format"); f != "" {
+		var err error
+		if format, err = static.ParseFormat(f); err != nil {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	contentTypes := map[string]string{
+		static.FormatCSV:  "text/csv; charset=utf-8",
+		static.FormatJSON: "application/json; charset=utf-8",
+		static.FormatYAML: "application/yaml; charset=utf-8",
+	}
+	filename := fmt.Sprintf("static-%s.%s", time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if err := static.Export(w, format, s.monitor.GetStaticEntries()); err != nil {
+		utils.Errorf("Static export failed: %v", err)
+	}
+}
+
+// handleStaticImportAPI imports static reservations from a CSV, JSON or
+// YAML file. The file is either a multipart upload with "file", "format",
+// "mode" and "dryrun" fields, or the request body with those as query
+// parameters. The format defaults to the file name's extension. A dry run
+// returns the planned changes and row errors and is always allowed.
+func (s *Server) handleStaticImportAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxStaticUpload)
+	var body io.Reader = r.Body
+	filename := ""
+	param := r.URL.Query().Get // The body is the file, so it is not parsed as a form
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxStaticUpload); err != nil {
+			s.writeErrorResponse(w, "Invalid upload: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			s.writeErrorResponse(w, "Missing file field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+		filename = header.Filename
+		param = r.FormValue
+	}
+
+	dryRun := false
+	switch strings.ToLower(param("dryrun")) {
+	case "", "0", "false", "no":
+	default:
+		dryRun = true
+	}
+	if !dryRun && !s.requireEdit(w, r) {
+		return
+	}
+
+	mode := param("mode")
+	if mode == "" {
+		mode = static.ImportMerge
+	}
+
+	format := static.FormatOf(filename)
+	if f := param("format"); f != "" {
+		var err error
+		if format, err = static.ParseFormat(f); err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if format == "" {
+		contentType := r.Header.Get("Content-Type")
+		switch {
+		case strings.Contains(contentType, "csv"):
+			format = static.FormatCSV
+		case strings.Contains(contentType, "yaml"):
+			format = static.FormatYAML
+		default:
+			format = static.FormatJSON
+		}
+	}
+
+	rows, err := static.Import(body, format)
+	if err != nil {
+		s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.monitor.ImportStatic(rows, mode, dryRun)
+	if err != nil {
+		utils.Errorf("Static import failed: %v", err)
+		s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		errs := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			errs[i] = fmt.Sprintf("row %d: %s", e.Row, e.Error)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: false,
+			Message: fmt.Sprintf("%d of %d rows cannot be imported; nothing was changed", result.FailedRows(), result.Rows),
+			Data:    result,
+			Errors:  errs,
+		})
+		return
+	}
+
+	message := "Dry run: " + result.Summary()
+	switch {
+	case result.Applied:
+		message = "Imported: " + result.Summary()
+		message += s.staticPendingNote()
+		utils.Infof("Static reservations imported (%s) by %s", mode, r.RemoteAddr)
+	case !result.HasChanges():
+		message = "Nothing to import: " + result.Summary()
+	}
+	json.NewEncoder(w).Encode(StaticDHCPResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// StaticBatchRequest is a list of static entry changes applied together
+type StaticBatchRequest struct {
+	Operations []StaticBatchOperation `json:"operations"`
+	DryRun     bool                   `json:"dryRun,omitempty"`
+}
+
+// StaticBatchOperation is one change of a batch. The entry is selected by
+// id, or by mac when there is no id.
+type StaticBatchOperation struct {
+	Action string              `json:"action"` // add, update, delete, enable or disable
+	ID     string              `json:"id,omitempty"`
+	MAC    string              `json:"mac,omitempty"`
+	Entry  StaticDHCPEntryJSON `json:"entry,omitempty"`
+}
+
+// handleStaticBatchAPI applies a list of add, update, delete, enable and
+// disable operations as one change (POST). The end state is validated as
+// a whole, so entries may swap addresses; if any operation fails nothing
+// is saved. A dry run reports the result without applying it and is
+// always allowed.
+func (s *Server) handleStaticBatchAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req StaticBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+	if !req.DryRun && !s.requireEdit(w, r) {
+		return
+	}
+	if len(req.Operations) == 0 {
+		s.writeErrorResponse(w, "No operations", http.StatusBadRequest)
+		return
+	}
+
+	ops := make([]static.BatchOp, len(req.Operations))
+	var errs []string
+	for i, op := range req.Operations {
+		ops[i] = static.BatchOp{Action: op.Action, ID: op.ID}
+		if op.MAC != "" {
+			mac, err := net.ParseMAC(op.MAC)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("operation %d: invalid MAC address: %v", i+1, err))
+				continue
+			}
+			ops[i].MAC = mac
+		}
+		if op.Action == static.BatchAdd || op.Action == static.BatchUpdate {
+			entry, err := op.Entry.ToStaticDHCPEntry()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("operation %d: invalid entry data: %v", i+1, err))
+				continue
+			}
+			ops[i].Entry = entry
+		}
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: false,
+			Message: "Invalid operations; nothing was changed",
+			Errors:  errs,
+		})
+		return
+	}
+
+	result, err := s.monitor.BatchStatic(ops, req.DryRun)
+	if err != nil {
+		utils.Errorf("Static batch failed: %v", err)
+		s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		for _, e := range result.Errors {
+			errs = append(errs, fmt.Sprintf("operation %d (%s): %s", e.Op, e.Action, e.Error))
+		}
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: false,
+			Message: "Batch rejected; nothing was changed",
+			Data:    result,
+			Errors:  errs,
+		})
+		return
+	}
+
+	message := fmt.Sprintf("%d operations would succeed", len(ops))
+	if result.Applied {
+		message = fmt.Sprintf("%d operations applied", len(ops))
+		message += s.staticPendingNote()
+		utils.Infof("Static batch of %d operations applied by %s", len(ops), r.RemoteAddr)
+	}
+	json.NewEncoder(w).Encode(StaticDHCPResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// StaticRenumberRequest is the body of a renumbering: the old and new
+// networks, explicit old to new address mappings, or both
+type StaticRenumberRequest struct {
+	From    string            `json:"from"`
+	To      string            `json:"to"`
+	Map     map[string]string `json:"map"`
+	NoHosts bool              `json:"noHosts"` // Leave the hosts file alone
+	DryRun  bool              `json:"dryRun"`
+}
+
+// handleStaticRenumberAPI moves static reservations, and matching hosts
+// entries, from one network to another
+func (s *Server) handleStaticRenumberAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req StaticRenumberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+	if !req.DryRun && !s.requireEdit(w, r) {
+		return
+	}
+
+	plan, err := static.ParseRenumberPlan(req.From, req.To, req.Map)
+	if err != nil {
+		s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.monitor.RenumberStatic(plan, !req.NoHosts, req.DryRun)
+	if err != nil {
+		utils.Errorf("Static renumbering failed: %v", err)
+		s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: false,
+			Message: "Renumbering rejected; nothing was changed",
+			Data:    result,
+			Errors:  result.Errors,
+		})
+		return
+	}
+
+	message := fmt.Sprintf("%d reservations and %d host entries would move", len(result.Static), len(result.Hosts))
+	if result.Applied {
+		message = fmt.Sprintf("%d reservations and %d host entries moved", len(result.Static), len(result.Hosts))
+		message += s.staticPendingNote()
+		utils.Infof("Renumbered %d static reservations by %s", len(result.Static), r.RemoteAddr)
+	} else if !result.DryRun {
+		message = "No reservations or host entries to move"
+	}
+	json.NewEncoder(w).Encode(StaticDHCPResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
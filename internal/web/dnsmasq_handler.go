@@ -0,0 +1,86 @@
+// ===== internal/web/dnsmasq_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dhcpmon/pkg/utils"
+)
+
+// DNSMasqRequest represents a dnsmasq service control request
+type DNSMasqRequest struct {
+	Action string `json:"action"`
+}
+
+// handleDNSMasqAPI reports the dnsmasq service status (GET) or performs
+// a start/stop/restart/reload action (POST)
+func (s *Server) handleDNSMasqAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Data:    s.monitor.DNSMasqStatus(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.requireEdit(w, r) {
+		return
+	}
+
+	var req DNSMasqRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch req.Action {
+	case "status":
+	case "start":
+		err = s.monitor.StartDNSMasq()
+	case "stop":
+		err = s.monitor.StopDNSMasq()
+	case "restart":
+		err = s.monitor.RestartDNSMasq()
+	case "reload":
+		err = s.monitor.ReloadDNSMasq()
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		utils.Errorf("dnsmasq %s failed: %v", req.Action, err)
+		s.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Action != "status" {
+		utils.Infof("dnsmasq %s requested by %s", req.Action, r.RemoteAddr)
+	}
+
+	json.NewEncoder(w).Encode(StaticDHCPResponse{
+		Success: true,
+		Message: "dnsmasq " + req.Action + " completed",
+		Data:    s.monitor.DNSMasqStatus(),
+	})
+}
+
+// requireEdit rejects the request when editing is disabled in the
+// configuration and the request has no API token, and reports whether
+// the caller may proceed
+func (s *Server) requireEdit(w http.ResponseWriter, r *http.Request) bool {
+	if s.canEdit(r) {
+		return true
+	}
+	s.writeErrorResponse(w, "Editing is disabled", http.StatusForbidden)
+	return false
+}
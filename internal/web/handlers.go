@@ -13,13 +13,18 @@ package web
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 	
+	"dhcpmon/internal/devices"
+	"dhcpmon/internal/dnsmasq"
+	"dhcpmon/internal/logs"
 	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
 )
 
 // DHCPLeaseJSON represents a DHCP lease in JSON format
@@ -35,14 +40,28 @@ type DHCPLeaseJSON struct {
 	ID     string        `json:"id"`
 	Tag    string        `json:"tag"`
 	Static bool          `json:"static"`
+	Label  *models.Label `json:"label,omitempty"`
+}
+
+// DeviceJSON represents a device with its user label in JSON format
+type DeviceJSON struct {
+	devices.Device
+	Label    *models.Label `json:"label,omitempty"`
+	Approval string        `json:"approval,omitempty"` // pending, approved or blocked
+	Blocked  string        `json:"blocked,omitempty"`  // ignore or quarantine, as enforced by dnsmasq
 }
 
 // LogEntryJSON represents a log entry in JSON format
 type LogEntryJSON struct {
+	ID        uint64 `json:"id"`
 	Timestamp string `json:"when"`
 	UnixTime  int64  `json:"utime"`
 	Channel   string `json:"channel"`
+	Host      string `json:"host,omitempty"`
 	Message   string `json:"message"`
+	Event     string `json:"event,omitempty"`
+	MAC       string `json:"mac,omitempty"`
+	IP        string `json:"ip,omitempty"`
 }
 
 // EditRequest represents an edit request from the frontend
@@ -66,10 +85,10 @@ type EditResponse struct {
 // handleLeasesAPI handles DHCP leases API requests
 func (s *Server) handleLeasesAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	log.Printf("Handling leases API request")
+	utils.Debugf("Handling leases API request")
 	
 	leases := s.monitor.GetDHCPLeases()
-	log.Printf("Found %d DHCP leases", len(leases))
+	utils.Debugf("Found %d DHCP leases", len(leases))
 	
 	jsonLeases := make([]DHCPLeaseJSON, len(leases))
 	
@@ -96,24 +115,167 @@ func (s *Server) handleLeasesAPI(w http.ResponseWriter, r *http.Request) {
 			macStr = s.formatMACAddress(lease.MAC)
 		}
 		
+		// User labels override the registry vendor
+		info, label := s.monitor.ApplyLabel(macStr, lease.Info)
+		
 		jsonLeases[i] = DHCPLeaseJSON{
 			Expire: expireStr,
 			Remain: remainStr,
 			Delta:  lease.Remain,
 			MAC:    macStr,
-			Info:   lease.Info,
+			Info:   info,
 			IP:     ipStr,
 			IPSort: ipSort,
 			Name:   lease.Name,
 			ID:     lease.ID,
 			Tag:    lease.Tag,
 			Static: lease.Static,
+			Label:  label,
 		}
 	}
 	
 	response := map[string]interface{}{"data": jsonLeases}
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Failed to encode leases JSON: %v", err)
+		utils.Errorf("Failed to encode leases JSON: %v", err)
+		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
+	}
+}
+
+// handleDevicesAPI returns known devices, with the randomized MACs of
+// each device listed as aliases
+func (s *Server) handleDevicesAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	
+	devices := s.monitor.GetDevices()
+	blocked := make(map[string]string)
+	for _, d := range s.monitor.GetBlockedDevices() {
+		blocked[d.MAC] = d.Mode
+	}
+	
+	jsonDevices := make([]DeviceJSON, len(devices))
+	for i, device := range devices {
+		jsonDevices[i] = DeviceJSON{Device: device}
+		
+		// A label on any of the device's MACs applies to the device
+		for _, alias := range device.MACs {
+			info, label := s.monitor.ApplyLabel(alias.MAC, &models.OUIEntry{Company: device.Vendor})
+			if label != nil {
+				jsonDevices[i].Vendor = info.Company
+				jsonDevices[i].Label = label
+				break
+			}
+		}
+		
+		// A blocked or pending MAC marks the whole device
+		for _, alias := range device.MACs {
+			approval, ok := s.monitor.GetApproval(alias.MAC)
+			if !ok || jsonDevices[i].Approval == models.ApprovalBlocked {
+				continue
+			}
+			if approval.State != models.ApprovalApproved || jsonDevices[i].Approval == "" {
+				jsonDevices[i].Approval = approval.State
+			}
+		}
+		for _, alias := range device.MACs {
+			if mode, ok := blocked[strings.ToUpper(alias.MAC)]; ok {
+				jsonDevices[i].Blocked = mode
+				break
+			}
+		}
+	}
+	
+	response := map[string]interface{}{"data": jsonDevices}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		utils.Errorf("Failed to encode devices JSON: %v", err)
+		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
+	}
+}
+
+// handleConsistencyAPI reports conflicts between the static
+// reservations, the hosts file and the live leases
+func (s *Server) handleConsistencyAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	
+	response := map[string]interface{}{"data": s.monitor.CheckConsistency()}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		utils.Errorf("Failed to encode consistency JSON: %v", err)
+		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
+	}
+}
+
+// handleAlertsAPI returns the active pool alerts, the recent alert changes
+// and the thresholds raising them
+func (s *Server) handleAlertsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	
+	active, history := s.monitor.GetAlerts()
+	warn, crit := s.monitor.AlertThresholds()
+	response := map[string]interface{}{
+		"data":    active,
+		"history": history,
+		"thresholds": map[string]float64{
+			"warning":  warn,
+			"critical": crit,
+		},
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		utils.Errorf("Failed to encode alerts JSON: %v", err)
+		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
+	}
+}
+
+// handleSubnetsAPI returns the subnets and pools dnsmasq serves, as read
+// from its configuration
+func (s *Server) handleSubnetsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	
+	subnets, conf, err := s.monitor.GetSubnets()
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if subnets == nil {
+		subnets = []dnsmasq.Subnet{}
+	}
+	
+	response := map[string]interface{}{
+		"data":       subnets,
+		"interfaces": conf.Interfaces,
+		"domains":    conf.Domains,
+		"files":      conf.Files,
+		"warnings":   conf.Warnings,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		utils.Errorf("Failed to encode subnets JSON: %v", err)
+		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
+	}
+}
+
+// handleIPAMAPI returns the utilization of every subnet; grid=1 adds the
+// state of each address of small IPv4 subnets
+func (s *Server) handleIPAMAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	
+	grid, _ := strconv.ParseBool(r.URL.Query().Get("grid"))
+	usage, err := s.monitor.GetIPUsage(grid)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
+	if network := r.URL.Query().Get("subnet"); network != "" {
+		filtered := usage[:0]
+		for _, u := range usage {
+			if u.Network == network {
+				filtered = append(filtered, u)
+			}
+		}
+		usage = filtered
+	}
+	
+	response := map[string]interface{}{"data": usage}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		utils.Errorf("Failed to encode IPAM JSON: %v", err)
 		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
 	}
 }
@@ -121,68 +283,125 @@ func (s *Server) handleLeasesAPI(w http.ResponseWriter, r *http.Request) {
 // handleHostsAPI handles hosts file API requests
 func (s *Server) handleHostsAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	log.Printf("Handling hosts API request")
+	utils.Debugf("Handling hosts API request")
 	
 	hosts := s.monitor.GetHostEntries()
-	log.Printf("Found %d host entries", len(hosts))
+	utils.Debugf("Found %d host entries", len(hosts))
 	
 	response := map[string]interface{}{"data": hosts}
 	
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Failed to encode hosts JSON: %v", err)
+		utils.Errorf("Failed to encode hosts JSON: %v", err)
 		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
 	}
 }
 
-// handleLogsAPI handles logs API requests
+// handleLogsAPI handles logs API requests.
+//
+// Supported query parameters:
+//
+//	from, to   time range (RFC 3339 or Unix seconds)
+//	channel    log channel (stdout, stderr, syslog, journal transport)
+//	host       source host of entries received over syslog
+//	q          case-insensitive free-text search
+//	mac, ip    entries mentioning the address
+//	cursor     return entries newer than this ID (polling)
+//	before     return entries older than this ID (paging back)
+//	limit      page size
 func (s *Server) handleLogsAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	log.Printf("Handling logs API request")
-	
-	var logs interface{}
-	
-	if s.cfg.SystemD {
-		// Get logs from systemd journal
-		logEntries, sysErr := s.monitor.GetSystemdLogs()
-		if sysErr != nil {
-			log.Printf("Failed to get systemd logs: %v", sysErr)
-			logs = []LogEntryJSON{}
-		} else {
-			log.Printf("Found %d systemd log entries", len(logEntries))
-			jsonLogs := make([]LogEntryJSON, len(logEntries))
-			for i, entry := range logEntries {
-				jsonLogs[i] = LogEntryJSON{
-					Timestamp: entry.Timestamp.Format(time.RFC3339),
-					UnixTime:  entry.UnixTime,
-					Channel:   entry.Channel,
-					Message:   entry.Message,
-				}
-			}
-			logs = jsonLogs
-		}
-	} else {
-		// Get logs from local collection
-		logEntries := s.monitor.GetLogs()
-		log.Printf("Found %d local log entries", len(logEntries))
-		jsonLogs := make([]LogEntryJSON, len(logEntries))
-		for i, entry := range logEntries {
-			jsonLogs[i] = LogEntryJSON{
-				Timestamp: entry.Timestamp.Format(time.RFC3339),
-				UnixTime:  entry.UnixTime,
-				Channel:   entry.Channel,
-				Message:   entry.Message,
-			}
+	utils.Debugf("Handling logs API request")
+	
+	query, err := parseLogQuery(r)
+	if err != nil {
+		s.writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	// Both dnsmasq output and the systemd journal feed the same store
+	result, err := s.monitor.QueryLogs(query)
+	if err != nil {
+		utils.Errorf("Failed to query logs: %v", err)
+		s.writeJSONError(w, "Failed to query logs", http.StatusInternalServerError)
+		return
+	}
+	utils.Debugf("Found %d log entries", len(result.Entries))
+	
+	jsonLogs := make([]LogEntryJSON, len(result.Entries))
+	for i, entry := range result.Entries {
+		jsonLogs[i] = LogEntryJSON{
+			ID:        entry.ID,
+			Timestamp: entry.Timestamp.Format(time.RFC3339),
+			UnixTime:  entry.UnixTime,
+			Channel:   entry.Channel,
+			Host:      entry.Host,
+			Message:   entry.Message,
+			Event:     entry.Event,
+			MAC:       entry.MAC,
+			IP:        entry.IP,
 		}
-		logs = jsonLogs
 	}
 	
-	response := map[string]interface{}{"data": logs}
+	response := map[string]interface{}{
+		"data": jsonLogs,
+		"next": result.Next,
+		"prev": result.Prev,
+		"more": result.More,
+	}
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Failed to encode logs JSON: %v", err)
+		utils.Errorf("Failed to encode logs JSON: %v", err)
 		http.Error(w, `{"error":"Internal server error"}`, http.StatusInternalServerError)
 	}
 }
 
+// parseLogQuery builds a log query from request parameters
+func parseLogQuery(r *http.Request) (logs.Query, error) {
+	params := r.URL.Query()
+	q := logs.Query{
+		Channel: params.Get("channel"),
+		Host:    params.Get("host"),
+		Text:    params.Get("q"),
+		MAC:     params.Get("mac"),
+		IP:      params.Get("ip"),
+	}
+	
+	var err error
+	if q.From, err = parseTimeParam(params.Get("from")); err != nil {
+		return q, fmt.Errorf("invalid from: %w", err)
+	}
+	if q.To, err = parseTimeParam(params.Get("to")); err != nil {
+		return q, fmt.Errorf("invalid to: %w", err)
+	}
+	if v := params.Get("cursor"); v != "" {
+		if q.After, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return q, fmt.Errorf("invalid cursor: %s", v)
+		}
+	}
+	if v := params.Get("before"); v != "" {
+		if q.Before, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return q, fmt.Errorf("invalid before: %s", v)
+		}
+	}
+	if v := params.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return q, fmt.Errorf("invalid limit: %s", v)
+		}
+	}
+	
+	return q, nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or Unix seconds
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
 // handleRemoveAPI handles remove requests for DHCP entries
 func (s *Server) handleRemoveAPI(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -192,18 +411,23 @@ func (s *Server) handleRemoveAPI(w http.ResponseWriter, r *http.Request) {
 	
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	
+	if !s.canEdit(r) {
+		s.writeJSONError(w, "Editing is disabled", http.StatusForbidden)
+		return
+	}
+	
 	dataStr := r.FormValue("data")
 	if dataStr == "" {
 		s.writeJSONError(w, "Missing data parameter", http.StatusBadRequest)
 		return
 	}
 	
-	log.Printf("Remove request received: %s", dataStr)
+	utils.Debugf("Remove request received: %s", dataStr)
 	
 	// Parse the JSON data to understand what to remove
 	var removeData map[string]interface{}
 	if err := json.Unmarshal([]byte(dataStr), &removeData); err != nil {
-		log.Printf("Failed to parse remove data: %v", err)
+		utils.Errorf("Failed to parse remove data: %v", err)
 		s.writeJSONError(w, "Invalid JSON data", http.StatusBadRequest)
 		return
 	}
@@ -228,14 +452,14 @@ func (s *Server) handleRemoveAPI(w http.ResponseWriter, r *http.Request) {
 	
 	if entryToRemove != nil {
 		if err := s.monitor.DeleteStaticEntry(entryToRemove.ID); err != nil {
-			log.Printf("Failed to delete static entry: %v", err)
+			utils.Errorf("Failed to delete static entry: %v", err)
 			s.writeJSONError(w, "Failed to delete static entry: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 		
 		// Save the changes
 		if err := s.monitor.SaveStaticEntries(); err != nil {
-			log.Printf("Failed to save static entries: %v", err)
+			utils.Errorf("Failed to save static entries: %v", err)
 			s.writeJSONError(w, "Failed to save changes: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -270,18 +494,23 @@ func (s *Server) handleEditAPI(w http.ResponseWriter, r *http.Request) {
 	
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	
+	if !s.canEdit(r) {
+		s.writeJSONError(w, "Editing is disabled", http.StatusForbidden)
+		return
+	}
+	
 	dataStr := r.FormValue("data")
 	if dataStr == "" {
 		s.writeJSONError(w, "Missing data parameter", http.StatusBadRequest)
 		return
 	}
 	
-	log.Printf("Edit request received: %s", dataStr)
+	utils.Debugf("Edit request received: %s", dataStr)
 	
 	// Parse the JSON data
 	var editData EditRequest
 	if err := json.Unmarshal([]byte(dataStr), &editData); err != nil {
-		log.Printf("Failed to parse edit data: %v", err)
+		utils.Errorf("Failed to parse edit data: %v", err)
 		s.writeJSONError(w, "Invalid JSON data", http.StatusBadRequest)
 		return
 	}
@@ -338,7 +567,7 @@ func (s *Server) handleEditAPI(w http.ResponseWriter, r *http.Request) {
 		}
 		
 		if err := s.monitor.UpdateStaticEntry(existingEntry.ID, updatedEntry); err != nil {
-			log.Printf("Failed to update static entry: %v", err)
+			utils.Errorf("Failed to update static entry: %v", err)
 			s.writeJSONError(w, "Failed to update entry: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -354,7 +583,7 @@ func (s *Server) handleEditAPI(w http.ResponseWriter, r *http.Request) {
 		}
 		
 		if err := s.monitor.AddStaticEntry(newEntry); err != nil {
-			log.Printf("Failed to add static entry: %v", err)
+			utils.Errorf("Failed to add static entry: %v", err)
 			s.writeJSONError(w, "Failed to add entry: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -362,7 +591,7 @@ func (s *Server) handleEditAPI(w http.ResponseWriter, r *http.Request) {
 	
 	// Save the changes
 	if err := s.monitor.SaveStaticEntries(); err != nil {
-		log.Printf("Failed to save static entries: %v", err)
+		utils.Errorf("Failed to save static entries: %v", err)
 		s.writeJSONError(w, "Failed to save changes: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -384,7 +613,7 @@ func (s *Server) handleEditGetData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	log.Printf("Getting edit data for MAC: %s", macParam)
+	utils.Debugf("Getting edit data for MAC: %s", macParam)
 	
 	// First check static entries
 	staticEntries := s.monitor.GetStaticEntries()
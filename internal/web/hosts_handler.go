@@ -0,0 +1,133 @@
+// ===== internal/web/hosts_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// HostsRequest represents API requests for hosts file management
+type HostsRequest struct {
+	Action string           `json:"action"`
+	ID     string           `json:"id,omitempty"`
+	Entry  models.HostEntry `json:"entry,omitempty"`
+}
+
+// handleHostsEditAPI handles hosts file management API requests; it
+// mirrors the static DHCP API
+func (s *Server) handleHostsEditAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Data:    s.monitor.GetHostEntries(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req HostsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Action {
+	case "list":
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Data:    s.monitor.GetHostEntries(),
+		})
+		return
+	case "get":
+		if req.ID == "" {
+			s.writeErrorResponse(w, "ID is required", http.StatusBadRequest)
+			return
+		}
+		entry, err := s.monitor.GetHostEntryByID(req.ID)
+		if err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(StaticDHCPResponse{Success: true, Data: entry})
+		return
+	case "validate":
+		s.handleHostsValidate(w)
+		return
+	}
+
+	if !s.requireEdit(w, r) {
+		return
+	}
+
+	var err error
+	var message string
+	status := http.StatusBadRequest
+
+	switch req.Action {
+	case "add":
+		err = s.monitor.AddHostEntry(req.Entry)
+		message = "Host entry added successfully"
+	case "update":
+		if req.ID == "" {
+			s.writeErrorResponse(w, "ID is required", http.StatusBadRequest)
+			return
+		}
+		err = s.monitor.UpdateHostEntry(req.ID, req.Entry)
+		message = "Host entry updated successfully"
+	case "delete":
+		if req.ID == "" {
+			s.writeErrorResponse(w, "ID is required", http.StatusBadRequest)
+			return
+		}
+		err = s.monitor.DeleteHostEntry(req.ID)
+		message = "Host entry deleted successfully"
+		status = http.StatusNotFound
+	case "save":
+		err = s.monitor.SaveHostEntries()
+		message = "Hosts file saved successfully"
+		status = http.StatusInternalServerError
+	case "reload":
+		err = s.monitor.ReloadHostEntries()
+		message = "Hosts file reloaded successfully"
+		status = http.StatusInternalServerError
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		s.writeErrorResponse(w, err.Error(), status)
+		return
+	}
+
+	json.NewEncoder(w).Encode(StaticDHCPResponse{
+		Success: true,
+		Message: message,
+	})
+	utils.Infof("Hosts %s: ID=%s IP=%s Name=%s", req.Action, req.ID, req.Entry.IP, req.Entry.Name)
+}
+
+// handleHostsValidate reports problems with the current host entries
+func (s *Server) handleHostsValidate(w http.ResponseWriter) {
+	errors := s.monitor.ValidateHostEntries()
+
+	response := StaticDHCPResponse{
+		Success: len(errors) == 0,
+		Message: fmt.Sprintf("Validation completed with %d errors", len(errors)),
+	}
+	for _, err := range errors {
+		response.Errors = append(response.Errors, err.Error())
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
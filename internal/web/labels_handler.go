@@ -0,0 +1,76 @@
+// ===== internal/web/labels_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
+)
+
+// LabelRequest represents a device label request
+type LabelRequest struct {
+	Action string       `json:"action"` // set or delete
+	Key    string       `json:"key"`    // MAC address or prefix (delete)
+	Label  models.Label `json:"label"`  // Label to store (set)
+}
+
+// handleLabelsAPI lists device labels (GET) or sets and deletes them
+// (POST)
+func (s *Server) handleLabelsAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Data:    s.monitor.GetLabels(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.requireEdit(w, r) {
+		return
+	}
+
+	var req LabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Action {
+	case "set":
+		if req.Label.Key == "" {
+			req.Label.Key = req.Key
+		}
+		label, err := s.monitor.SetLabel(req.Label)
+		if err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		utils.Infof("Label for %s set by %s", label.Key, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: "Label saved for " + label.Key,
+			Data:    label,
+		})
+	case "delete":
+		if err := s.monitor.DeleteLabel(req.Key); err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		utils.Infof("Label for %s deleted by %s", req.Key, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: "Label deleted",
+		})
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,52 @@
+// ===== internal/web/lease_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dhcpmon/pkg/utils"
+)
+
+// LeaseRequest represents an action on a dynamic lease
+type LeaseRequest struct {
+	Action string `json:"action"` // revoke
+	IP     string `json:"ip"`
+}
+
+// handleLeaseActionAPI revokes dynamic leases (POST)
+func (s *Server) handleLeaseActionAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.requireEdit(w, r) {
+		return
+	}
+
+	var req LeaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Action {
+	case "revoke":
+		method, err := s.monitor.RevokeLease(req.IP)
+		if err != nil {
+			s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		utils.Infof("Lease of %s revoked with %s by %s", req.IP, method, r.RemoteAddr)
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: "Lease of " + req.IP + " revoked",
+			Data:    map[string]string{"ip": req.IP, "method": method},
+		})
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,122 @@
+// ===== internal/web/macdb_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"dhcpmon/pkg/utils"
+)
+
+// maxMACDBUpload bounds the size of an uploaded vendor file; the full
+// macaddress.io export is the largest supported source
+const maxMACDBUpload = 256 << 20
+
+// MACDBRequest represents a MAC vendor database request
+type MACDBRequest struct {
+	Action string `json:"action"`
+}
+
+// handleMACDBAPI reports the loaded vendor sources (GET), reloads them
+// ({"action": "reload"}) or imports an uploaded registry file (multipart
+// POST with a "file" field and an optional "target" source path)
+func (s *Server) handleMACDBAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Data:    s.monitor.MACDatabaseStats(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.requireEdit(w, r) {
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		s.handleMACDBImport(w, r)
+		return
+	}
+
+	var req MACDBRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Action {
+	case "status":
+	case "reload":
+		if err := s.monitor.ReloadMACDatabase(); err != nil {
+			utils.Errorf("MAC database reload failed: %v", err)
+			s.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		utils.Infof("MAC database reload requested by %s", r.RemoteAddr)
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(StaticDHCPResponse{
+		Success: true,
+		Message: "MAC database " + req.Action + " completed",
+		Data:    s.monitor.MACDatabaseStats(),
+	})
+}
+
+// handleMACDBImport installs an uploaded vendor file over one of the
+// configured sources. Without a target the upload replaces the source
+// with the same file name, so uploading oui.csv updates .../oui.csv.
+func (s *Server) handleMACDBImport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxMACDBUpload)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		s.writeErrorResponse(w, "Invalid upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		s.writeErrorResponse(w, "Missing file field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	target := r.FormValue("target")
+	if target == "" {
+		for _, path := range s.cfg.MACDBFiles {
+			if filepath.Base(path) == filepath.Base(header.Filename) {
+				target = path
+				break
+			}
+		}
+	}
+	if target == "" {
+		s.writeErrorResponse(w, "No configured MAC database named "+filepath.Base(header.Filename)+"; specify a target", http.StatusBadRequest)
+		return
+	}
+
+	status, err := s.monitor.ImportMACDatabase(file, target)
+	if err != nil {
+		utils.Errorf("MAC database import into %s failed: %v", target, err)
+		s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	utils.Infof("MAC database %s imported by %s", target, r.RemoteAddr)
+	json.NewEncoder(w).Encode(StaticDHCPResponse{
+		Success: true,
+		Message: "Imported " + status.Format + " vendor file into " + target,
+		Data:    s.monitor.MACDatabaseStats(),
+	})
+}
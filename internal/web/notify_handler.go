@@ -0,0 +1,56 @@
+// ===== internal/web/notify_handler.go =====
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"dhcpmon/pkg/utils"
+)
+
+// NotifyRequest represents a notification channel request
+type NotifyRequest struct {
+	Action  string `json:"action"`  // test
+	Channel string `json:"channel"` // Channel name
+}
+
+// handleNotifyAPI lists the notification channels (GET) or sends a test
+// message to one (POST)
+func (s *Server) handleNotifyAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Data:    s.monitor.NotifyStatus(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		s.writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req NotifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, "Invalid JSON request", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Action {
+	case "test":
+		utils.Infof("Test notification to %s requested by %s", req.Channel, r.RemoteAddr)
+		if err := s.monitor.TestNotify(req.Channel); err != nil {
+			s.writeErrorResponse(w, "Test notification failed: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(StaticDHCPResponse{
+			Success: true,
+			Message: "Test notification sent to " + req.Channel,
+			Data:    s.monitor.NotifyStatus(),
+		})
+	default:
+		s.writeErrorResponse(w, "Unknown action", http.StatusBadRequest)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"dhcpmon/internal/config"
 	"dhcpmon/internal/monitor"
 	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
 )
 
 // Server represents the HTTP server
@@ -36,6 +37,7 @@ type TemplateData struct {
     EnableSSHLinks    bool
     EnableNetworkTags bool
     EnableEdit        bool
+    QuarantineTag     string
 }
 
 // NewServer creates a new web server
@@ -56,24 +58,36 @@ func NewServer(cfg *config.Config, mon *monitor.Monitor) *Server {
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
-	return http.ListenAndServe(s.cfg.HTTPListen, s.mux)
+	return http.ListenAndServe(s.cfg.HTTPListen, s.authenticate(s.mux))
 }
 
 // setupRoutes configures HTTP routes
 func (s *Server) setupRoutes() {
 	s.mux.HandleFunc("/", s.handleRoot)
 	s.mux.HandleFunc("/api/static", s.handleStaticAPI)
+	s.mux.HandleFunc("/api/static/export", s.handleStaticExportAPI)
+	s.mux.HandleFunc("/api/static/import", s.handleStaticImportAPI)
+	s.mux.HandleFunc("/api/static/batch", s.handleStaticBatchAPI)
+	s.mux.HandleFunc("/api/static/renumber", s.handleStaticRenumberAPI)
 	s.mux.HandleFunc("/api/edit", s.handleEditAPI)
+	s.mux.HandleFunc("/api/dnsmasq", s.handleDNSMasqAPI)
+	s.mux.HandleFunc("/api/macdb", s.handleMACDBAPI)
+	s.mux.HandleFunc("/api/labels", s.handleLabelsAPI)
+	s.mux.HandleFunc("/api/notify", s.handleNotifyAPI)
+	s.mux.HandleFunc("/api/approvals", s.handleApprovalsAPI)
+	s.mux.HandleFunc("/api/block", s.handleBlockAPI)
+	s.mux.HandleFunc("/api/lease", s.handleLeaseActionAPI)
+	s.mux.HandleFunc("/api/hosts", s.handleHostsEditAPI)
 }
 
 // handleRoot handles the main page requests
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
-	log.Printf("Request from %s: %s", r.RemoteAddr, r.URL.String())
+	utils.Debugf("Request from %s: %s", r.RemoteAddr, r.URL.String())
 	
 	// Handle API requests
 	if apiType, exists := query["api"]; exists && len(apiType) > 0 {
-		log.Printf("API request: %s", apiType[0])
+		utils.Debugf("API request: %s", apiType[0])
 		
 		// Route API calls to appropriate handlers
 		switch apiType[0] {
@@ -83,6 +97,16 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 			s.handleLeasesAPI(w, r)
 		case "hosts.json":
 			s.handleHostsAPI(w, r)
+		case "ipam.json":
+			s.handleIPAMAPI(w, r)
+		case "subnets.json":
+			s.handleSubnetsAPI(w, r)
+		case "consistency.json":
+			s.handleConsistencyAPI(w, r)
+		case "alerts.json":
+			s.handleAlertsAPI(w, r)
+		case "devices.json":
+			s.handleDevicesAPI(w, r)
 		case "logs.json":
 			s.handleLogsAPI(w, r)
 		case "remove":
@@ -95,6 +119,14 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 			s.handleVersionAPI(w, r)
 		case "dhcp-status":
 			s.handleDHCPStatusAPI(w, r)
+		case "dnsmasq":
+			s.handleDNSMasqAPI(w, r)
+		case "macdb":
+			s.handleMACDBAPI(w, r)
+		case "labels":
+			s.handleLabelsAPI(w, r)
+		case "hosts":
+			s.handleHostsEditAPI(w, r)
 		case "file-status":
 			s.handleFileStatusAPI(w, r)
 		case "process-info":
@@ -102,7 +134,7 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 		case "recent-events":
 			s.handleRecentEventsAPI(w, r)
 		default:
-			log.Printf("Unknown API type: %s", apiType[0])
+			utils.Infof("Unknown API type: %s", apiType[0])
 			http.Error(w, `{"error":"Unknown API endpoint"}`, http.StatusNotFound)
 		}
 		return
@@ -112,7 +144,7 @@ func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 	pageType := "Leases"
 	if p, exists := query["p"]; exists && len(p) > 0 {
 		pageType = p[0]
-		log.Printf("Page request: %s", pageType)
+		utils.Debugf("Page request: %s", pageType)
 	}
 	
 	s.handlePage(w, r, pageType)
@@ -127,18 +159,18 @@ func (s *Server) loadTemplates() {
 		
 		// Check if file exists
 		if _, err := os.Stat(path); os.IsNotExist(err) {
-			log.Printf("Warning: template file %s not found, skipping %s template", filename, name)
+			utils.Warnf("Warning: template file %s not found, skipping %s template", filename, name)
 			continue
 		}
 		
 		tmpl, err := template.ParseFiles(path)
 		if err != nil {
-			log.Printf("Warning: failed to load template %s (%s): %v", name, filename, err)
+			utils.Warnf("Warning: failed to load template %s (%s): %v", name, filename, err)
 			continue
 		}
 		
 		s.templates[name] = tmpl
-		log.Printf("Loaded template: %s -> %s", name, filename)
+		utils.Infof("Loaded template: %s -> %s", name, filename)
 	}
 	
 	// Validate that we have the bootstrap template
@@ -155,6 +187,7 @@ func (s *Server) handlePage(w http.ResponseWriter, r *http.Request, pageType str
 		EnableSSHLinks:    s.cfg.SSHLinks,
 		EnableNetworkTags: s.cfg.NetworkTags,
 		EnableEdit:        s.cfg.Edit,
+		QuarantineTag:     s.cfg.QuarantineTag,
 	}
 	
 	var content string
@@ -171,6 +204,12 @@ func (s *Server) handlePage(w http.ResponseWriter, r *http.Request, pageType str
 	case "Hosts":
 		data.PageTitle = "DHCPmon - Hosts"
 		templateName = "hosts"
+	case "Devices":
+		data.PageTitle = "DHCPmon - Devices"
+		templateName = "devices"
+	case "IPAM":
+		data.PageTitle = "DHCPmon - IPAM"
+		templateName = "ipam"
 	case "System":
 		data.PageTitle = "DHCPmon - System"
 		templateName = "system"
@@ -204,7 +243,7 @@ func (s *Server) handlePage(w http.ResponseWriter, r *http.Request, pageType str
 	// Render main template
 	if tmpl, exists := s.templates["bootstrap"]; exists {
 		if err := tmpl.Execute(w, data); err != nil {
-			log.Printf("Failed to execute bootstrap template: %v", err)
+			utils.Errorf("Failed to execute bootstrap template: %v", err)
 			http.Error(w, "Template execution failed", http.StatusInternalServerError)
 		}
 	} else {
@@ -247,7 +286,7 @@ func (s *Server) handleSystemAPI(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := s.writeJSONResponse(w, response); err != nil {
-		log.Printf("Failed to encode system JSON: %v", err)
+		utils.Errorf("Failed to encode system JSON: %v", err)
 	}
 }
 
@@ -264,7 +303,7 @@ func (s *Server) handleVersionAPI(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := s.writeJSONResponse(w, response); err != nil {
-		log.Printf("Failed to encode version JSON: %v", err)
+		utils.Errorf("Failed to encode version JSON: %v", err)
 	}
 }
 
@@ -272,19 +311,21 @@ func (s *Server) handleVersionAPI(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleDHCPStatusAPI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	
-	// Check if DHCP service is running (simplified check)
-	running := true
-	if _, err := os.Stat(s.cfg.LeasesFile); os.IsNotExist(err) {
-		running = false
-	}
+	status := s.monitor.DNSMasqStatus()
 	
 	response := map[string]interface{}{
-		"running": running,
-		"service": "dnsmasq", // Could be made configurable
+		"running":  status.Running,
+		"service":  status.Service,
+		"mode":     status.Mode,
+		"state":    status.State,
+		"pid":      status.PID,
+		"uptime":   status.Uptime,
+		"restarts": status.Restarts,
+		"history":  status.History,
 	}
 	
 	if err := s.writeJSONResponse(w, response); err != nil {
-		log.Printf("Failed to encode DHCP status JSON: %v", err)
+		utils.Errorf("Failed to encode DHCP status JSON: %v", err)
 	}
 }
 
@@ -299,7 +340,13 @@ func (s *Server) handleFileStatusAPI(w http.ResponseWriter, r *http.Request) {
 		"Leases File": s.cfg.LeasesFile,
 		"Hosts File":  s.cfg.HostsFile,
 		"Static File": s.cfg.StaticFile,
-		"MAC DB":      s.cfg.MACDBFile,
+	}
+	for i, path := range s.cfg.MACDBFiles {
+		name := "MAC DB"
+		if i > 0 {
+			name = fmt.Sprintf("MAC DB %d", i+1)
+		}
+		filesToCheck[name] = path
 	}
 	
 	for name, path := range filesToCheck {
@@ -323,18 +370,31 @@ func (s *Server) handleFileStatusAPI(w http.ResponseWriter, r *http.Request) {
 		files = append(files, fileInfo)
 	}
 	
+	configModified := s.startTime
+	if stat, err := os.Stat(s.cfg.File); err == nil {
+		configModified = stat.ModTime()
+	}
+	sources := make(map[string]config.Source, len(s.cfg.Sources))
+	for key, source := range s.cfg.Sources {
+		if key == "apitoken" {
+			source.Value = "********"
+		}
+		sources[key] = source
+	}
+	
 	response := map[string]interface{}{
 		"files": files,
 		"config": map[string]interface{}{
-			"configFile":     "dhcpmon.ini",
-			"configModified": s.startTime, // Simplified
+			"configFile":     s.cfg.File,
+			"configModified": configModified,
+			"sources":        sources,
 			"leasesFile":     s.cfg.LeasesFile,
 			"staticFile":     s.cfg.StaticFile,
 		},
 	}
 	
 	if err := s.writeJSONResponse(w, response); err != nil {
-		log.Printf("Failed to encode file status JSON: %v", err)
+		utils.Errorf("Failed to encode file status JSON: %v", err)
 	}
 }
 
@@ -353,7 +413,7 @@ func (s *Server) handleProcessInfoAPI(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := s.writeJSONResponse(w, response); err != nil {
-		log.Printf("Failed to encode process info JSON: %v", err)
+		utils.Errorf("Failed to encode process info JSON: %v", err)
 	}
 }
 
@@ -385,7 +445,7 @@ func (s *Server) handleRecentEventsAPI(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := s.writeJSONResponse(w, response); err != nil {
-		log.Printf("Failed to encode recent events JSON: %v", err)
+		utils.Errorf("Failed to encode recent events JSON: %v", err)
 	}
 }
 
@@ -433,7 +493,7 @@ func (s *Server) handleRemove(w http.ResponseWriter, r *http.Request) {
 	
 	// Parse the data parameter
 	dataStr := r.FormValue("data")
-	log.Printf("Remove request data: %s", dataStr)
+	utils.Debugf("Remove request data: %s", dataStr)
 	
 	// For now, just log the request
 	// TODO: Implement actual removal logic
@@ -444,7 +504,7 @@ func (s *Server) handleRemove(w http.ResponseWriter, r *http.Request) {
 
 // ReloadTemplates reloads all templates (useful for development)
 func (s *Server) ReloadTemplates() {
-	log.Println("Reloading templates...")
+	utils.Infof("Reloading templates...")
 	s.loadTemplates()
 }
 
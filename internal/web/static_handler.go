@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
-	"log"
 	"net/http"
 	"strings"
 	
+	"dhcpmon/internal/ipam"
+	"dhcpmon/internal/monitor"
 	"dhcpmon/pkg/models"
+	"dhcpmon/pkg/utils"
 )
 
 // StaticDHCPRequest represents API requests for static DHCP management
@@ -18,6 +20,8 @@ type StaticDHCPRequest struct {
     ID     string                     `json:"id,omitempty"`
     Entry  StaticDHCPEntryJSON        `json:"entry,omitempty"`  // Changed to JSON type
     Filter map[string]string          `json:"filter,omitempty"`
+    Subnet string                     `json:"subnet,omitempty"` // CIDR to suggest an address in
+    Move   bool                       `json:"move,omitempty"`   // Pin to a free address outside the dynamic pools
 }
 
 // StaticDHCPResponse represents API responses for static DHCP management
@@ -38,6 +42,7 @@ type StaticDHCPEntryJSON struct {
     LeaseTime  string `json:"leaseTime,omitempty"`
     Comment    string `json:"comment,omitempty"`
     Enabled    bool   `json:"enabled"`
+    Ignore     bool   `json:"ignore,omitempty"`
     LineNumber int    `json:"lineNumber,omitempty"`
 }
 
@@ -61,6 +66,15 @@ func (s *Server) handleStaticAPI(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Read-only actions are always allowed; changes need edit=true
+	switch req.Action {
+	case "list", "get", "validate", "suggest":
+	default:
+		if !s.requireEdit(w, r) {
+			return
+		}
+	}
+	
 	switch req.Action {
 	case "list":
 		s.handleStaticList(w, r, req)
@@ -78,6 +92,10 @@ func (s *Server) handleStaticAPI(w http.ResponseWriter, r *http.Request) {
 		s.handleStaticDisable(w, r, req)
 	case "validate":
 		s.handleStaticValidate(w, r, req)
+	case "suggest":
+		s.handleStaticSuggest(w, r, req)
+	case "pin":
+		s.handleStaticPin(w, r, req)
 	case "save":
 		s.handleStaticSave(w, r, req)
 	case "reload":
@@ -104,8 +122,69 @@ func (s *Server) handleStaticList(w http.ResponseWriter, r *http.Request, req St
 	json.NewEncoder(w).Encode(response)
 }
 
-// handleStaticAdd handles add entry requests
+// handleStaticSuggest returns the next free address outside the dynamic
+// pools, in the subnet given by the request or the entry's tag
+func (s *Server) handleStaticSuggest(w http.ResponseWriter, r *http.Request, req StaticDHCPRequest) {
+	ip, subnet, err := s.monitor.SuggestStaticIP(ipam.Hint{Network: req.Subnet, Tag: req.Entry.Tag})
+	if err != nil {
+		s.writeErrorResponse(w, err.Error(), http.StatusConflict)
+		return
+	}
+	
+	response := StaticDHCPResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s is free in %s", ip, subnet),
+		Data:    map[string]string{"ip": ip.String(), "subnet": subnet},
+	}
+	
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleStaticPin turns the dynamic lease of entry.mac into a static
+// reservation, saved and applied right away
+func (s *Server) handleStaticPin(w http.ResponseWriter, r *http.Request, req StaticDHCPRequest) {
+	if req.Entry.MAC == "" {
+		s.writeErrorResponse(w, "MAC address is required", http.StatusBadRequest)
+		return
+	}
+	
+	entry, err := s.monitor.PinLease(monitor.PinRequest{
+		MAC:      req.Entry.MAC,
+		Hostname: req.Entry.Hostname,
+		Comment:  req.Entry.Comment,
+		Move:     req.Move,
+	})
+	if err != nil {
+		s.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	message := fmt.Sprintf("%s reserved at %s", entry.GetFormattedMAC(), entry.IP)
+	if req.Move {
+		message += "; the device moves there when it renews its lease"
+	}
+	message += s.staticPendingNote()
+	response := StaticDHCPResponse{
+		Success: true,
+		Message: message,
+		Data:    entry,
+	}
+	
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleStaticAdd handles add entry requests. An IP of "auto" takes the
+// next free address outside the dynamic pools.
 func (s *Server) handleStaticAdd(w http.ResponseWriter, r *http.Request, req StaticDHCPRequest) {
+    if strings.EqualFold(req.Entry.IP, "auto") {
+        ip, _, err := s.monitor.SuggestStaticIP(ipam.Hint{Network: req.Subnet, Tag: req.Entry.Tag})
+        if err != nil {
+            s.writeErrorResponse(w, err.Error(), http.StatusConflict)
+            return
+        }
+        req.Entry.IP = ip.String()
+    }
+    
     // Convert JSON entry to internal model
     entry, err := req.Entry.ToStaticDHCPEntry()
     if err != nil {
@@ -121,6 +200,7 @@ func (s *Server) handleStaticAdd(w http.ResponseWriter, r *http.Request, req Sta
     response := StaticDHCPResponse{
         Success: true,
         Message: "Static DHCP entry added successfully",
+        Data:    FromStaticDHCPEntry(entry),
     }
     
     json.NewEncoder(w).Encode(response)
@@ -134,7 +214,7 @@ func (s *Server) handleStaticAdd(w http.ResponseWriter, r *http.Request, req Sta
         ipStr = entry.IP.String()
     }
     
-    log.Printf("Added static DHCP entry: MAC=%s, IP=%s, Hostname=%s", 
+    utils.Infof("Added static DHCP entry: MAC=%s, IP=%s, Hostname=%s", 
         macStr, ipStr, entry.Hostname)
 }
 
@@ -162,7 +242,7 @@ func (s *Server) handleStaticUpdate(w http.ResponseWriter, r *http.Request, req
     }
     
     json.NewEncoder(w).Encode(response)
-    log.Printf("Updated static DHCP entry: ID=%s", req.ID)
+    utils.Infof("Updated static DHCP entry: ID=%s", req.ID)
 }
 
 // Updated handleStaticGetOne to return JSON-friendly format
@@ -225,7 +305,7 @@ func (s *Server) handleStaticDelete(w http.ResponseWriter, r *http.Request, req
 	}
 	
 	json.NewEncoder(w).Encode(response)
-	log.Printf("Deleted static DHCP entry: ID=%s", req.ID)
+	utils.Infof("Deleted static DHCP entry: ID=%s", req.ID)
 }
 
 // handleStaticEnable handles enable entry requests
@@ -246,7 +326,7 @@ func (s *Server) handleStaticEnable(w http.ResponseWriter, r *http.Request, req
 	}
 	
 	json.NewEncoder(w).Encode(response)
-	log.Printf("Enabled static DHCP entry: ID=%s", req.ID)
+	utils.Infof("Enabled static DHCP entry: ID=%s", req.ID)
 }
 
 // handleStaticDisable handles disable entry requests
@@ -267,7 +347,7 @@ func (s *Server) handleStaticDisable(w http.ResponseWriter, r *http.Request, req
 	}
 	
 	json.NewEncoder(w).Encode(response)
-	log.Printf("Disabled static DHCP entry: ID=%s", req.ID)
+	utils.Infof("Disabled static DHCP entry: ID=%s", req.ID)
 }
 
 // handleStaticValidate handles validate configuration requests
@@ -303,7 +383,7 @@ func (s *Server) handleStaticSave(w http.ResponseWriter, r *http.Request, req St
 	}
 	
 	json.NewEncoder(w).Encode(response)
-	log.Printf("Saved static DHCP configuration to file")
+	utils.Infof("Saved static DHCP configuration to file")
 }
 
 // handleStaticReload handles reload configuration requests
@@ -319,7 +399,7 @@ func (s *Server) handleStaticReload(w http.ResponseWriter, r *http.Request, req
 	}
 	
 	json.NewEncoder(w).Encode(response)
-	log.Printf("Reloaded static DHCP configuration from file")
+	utils.Infof("Reloaded static DHCP configuration from file")
 }
 
 // filterStaticEntries applies filters to static entries
@@ -384,6 +464,7 @@ func (j *StaticDHCPEntryJSON) ToStaticDHCPEntry() (models.StaticDHCPEntry, error
         LeaseTime:  j.LeaseTime,
         Comment:    j.Comment,
         Enabled:    j.Enabled,
+        Ignore:     j.Ignore,
         LineNumber: j.LineNumber,
     }
 
@@ -417,6 +498,7 @@ func FromStaticDHCPEntry(entry models.StaticDHCPEntry) StaticDHCPEntryJSON {
         LeaseTime:  entry.LeaseTime,
         Comment:    entry.Comment,
         Enabled:    entry.Enabled,
+        Ignore:     entry.Ignore,
         LineNumber: entry.LineNumber,
     }
 
@@ -430,3 +512,12 @@ func FromStaticDHCPEntry(entry models.StaticDHCPEntry) StaticDHCPEntryJSON {
 
     return j
 }
+
+// staticPendingNote tells that a saved static change has not reached
+// dnsmasq, because dnsmasq is not running to be restarted with it
+func (s *Server) staticPendingNote() string {
+	if s.monitor.DNSMasqStatus().Running {
+		return ""
+	}
+	return "; dnsmasq is not running, so the change applies when it starts"
+}
@@ -4,9 +4,10 @@ package web
 import (
 	"fmt"
 	"html/template"
-	"log"
 	"path/filepath"
 	"strings"
+
+	"dhcpmon/pkg/utils"
 )
 
 // TemplateManager handles template loading and rendering
@@ -38,12 +39,12 @@ func (tm *TemplateManager) LoadTemplates() error {
 		
 		tmpl, err := template.ParseFiles(path)
 		if err != nil {
-			log.Printf("Warning: failed to load template %s: %v", filename, err)
+			utils.Warnf("Warning: failed to load template %s: %v", filename, err)
 			continue
 		}
 		
 		tm.templates[name] = tmpl
-		log.Printf("Loaded template: %s", filename)
+		utils.Infof("Loaded template: %s", filename)
 	}
 	
 	return nil
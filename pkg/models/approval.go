@@ -0,0 +1,38 @@
+// ===== pkg/models/approval.go =====
+package models
+
+import "time"
+
+// Approval states of a device
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalBlocked  = "blocked"
+)
+
+// Approval is the admin decision on a device that joined the network
+type Approval struct {
+	MAC       string     `json:"mac"`
+	State     string     `json:"state"` // pending, approved or blocked
+	Hostname  string     `json:"hostname,omitempty"`
+	IP        string     `json:"ip,omitempty"` // Address at first lease
+	Vendor    string     `json:"vendor,omitempty"`
+	FirstSeen time.Time  `json:"firstSeen"`
+	DecidedAt *time.Time `json:"decidedAt,omitempty"`
+	DecidedBy string     `json:"decidedBy,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	// Reservations a quarantine disabled, enabled again on unblock
+	Quarantined []string `json:"quarantined,omitempty"`
+}
+
+// BlockedDevice is a device that dnsmasq ignores or quarantines through
+// a dhcp-host entry
+type BlockedDevice struct {
+	MAC      string `json:"mac"`
+	Mode     string `json:"mode"` // ignore or quarantine
+	Hostname string `json:"hostname,omitempty"`
+	IP       string `json:"ip,omitempty"` // Current lease, which runs until it expires
+	Vendor   string `json:"vendor,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	EntryID  string `json:"entryId"` // Static entry doing the blocking
+}
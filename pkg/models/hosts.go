@@ -0,0 +1,65 @@
+// ===== pkg/models/hosts.go =====
+package models
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Validate checks that a hosts entry has a valid IP address and valid
+// host names
+func (e *HostEntry) Validate() error {
+	if e.IP == "" {
+		return fmt.Errorf("IP address is required")
+	}
+	if net.ParseIP(e.IP) == nil {
+		return fmt.Errorf("invalid IP address %q", e.IP)
+	}
+
+	if e.Name == "" {
+		return fmt.Errorf("hostname is required")
+	}
+	for _, name := range append([]string{e.Name}, e.Alias...) {
+		if err := validateHostname(name); err != nil {
+			return err
+		}
+	}
+
+	if strings.ContainsAny(e.Comment, "\r\n") {
+		return fmt.Errorf("comment must be a single line")
+	}
+
+	return nil
+}
+
+// Names returns the hostname followed by the aliases
+func (e *HostEntry) Names() []string {
+	return append([]string{e.Name}, e.Alias...)
+}
+
+// validateHostname checks a host name against RFC 1123
+func validateHostname(name string) error {
+	if len(name) > 253 {
+		return fmt.Errorf("hostname %q too long (max 253 characters)", name)
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("hostname %q has an empty or overlong label", name)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("hostname %q has a label starting or ending with '-'", name)
+		}
+		for _, char := range label {
+			if !((char >= 'a' && char <= 'z') ||
+				(char >= 'A' && char <= 'Z') ||
+				(char >= '0' && char <= '9') ||
+				char == '-' || char == '_') {
+				return fmt.Errorf("hostname %q contains invalid characters", name)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+// ===== pkg/models/label.go =====
+package models
+
+import "time"
+
+// Label is user-supplied information about a device or a range of
+// devices, keyed by a full MAC address or a vendor prefix
+type Label struct {
+	Key      string    `json:"key"`            // MAC address or prefix, e.g. AA:BB:CC or AA:BB:CC:D/28
+	Name     string    `json:"name,omitempty"` // Friendly name
+	Type     string    `json:"type,omitempty"` // Device type, e.g. printer
+	Owner    string    `json:"owner,omitempty"`
+	Location string    `json:"location,omitempty"`
+	Notes    string    `json:"notes,omitempty"`
+	Vendor   string    `json:"vendor,omitempty"` // Overrides the OUI company name
+	Updated  time.Time `json:"updated"`
+}
@@ -33,16 +33,24 @@ type OUIEntry struct {
 
 // HostEntry represents a hosts file entry
 type HostEntry struct {
-	IP    string   `json:"ip"`
-	Name  string   `json:"name"`
-	Alias []string `json:"alias"`
+	ID         string   `json:"id"`
+	IP         string   `json:"ip"`
+	Name       string   `json:"name"`
+	Alias      []string `json:"alias"`
+	Comment    string   `json:"comment,omitempty"`    // Inline comment
+	LineNumber int      `json:"lineNumber,omitempty"` // Line in the hosts file, 0 if not saved yet
 }
 
 // LogEntry represents a log entry
 type LogEntry struct {
+	ID        uint64    `json:"id"`
 	Timestamp time.Time `json:"when"`
 	UnixTime  int64     `json:"utime"`
 	Channel   string    `json:"channel"`
+	Host      string    `json:"host,omitempty"`
 	Message   string    `json:"message"`
+	Event     string    `json:"event,omitempty"`
+	MAC       string    `json:"mac,omitempty"`
+	IP        string    `json:"ip,omitempty"`
 }
 
@@ -18,6 +18,7 @@ type StaticDHCPEntry struct {
 	LeaseTime   string           `json:"leaseTime"`   // Lease time (optional)
 	Comment     string           `json:"comment"`     // Comment (optional)
 	Enabled     bool             `json:"enabled"`     // Whether entry is enabled
+	Ignore      bool             `json:"ignore"`      // dnsmasq ignores the device (blocked)
 	LineNumber  int              `json:"lineNumber"`  // Original line number in file
 	RawLine     string           `json:"rawLine"`     // Original raw line
 }
@@ -85,12 +86,9 @@ func (e *StaticDHCPEntry) SetIP(ipStr string) error {
 	return nil
 }
 
-// ToDnsmasqLine converts the entry back to dnsmasq configuration format
+// ToDnsmasqLine converts the entry back to dnsmasq configuration format.
+// Disabled entries are written commented out.
 func (e *StaticDHCPEntry) ToDnsmasqLine() string {
-	if !e.Enabled {
-		return "# " + e.RawLine
-	}
-
 	parts := []string{}
 	
 	// MAC address is required (formatted as AA:BB:CC:DD:EE:FF)
@@ -118,6 +116,10 @@ func (e *StaticDHCPEntry) ToDnsmasqLine() string {
 		parts = append(parts, e.LeaseTime)
 	}
 	
+	if e.Ignore {
+		parts = append(parts, "ignore")
+	}
+	
 	line := "dhcp-host=" + strings.Join(parts, ",")
 	
 	// Add comment if specified
@@ -125,6 +127,9 @@ func (e *StaticDHCPEntry) ToDnsmasqLine() string {
 		line += " # " + e.Comment
 	}
 	
+	if !e.Enabled {
+		return "# " + line
+	}
 	return line
 }
 
@@ -139,7 +144,7 @@ func (e *StaticDHCPEntry) Validate() error {
 		return fmt.Errorf("invalid MAC address length")
 	}
 	
-	if e.IP == nil && e.Hostname == "" {
+	if e.IP == nil && e.Hostname == "" && e.Tag == "" && !e.Ignore {
 		return fmt.Errorf("either IP address or hostname is required")
 	}
 	
@@ -180,6 +185,7 @@ func (e *StaticDHCPEntry) Clone() *StaticDHCPEntry {
 		LeaseTime:  e.LeaseTime,
 		Comment:    e.Comment,
 		Enabled:    e.Enabled,
+		Ignore:     e.Ignore,
 		LineNumber: e.LineNumber,
 		RawLine:    e.RawLine,
 	}
@@ -212,7 +218,8 @@ func (e *StaticDHCPEntry) Equal(other *StaticDHCPEntry) bool {
 		e.Tag == other.Tag &&
 		e.LeaseTime == other.LeaseTime &&
 		e.Comment == other.Comment &&
-		e.Enabled == other.Enabled
+		e.Enabled == other.Enabled &&
+		e.Ignore == other.Ignore
 }
 
 // String returns a string representation of the entry
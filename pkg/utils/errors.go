@@ -16,7 +16,7 @@ func CheckFatal(err error, context string) {
 // CheckWarn logs a warning and returns true if err is not nil
 func CheckWarn(err error, context string) bool {
 	if err != nil {
-		log.Printf("Warning - %s: %v", context, err)
+		Warnf("Warning - %s: %v", context, err)
 		return true
 	}
 	return false
@@ -0,0 +1,51 @@
+// ===== pkg/utils/file.go =====
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to name through a temporary file in the same
+// directory, so readers never see a partial file. See WriteAtomic.
+func WriteFileAtomic(name string, data []byte, perm os.FileMode) error {
+	return WriteAtomic(name, perm, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+// WriteAtomic creates a temporary file next to name, lets write fill it,
+// and renames it over name once it is synced. When write fails, name is
+// left untouched. The directory is created when missing; an existing file
+// keeps its mode, and a new one gets perm.
+func WriteAtomic(name string, perm os.FileMode, write func(f *os.File) error) error {
+	if info, err := os.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,80 @@
+// ===== pkg/utils/loglevel.go =====
+package utils
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+// Log levels, from most to least verbose
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+)
+
+var logLevelNames = []string{"debug", "info", "warning", "error"}
+
+// String returns the name of a log level
+func (l LogLevel) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+// ParseLogLevel parses debug, info, warning (or warn) and error
+func ParseLogLevel(s string) (LogLevel, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warn" {
+		return LevelWarning, nil
+	}
+	for i, name := range logLevelNames {
+		if s == name {
+			return LogLevel(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (use debug, info, warning or error)", s)
+}
+
+// logLevel is the lowest level logged, set once the configuration is read
+var logLevel = int32(LevelInfo)
+
+// SetLogLevel sets the lowest level of the messages logged
+func SetLogLevel(level LogLevel) {
+	atomic.StoreInt32(&logLevel, int32(level))
+}
+
+// logf logs a message through the standard logger when level is enabled
+func logf(level LogLevel, format string, args ...interface{}) {
+	if level < LogLevel(atomic.LoadInt32(&logLevel)) {
+		return
+	}
+	log.Output(3, fmt.Sprintf(format, args...))
+}
+
+// Debugf logs a debug message, such as a line per request
+func Debugf(format string, args ...interface{}) {
+	logf(LevelDebug, format, args...)
+}
+
+// Infof logs an informational message
+func Infof(format string, args ...interface{}) {
+	logf(LevelInfo, format, args...)
+}
+
+// Warnf logs a warning
+func Warnf(format string, args ...interface{}) {
+	logf(LevelWarning, format, args...)
+}
+
+// Errorf logs an error
+func Errorf(format string, args ...interface{}) {
+	logf(LevelError, format, args...)
+}
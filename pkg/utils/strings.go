@@ -0,0 +1,12 @@
+// ===== pkg/utils/strings.go =====
+package utils
+
+// ContainsString reports whether list contains s
+func ContainsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}